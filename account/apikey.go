@@ -0,0 +1,177 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, so that keys can be told apart from user
+// tokens and spotted by secret scanners.
+const APIKeyPrefix = "lgk_"
+
+const (
+	DefaultAPIKeyRateLimit = 60   // Requests per minute.
+	maxAPIKeyRateLimit     = 1200 // Requests per minute.
+
+	// apiKeyTokenTTL is the lifetime of the access tokens API keys are
+	// exchanged for. A revoked key's tokens lapse within it.
+	apiKeyTokenTTL = 5 * time.Minute
+
+	apiKeyDisplayLength = len(APIKeyPrefix) + 8
+)
+
+var (
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+	ErrInvalidAPIKey   = errors.New("invalid or revoked api key")
+	ErrInvalidScope    = errors.New("invalid api key scope")
+	ErrInvalidKeyLimit = errors.New("api key rate limit must be between 1 and 1200 requests per minute")
+	ErrNoAPIAccess     = errors.New("api access is not included in the account's plan")
+)
+
+// PlanChecker reports whether a merchant's pricing plan includes API access.
+// API keys can only be created and used while it does.
+type PlanChecker interface {
+	APIAccessAllowed(ctx context.Context, accountID string) (bool, error)
+}
+
+// apiKeyScopes are the permissions an API key can be given. Team and key
+// management stay with signed-in users.
+var apiKeyScopes = []Permission{PermissionViewShipments, PermissionManageShipments, PermissionViewBilling, PermissionManageBilling}
+
+// APIKey lets a merchant's own systems act for the account. Only the SHA-256
+// hash of the key is stored; Prefix is kept so the key can be recognised in
+// lists.
+type APIKey struct {
+	ID         string
+	AccountID  string
+	Name       string
+	Prefix     string
+	Scopes     []Permission
+	RateLimit  int // Requests per minute.
+	CreatedBy  string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// APIKeyToken is an access token issued for an authenticated API key.
+type APIKeyToken struct {
+	Key         *APIKey
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// CreateAPIKey creates an API key for the account and returns it with the key
+// itself, which is shown once. The actor can only grant scopes their own role
+// has, and the account's plan must include API access. A rateLimit of 0 uses
+// DefaultAPIKeyRateLimit.
+func (s *accountService) CreateAPIKey(ctx context.Context, actorID string, accountID string, name string, scopes []Permission, rateLimit int) (*APIKey, string, error) {
+	actor, err := s.authorize(ctx, accountID, actorID, PermissionManageAPIKeys)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !validAPIKeyScope(scope) {
+			return nil, "", ErrInvalidScope
+		}
+		if !HasPermission(actor.Role, scope) {
+			return nil, "", ErrPermissionDenied
+		}
+	}
+	if rateLimit == 0 {
+		rateLimit = DefaultAPIKeyRateLimit
+	}
+	if rateLimit < 1 || rateLimit > maxAPIKeyRateLimit {
+		return nil, "", ErrInvalidKeyLimit
+	}
+	if err := s.requireAPIAccess(ctx, accountID); err != nil {
+		return nil, "", err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	secret := APIKeyPrefix + token
+	key, err := s.repo.CreateAPIKey(ctx, APIKey{
+		AccountID: accountID,
+		Name:      strings.TrimSpace(name),
+		Prefix:    secret[:apiKeyDisplayLength],
+		Scopes:    scopes,
+		RateLimit: rateLimit,
+		CreatedBy: actorID,
+	}, hashOneTimeToken(secret))
+	if err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// ListAPIKeys returns the account's active API keys.
+func (s *accountService) ListAPIKeys(ctx context.Context, actorID string, accountID string) ([]APIKey, error) {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageAPIKeys); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAPIKeys(ctx, accountID)
+}
+
+// RevokeAPIKey revokes one of the account's API keys. Tokens already issued
+// for it lapse within apiKeyTokenTTL.
+func (s *accountService) RevokeAPIKey(ctx context.Context, actorID string, accountID string, keyID string) error {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageAPIKeys); err != nil {
+		return err
+	}
+	return s.repo.RevokeAPIKey(ctx, accountID, keyID)
+}
+
+// AuthenticateAPIKey checks an API key, records its use and exchanges it for a
+// short-lived access token carrying its scopes.
+func (s *accountService) AuthenticateAPIKey(ctx context.Context, secret string) (*APIKeyToken, error) {
+	if !strings.HasPrefix(secret, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.UseAPIKey(ctx, hashOneTimeToken(secret))
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.repo.GetAccountByID(ctx, key.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	// Keys stop working if the merchant moves to a plan without API access.
+	if err := s.requireAPIAccess(ctx, key.AccountID); err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := s.tokens.IssueForAPIKey(key, account.EmailVerifiedAt != nil, apiKeyTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeyToken{Key: key, AccessToken: token, ExpiresAt: expiresAt}, nil
+}
+
+// requireAPIAccess returns ErrNoAPIAccess unless the account's plan includes
+// API access.
+func (s *accountService) requireAPIAccess(ctx context.Context, accountID string) error {
+	allowed, err := s.plans.APIAccessAllowed(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrNoAPIAccess
+	}
+	return nil
+}
+
+func validAPIKeyScope(scope Permission) bool {
+	for _, p := range apiKeyScopes {
+		if p == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -3,23 +3,31 @@ package account
 import (
 	"context"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/Shridhar2104/logilo/account/pb"
+	"github.com/Shridhar2104/logilo/payment/invoice"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
 )
 
+// maxResponseSize lets data export archives through, which can be larger
+// than the default 4 MB limit.
+const maxResponseSize = 64 << 20
+
 // Client is a struct that manages the gRPC connection and AccountServiceClient
 type Client struct {
 	conn    *grpc.ClientConn         // Holds the gRPC client connection
 	service pb.AccountServiceClient // gRPC client for calling the remote AccountService
 }
 
-// NewClient establishes a new gRPC connection and returns a Client instance
-func NewClient(url string) (*Client, error) {
+// NewClient establishes a new gRPC connection and returns a Client instance.
+// Other services pass ServiceCredentials in opts.
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
 	// Establish a connection to the gRPC server using the provided URL
-	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	opts = append([]grpc.DialOption{grpc.WithInsecure(), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxResponseSize))}, opts...)
+	conn, err := grpc.Dial(url, opts...)
 	if err != nil {
 		return nil, err // Return error if connection fails
 	}
@@ -62,12 +70,14 @@ func (c *Client) CreateAccount(ctx context.Context, a *Account) (*Account, error
 	}, nil
 }
 
-// GetAccount authenticates and fetches specific account details from the server by email and password
-func (c *Client) LoginAndGetAccount(ctx context.Context, email string, password string) (*Account, error) {
+// LoginAndGetAccount authenticates and fetches specific account details from the server by email and password
+func (c *Client) LoginAndGetAccount(ctx context.Context, email string, password string, device Device) (*Account, error) {
 	// Send the GetAccountByEmailAndPassword request to the server
 	res, err := c.service.GetAccountByEmailAndPassword(ctx, &pb.GetAccountByEmailAndPasswordRequest{
-		Email:    email,
-		Password: password,
+		Email:     email,
+		Password:  password,
+		UserAgent: device.UserAgent,
+		Ip:        device.IP,
 	})
 	if err != nil {
 		return nil, err // Return error if RPC fails
@@ -81,6 +91,49 @@ func (c *Client) LoginAndGetAccount(ctx context.Context, email string, password
 	}, nil
 }
 
+// GetAccountByID fetches an active account by its id
+func (c *Client) GetAccountByID(ctx context.Context, id string) (*Account, error) {
+	res, err := c.service.GetAccountByID(ctx, &pb.GetAccountByIDRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoAccount(res.Account), nil
+}
+
+// UpdateAccount changes the user's name, email or password; empty fields are left unchanged
+func (c *Client) UpdateAccount(ctx context.Context, userID string, sessionID string, update AccountUpdate) (*Account, error) {
+	res, err := c.service.UpdateAccount(ctx, &pb.UpdateAccountRequest{
+		ActorId:         userID,
+		SessionId:       sessionID,
+		Name:            update.Name,
+		Email:           update.Email,
+		Password:        update.Password,
+		CurrentPassword: update.CurrentPassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoAccount(res.Account), nil
+}
+
+// DeleteAccount deactivates the user's account after checking its password
+func (c *Client) DeleteAccount(ctx context.Context, userID string, password string) error {
+	_, err := c.service.DeleteAccount(ctx, &pb.DeleteAccountRequest{ActorId: userID, Password: password})
+	return err
+}
+
+func fromProtoAccount(a *pb.Account) *Account {
+	out := &Account{
+		ID:    uuid.MustParse(a.Id),
+		Name:  a.Name,
+		Email: a.Email,
+	}
+	if a.EmailVerifiedAt != 0 {
+		verified := time.Unix(a.EmailVerifiedAt, 0)
+		out.EmailVerifiedAt = &verified
+	}
+	return out
+}
 
 // ListAccounts fetches a paginated list of accounts from the server
 func (c *Client) ListAccounts(ctx context.Context, skip, take uint64) ([]Account, error) {
@@ -100,3 +153,640 @@ func (c *Client) ListAccounts(ctx context.Context, skip, take uint64) ([]Account
 	}
 	return accounts, nil // Return the mapped slice
 }
+
+// Login authenticates with email and password. It starts a session for the device and returns its token pair, or returns a
+// challenge to complete with CompleteLogin if the account has two-factor authentication enabled
+func (c *Client) Login(ctx context.Context, email string, password string, device Device) (*LoginResult, error) {
+	res, err := c.service.Login(ctx, &pb.LoginRequest{
+		Email:     email,
+		Password:  password,
+		UserAgent: device.UserAgent,
+		Ip:        device.IP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoLoginResponse(res), nil
+}
+
+// CompleteLogin answers a two-factor challenge with a TOTP or recovery code and returns the new session's token pair
+func (c *Client) CompleteLogin(ctx context.Context, challengeToken string, code string, device Device) (*LoginResult, error) {
+	res, err := c.service.CompleteLogin(ctx, &pb.CompleteLoginRequest{
+		ChallengeToken: challengeToken,
+		Code:           code,
+		UserAgent:      device.UserAgent,
+		Ip:             device.IP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoLoginResponse(res), nil
+}
+
+func fromProtoLoginResponse(res *pb.LoginResponse) *LoginResult {
+	a := fromProtoAccount(res.Account)
+	result := &LoginResult{Account: a}
+	if res.Tokens != nil {
+		result.Tokens = fromProtoTokenPair(res.Tokens)
+	}
+	if res.ChallengeToken != "" {
+		result.Challenge = &LoginChallenge{
+			Token:     res.ChallengeToken,
+			AccountID: a.ID.String(),
+			ExpiresAt: time.Unix(res.ChallengeExpiresAt, 0),
+		}
+	}
+	return result
+}
+
+// RefreshToken rotates a session's refresh token and returns the new token pair
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string, device Device) (*TokenPair, error) {
+	res, err := c.service.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: refreshToken,
+		UserAgent:    device.UserAgent,
+		Ip:           device.IP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTokenPair(res.Tokens), nil
+}
+
+func fromProtoTokenPair(p *pb.TokenPair) *TokenPair {
+	return &TokenPair{
+		SessionID:        p.SessionId,
+		AccessToken:      p.AccessToken,
+		RefreshToken:     p.RefreshToken,
+		AccessExpiresAt:  time.Unix(p.AccessExpiresAt, 0),
+		RefreshExpiresAt: time.Unix(p.RefreshExpiresAt, 0),
+	}
+}
+
+// ListSessions fetches the account's active sessions
+func (c *Client) ListSessions(ctx context.Context, accountID string) ([]Session, error) {
+	res, err := c.service.ListSessions(ctx, &pb.ListSessionsRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, len(res.Sessions))
+	for i, s := range res.Sessions {
+		sessions[i] = Session{
+			ID:         s.Id,
+			UserID:     accountID,
+			Device:     Device{UserAgent: s.UserAgent, IP: s.Ip},
+			CreatedAt:  time.Unix(s.CreatedAt, 0),
+			LastUsedAt: time.Unix(s.LastUsedAt, 0),
+			ExpiresAt:  time.Unix(s.ExpiresAt, 0),
+		}
+	}
+	return sessions, nil
+}
+
+// ListLoginHistory fetches the user's recent login attempts, newest first
+func (c *Client) ListLoginHistory(ctx context.Context, userID string, limit int) ([]LoginEvent, error) {
+	res, err := c.service.ListLoginHistory(ctx, &pb.ListLoginHistoryRequest{ActorId: userID, Limit: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]LoginEvent, len(res.Events))
+	for i, e := range res.Events {
+		events[i] = LoginEvent{
+			ID:            e.Id,
+			AccountID:     userID,
+			Device:        Device{UserAgent: e.UserAgent, IP: e.Ip},
+			Network:       e.Network,
+			Success:       e.Success,
+			FailureReason: e.FailureReason,
+			NewDevice:     e.NewDevice,
+			CreatedAt:     time.Unix(e.CreatedAt, 0),
+		}
+	}
+	return events, nil
+}
+
+// RequestDataExport queues an export of the user's data across every service
+func (c *Client) RequestDataExport(ctx context.Context, userID string) (*DataRequest, error) {
+	res, err := c.service.RequestDataExport(ctx, &pb.RequestDataExportRequest{ActorId: userID})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoDataRequest(userID, res.Request), nil
+}
+
+// RequestDataErasure queues the erasure of the user's data across every service
+func (c *Client) RequestDataErasure(ctx context.Context, userID string, password string) (*DataRequest, error) {
+	res, err := c.service.RequestDataErasure(ctx, &pb.RequestDataErasureRequest{ActorId: userID, Password: password})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoDataRequest(userID, res.Request), nil
+}
+
+// ListDataRequests fetches the user's data export and erasure requests
+func (c *Client) ListDataRequests(ctx context.Context, userID string) ([]DataRequest, error) {
+	res, err := c.service.ListDataRequests(ctx, &pb.ListDataRequestsRequest{ActorId: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]DataRequest, len(res.Requests))
+	for i, r := range res.Requests {
+		requests[i] = *fromProtoDataRequest(userID, r)
+	}
+	return requests, nil
+}
+
+// GetDataExport fetches the zip archive of a completed data export
+func (c *Client) GetDataExport(ctx context.Context, userID string, requestID string) (*DataRequest, []byte, error) {
+	res, err := c.service.GetDataExport(ctx, &pb.GetDataExportRequest{ActorId: userID, RequestId: requestID})
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromProtoDataRequest(userID, res.Request), res.Data, nil
+}
+
+func fromProtoDataRequest(accountID string, r *pb.DataRequest) *DataRequest {
+	out := &DataRequest{
+		ID:        r.Id,
+		AccountID: accountID,
+		Kind:      r.Kind,
+		Status:    r.Status,
+		Attempts:  int(r.Attempts),
+		LastError: r.LastError,
+		Retained:  r.Retained,
+		CreatedAt: time.Unix(r.CreatedAt, 0),
+	}
+	if r.CompletedAt != 0 {
+		completed := time.Unix(r.CompletedAt, 0)
+		out.CompletedAt = &completed
+	}
+	if r.ExpiresAt != 0 {
+		expires := time.Unix(r.ExpiresAt, 0)
+		out.ExpiresAt = &expires
+	}
+	return out
+}
+
+// RevokeSession ends one of the account's sessions
+func (c *Client) RevokeSession(ctx context.Context, accountID string, sessionID string, reason string) error {
+	_, err := c.service.RevokeSession(ctx, &pb.RevokeSessionRequest{
+		AccountId: accountID,
+		SessionId: sessionID,
+		Reason:    reason,
+	})
+	return err
+}
+
+// RevokeAllSessions ends the account's sessions other than exceptSessionID and returns how many were ended
+func (c *Client) RevokeAllSessions(ctx context.Context, accountID string, exceptSessionID string) (int, error) {
+	res, err := c.service.RevokeAllSessions(ctx, &pb.RevokeAllSessionsRequest{
+		AccountId:       accountID,
+		ExceptSessionId: exceptSessionID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(res.Revoked), nil
+}
+
+// SendVerificationEmail asks the server to email the account a verification link
+func (c *Client) SendVerificationEmail(ctx context.Context, accountID string) error {
+	_, err := c.service.SendVerificationEmail(ctx, &pb.SendVerificationEmailRequest{AccountId: accountID})
+	return err
+}
+
+// VerifyEmail consumes an email verification token
+func (c *Client) VerifyEmail(ctx context.Context, token string) error {
+	_, err := c.service.VerifyEmail(ctx, &pb.VerifyEmailRequest{Token: token})
+	return err
+}
+
+// RequestPasswordReset asks the server to email a password-reset link to the address
+func (c *Client) RequestPasswordReset(ctx context.Context, email string) error {
+	_, err := c.service.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{Email: email})
+	return err
+}
+
+// ResetPassword consumes a password-reset token and sets the new password
+func (c *Client) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	_, err := c.service.ResetPassword(ctx, &pb.ResetPasswordRequest{Token: token, NewPassword: newPassword})
+	return err
+}
+
+// BeginTwoFactorEnrollment generates a pending TOTP secret for the account
+func (c *Client) BeginTwoFactorEnrollment(ctx context.Context, accountID string) (*TwoFactorEnrollment, error) {
+	res, err := c.service.BeginTwoFactorEnrollment(ctx, &pb.BeginTwoFactorEnrollmentRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorEnrollment{Secret: res.Secret, URI: res.OtpauthUri}, nil
+}
+
+// ConfirmTwoFactorEnrollment enables two-factor authentication with a code from the authenticator app and returns recovery codes
+func (c *Client) ConfirmTwoFactorEnrollment(ctx context.Context, accountID string, code string) ([]string, error) {
+	res, err := c.service.ConfirmTwoFactorEnrollment(ctx, &pb.TwoFactorCodeRequest{AccountId: accountID, Code: code})
+	if err != nil {
+		return nil, err
+	}
+	return res.RecoveryCodes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off, given a TOTP or recovery code
+func (c *Client) DisableTwoFactor(ctx context.Context, accountID string, code string) error {
+	_, err := c.service.DisableTwoFactor(ctx, &pb.TwoFactorCodeRequest{AccountId: accountID, Code: code})
+	return err
+}
+
+// RegenerateRecoveryCodes replaces the account's recovery codes, given a TOTP or recovery code
+func (c *Client) RegenerateRecoveryCodes(ctx context.Context, accountID string, code string) ([]string, error) {
+	res, err := c.service.RegenerateRecoveryCodes(ctx, &pb.TwoFactorCodeRequest{AccountId: accountID, Code: code})
+	if err != nil {
+		return nil, err
+	}
+	return res.RecoveryCodes, nil
+}
+
+// InviteMember invites an email address to join the account's team with a role
+func (c *Client) InviteMember(ctx context.Context, actorID string, accountID string, email string, role string) (*Invitation, error) {
+	res, err := c.service.InviteMember(ctx, &pb.InviteMemberRequest{
+		ActorId:   actorID,
+		AccountId: accountID,
+		Email:     email,
+		Role:      role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoInvitation(res.Invitation), nil
+}
+
+// ListInvitations fetches the account's pending invitations
+func (c *Client) ListInvitations(ctx context.Context, actorID string, accountID string) ([]Invitation, error) {
+	res, err := c.service.ListInvitations(ctx, &pb.ListInvitationsRequest{ActorId: actorID, AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]Invitation, len(res.Invitations))
+	for i, inv := range res.Invitations {
+		invitations[i] = *fromProtoInvitation(inv)
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation withdraws a pending invitation
+func (c *Client) RevokeInvitation(ctx context.Context, actorID string, accountID string, invitationID string) error {
+	_, err := c.service.RevokeInvitation(ctx, &pb.RevokeInvitationRequest{
+		ActorId:      actorID,
+		AccountId:    accountID,
+		InvitationId: invitationID,
+	})
+	return err
+}
+
+// AcceptInvitation joins the inviting team
+func (c *Client) AcceptInvitation(ctx context.Context, actorID string, token string) (*Membership, error) {
+	res, err := c.service.AcceptInvitation(ctx, &pb.AcceptInvitationRequest{ActorId: actorID, Token: token})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoMembership(res.Membership), nil
+}
+
+// ListMembers fetches the account's team
+func (c *Client) ListMembers(ctx context.Context, actorID string, accountID string) ([]Member, error) {
+	res, err := c.service.ListMembers(ctx, &pb.ListMembersRequest{ActorId: actorID, AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]Member, len(res.Members))
+	for i, m := range res.Members {
+		members[i] = Member{Membership: *fromProtoMembership(m.Membership), Name: m.Name, Email: m.Email}
+	}
+	return members, nil
+}
+
+// SetMemberRole changes a member's role
+func (c *Client) SetMemberRole(ctx context.Context, actorID string, accountID string, userID string, role string) error {
+	_, err := c.service.SetMemberRole(ctx, &pb.SetMemberRoleRequest{
+		ActorId:   actorID,
+		AccountId: accountID,
+		UserId:    userID,
+		Role:      role,
+	})
+	return err
+}
+
+// RemoveMember removes a member from the team, or leaves it when userID is the actor
+func (c *Client) RemoveMember(ctx context.Context, actorID string, accountID string, userID string) error {
+	_, err := c.service.RemoveMember(ctx, &pb.RemoveMemberRequest{
+		ActorId:   actorID,
+		AccountId: accountID,
+		UserId:    userID,
+	})
+	return err
+}
+
+// ListMemberships fetches the teams the user belongs to
+func (c *Client) ListMemberships(ctx context.Context, userID string) ([]Membership, error) {
+	res, err := c.service.ListMemberships(ctx, &pb.ListMembershipsRequest{ActorId: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := make([]Membership, len(res.Memberships))
+	for i, m := range res.Memberships {
+		memberships[i] = *fromProtoMembership(m)
+	}
+	return memberships, nil
+}
+
+// SwitchAccount reissues the session's tokens to act for another of the user's teams
+func (c *Client) SwitchAccount(ctx context.Context, userID string, sessionID string, accountID string) (*TokenPair, error) {
+	res, err := c.service.SwitchAccount(ctx, &pb.SwitchAccountRequest{
+		ActorId:   userID,
+		SessionId: sessionID,
+		AccountId: accountID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoTokenPair(res.Tokens), nil
+}
+
+// SetTwoFactorRequired sets whether the account's team must use two-factor authentication
+func (c *Client) SetTwoFactorRequired(ctx context.Context, actorID string, accountID string, required bool) error {
+	_, err := c.service.SetTwoFactorRequired(ctx, &pb.SetTwoFactorRequiredRequest{
+		ActorId:   actorID,
+		AccountId: accountID,
+		Required:  required,
+	})
+	return err
+}
+
+func fromProtoMembership(m *pb.Membership) *Membership {
+	return &Membership{
+		AccountID:   m.AccountId,
+		AccountName: m.AccountName,
+		UserID:      m.UserId,
+		Role:        m.Role,
+		CreatedAt:   time.Unix(m.CreatedAt, 0),
+	}
+}
+
+func fromProtoInvitation(inv *pb.Invitation) *Invitation {
+	return &Invitation{
+		ID:        inv.Id,
+		AccountID: inv.AccountId,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		InvitedBy: inv.InvitedBy,
+		CreatedAt: time.Unix(inv.CreatedAt, 0),
+		ExpiresAt: time.Unix(inv.ExpiresAt, 0),
+	}
+}
+
+// CreateAPIKey creates an API key for the account and returns it with the key itself, which cannot be retrieved again
+func (c *Client) CreateAPIKey(ctx context.Context, actorID string, accountID string, name string, scopes []Permission, rateLimit int) (*APIKey, string, error) {
+	res, err := c.service.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{
+		ActorId:   actorID,
+		AccountId: accountID,
+		Name:      name,
+		Scopes:    scopeStrings(scopes),
+		RateLimit: int32(rateLimit),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return fromProtoAPIKey(res.ApiKey), res.Secret, nil
+}
+
+// ListAPIKeys fetches the account's active API keys
+func (c *Client) ListAPIKeys(ctx context.Context, actorID string, accountID string) ([]APIKey, error) {
+	res, err := c.service.ListAPIKeys(ctx, &pb.ListAPIKeysRequest{ActorId: actorID, AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, len(res.ApiKeys))
+	for i, key := range res.ApiKeys {
+		keys[i] = *fromProtoAPIKey(key)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes one of the account's API keys
+func (c *Client) RevokeAPIKey(ctx context.Context, actorID string, accountID string, keyID string) error {
+	_, err := c.service.RevokeAPIKey(ctx, &pb.RevokeAPIKeyRequest{
+		ActorId:   actorID,
+		AccountId: accountID,
+		KeyId:     keyID,
+	})
+	return err
+}
+
+// AuthenticateAPIKey exchanges an API key for a short-lived access token carrying its scopes
+func (c *Client) AuthenticateAPIKey(ctx context.Context, secret string) (*APIKeyToken, error) {
+	res, err := c.service.AuthenticateAPIKey(ctx, &pb.AuthenticateAPIKeyRequest{Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeyToken{
+		Key:         fromProtoAPIKey(res.ApiKey),
+		AccessToken: res.AccessToken,
+		ExpiresAt:   time.Unix(res.ExpiresAt, 0),
+	}, nil
+}
+
+// GetProfile fetches the account's merchant profile
+func (c *Client) GetProfile(ctx context.Context, actorID string, accountID string) (*Profile, error) {
+	res, err := c.service.GetProfile(ctx, &pb.GetProfileRequest{ActorId: actorID, AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoProfileResponse(res.Profile), nil
+}
+
+// UpdateProfile sets the account's business details
+func (c *Client) UpdateProfile(ctx context.Context, actorID string, accountID string, p Profile) (*Profile, error) {
+	a := p.BillingAddress
+	res, err := c.service.UpdateProfile(ctx, &pb.UpdateProfileRequest{
+		ActorId:   actorID,
+		AccountId: accountID,
+		Profile: &pb.Profile{
+			CompanyName:    p.CompanyName,
+			Gstin:          p.GSTIN,
+			Pan:            p.PAN,
+			BillingAddress: &pb.PostalAddress{Line1: a.Line1, Line2: a.Line2, City: a.City, State: a.State, PostalCode: a.PostalCode},
+			ContactPhone:   p.ContactPhone,
+			BrandName:      p.BrandName,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoProfileResponse(res.Profile), nil
+}
+
+// UploadLogo replaces the logo printed on the account's shipping labels
+func (c *Client) UploadLogo(ctx context.Context, actorID string, accountID string, data []byte) (*Profile, error) {
+	res, err := c.service.UploadLogo(ctx, &pb.UploadLogoRequest{ActorId: actorID, AccountId: accountID, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoProfileResponse(res.Profile), nil
+}
+
+// GetLogo fetches the account's label logo and its content type
+func (c *Client) GetLogo(ctx context.Context, accountID string) ([]byte, string, error) {
+	res, err := c.service.GetLogo(ctx, &pb.GetLogoRequest{AccountId: accountID})
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Data, res.ContentType, nil
+}
+
+// UploadKYCDocument uploads a document and submits the account's KYC for review
+func (c *Client) UploadKYCDocument(ctx context.Context, actorID string, accountID string, docType string, filename string, data []byte) (*KYCDocument, error) {
+	res, err := c.service.UploadKYCDocument(ctx, &pb.UploadKYCDocumentRequest{
+		ActorId:   actorID,
+		AccountId: accountID,
+		Type:      docType,
+		Filename:  filename,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoKYCDocument(res.Document), nil
+}
+
+// ListKYCDocuments fetches the account's KYC documents
+func (c *Client) ListKYCDocuments(ctx context.Context, actorID string, accountID string) ([]KYCDocument, error) {
+	res, err := c.service.ListKYCDocuments(ctx, &pb.ListKYCDocumentsRequest{ActorId: actorID, AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]KYCDocument, len(res.Documents))
+	for i, doc := range res.Documents {
+		docs[i] = *fromProtoKYCDocument(doc)
+	}
+	return docs, nil
+}
+
+// BillingProfile fills a merchant's invoice billing details from their account
+// profile. It returns invoice.ErrBillingProfileNotFound until the profile has
+// been filled in.
+func (c *Client) BillingProfile(ctx context.Context, accountID string) (*invoice.BillingProfile, error) {
+	res, err := c.service.GetBusinessProfile(ctx, &pb.GetBusinessProfileRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	p := fromProtoProfileResponse(res.Profile)
+	if p.CompanyName == "" {
+		return nil, invoice.ErrBillingProfileNotFound
+	}
+
+	a := p.BillingAddress
+	lines := []string{a.Line1}
+	if a.Line2 != "" {
+		lines = append(lines, a.Line2)
+	}
+	lines = append(lines, a.City, a.State+" "+a.PostalCode)
+
+	billing := &invoice.BillingProfile{
+		AccountID: accountID,
+		LegalName: p.CompanyName,
+		GSTIN:     p.GSTIN,
+		Address:   strings.Join(lines, ", "),
+	}
+	if p.GSTIN != "" {
+		billing.StateCode = p.GSTIN[:2]
+	} else if billing.StateCode = invoice.StateCode(a.State); billing.StateCode == "" {
+		return nil, invoice.ErrBillingProfileNotFound
+	}
+	return billing, nil
+}
+
+// KYCApproved reports whether the account's KYC has been approved
+func (c *Client) KYCApproved(ctx context.Context, accountID string) (bool, error) {
+	res, err := c.service.GetKYCStatus(ctx, &pb.GetKYCStatusRequest{AccountId: accountID})
+	if err != nil {
+		return false, err
+	}
+	return res.Approved, nil
+}
+
+// EmailVerified reports whether the account's email address has been verified
+func (c *Client) EmailVerified(ctx context.Context, accountID string) (bool, error) {
+	res, err := c.service.GetKYCStatus(ctx, &pb.GetKYCStatusRequest{AccountId: accountID})
+	if err != nil {
+		return false, err
+	}
+	return res.EmailVerified, nil
+}
+
+func fromProtoProfileResponse(p *pb.Profile) *Profile {
+	out := &Profile{
+		AccountID:          p.AccountId,
+		CompanyName:        p.CompanyName,
+		GSTIN:              p.Gstin,
+		PAN:                p.Pan,
+		ContactPhone:       p.ContactPhone,
+		BrandName:          p.BrandName,
+		HasLogo:            p.HasLogo,
+		KYCStatus:          p.KycStatus,
+		KYCReviewedBy:      p.KycReviewedBy,
+		KYCRejectionReason: p.KycRejectionReason,
+		UpdatedAt:          time.Unix(p.UpdatedAt, 0),
+	}
+	if a := p.BillingAddress; a != nil {
+		out.BillingAddress = Address{Line1: a.Line1, Line2: a.Line2, City: a.City, State: a.State, PostalCode: a.PostalCode}
+	}
+	if p.KycSubmittedAt != 0 {
+		submitted := time.Unix(p.KycSubmittedAt, 0)
+		out.KYCSubmittedAt = &submitted
+	}
+	if p.KycReviewedAt != 0 {
+		reviewed := time.Unix(p.KycReviewedAt, 0)
+		out.KYCReviewedAt = &reviewed
+	}
+	return out
+}
+
+func fromProtoKYCDocument(doc *pb.KYCDocument) *KYCDocument {
+	return &KYCDocument{
+		ID:          doc.Id,
+		AccountID:   doc.AccountId,
+		Type:        doc.Type,
+		Filename:    doc.Filename,
+		ContentType: doc.ContentType,
+		Size:        int(doc.Size),
+		UploadedBy:  doc.UploadedBy,
+		UploadedAt:  time.Unix(doc.UploadedAt, 0),
+	}
+}
+
+func fromProtoAPIKey(key *pb.APIKey) *APIKey {
+	k := &APIKey{
+		ID:        key.Id,
+		AccountID: key.AccountId,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		RateLimit: int(key.RateLimit),
+		CreatedBy: key.CreatedBy,
+		CreatedAt: time.Unix(key.CreatedAt, 0),
+	}
+	for _, scope := range key.Scopes {
+		k.Scopes = append(k.Scopes, Permission(scope))
+	}
+	if key.LastUsedAt != 0 {
+		lastUsed := time.Unix(key.LastUsedAt, 0)
+		k.LastUsedAt = &lastUsed
+	}
+	return k
+}
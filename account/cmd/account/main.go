@@ -1,17 +1,90 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 
 	"github.com/Shridhar2104/logilo/account"
+	"github.com/Shridhar2104/logilo/account/pb"
+	"github.com/Shridhar2104/logilo/datasubject"
+	"github.com/Shridhar2104/logilo/payment"
 
-	"github.com/tinrab/retry"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/tinrab/retry"
+	"google.golang.org/grpc"
 )
 
 type Config struct {
-	DatabaseURL string `envconfig:"DATABASE_ACCOUNT_URL"`
+	DatabaseURL     string        `envconfig:"DATABASE_ACCOUNT_URL"`
+	TokenSecret     string        `envconfig:"AUTH_TOKEN_SECRET" required:"true"`
+	StaffSecret     string        `envconfig:"STAFF_TOKEN_SECRET" required:"true"` // Signs staff and service tokens; never given to the gateway
+	AccessTokenTTL  time.Duration `envconfig:"ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL time.Duration `envconfig:"REFRESH_TOKEN_TTL" default:"720h"`
+	AppURL          string        `envconfig:"APP_URL" default:"http://localhost:3000"` // Base URL of emailed links
+	SMTPHost        string        `envconfig:"SMTP_HOST"`                               // Emails are kept in memory when unset
+	SMTPPort        int           `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername    string        `envconfig:"SMTP_USERNAME"`
+	SMTPPassword    string        `envconfig:"SMTP_PASSWORD"`
+	MailFrom        string        `envconfig:"MAIL_FROM" default:"Logilo <no-reply@logilo.in>"`
+	TwoFactorKey    string        `envconfig:"TWO_FACTOR_ENCRYPTION_KEY" required:"true"` // Seals stored TOTP secrets
+	TwoFactorIssuer string        `envconfig:"TWO_FACTOR_ISSUER" default:"Logilo"`
+	BlobDir         string        `envconfig:"BLOB_DIR" default:"/var/lib/logilo/blobs"` // Holds KYC documents, label logos and data exports
+	ShopifyURL      string        `envconfig:"SHOPIFY_URL" required:"true"`              // Data export and erasure fan out to these services
+	PaymentURL      string        `envconfig:"PAYMENT_URL" required:"true"`
+}
+
+// policies authenticates every RPC but those used to sign up, sign in and
+// recover an account. The service checks the actor's current role again,
+// since roles can change within a token's lifetime.
+var policies = map[string]account.Policy{
+	pb.AccountService_InviteMember_FullMethodName:         {Permission: account.PermissionManageTeam, Account: account.RequestAccountID},
+	pb.AccountService_ListInvitations_FullMethodName:      {Permission: account.PermissionManageTeam, Account: account.RequestAccountID},
+	pb.AccountService_RevokeInvitation_FullMethodName:     {Permission: account.PermissionManageTeam, Account: account.RequestAccountID},
+	pb.AccountService_SetMemberRole_FullMethodName:        {Permission: account.PermissionManageTeam, Account: account.RequestAccountID},
+	pb.AccountService_SetTwoFactorRequired_FullMethodName: {Permission: account.PermissionManageTeam, Account: account.RequestAccountID},
+	pb.AccountService_CreateAPIKey_FullMethodName:         {Permission: account.PermissionManageAPIKeys, Account: account.RequestAccountID},
+	pb.AccountService_ListAPIKeys_FullMethodName:          {Permission: account.PermissionManageAPIKeys, Account: account.RequestAccountID},
+	pb.AccountService_RevokeAPIKey_FullMethodName:         {Permission: account.PermissionManageAPIKeys, Account: account.RequestAccountID},
+	pb.AccountService_UpdateProfile_FullMethodName:        {Permission: account.PermissionManageProfile, Account: account.RequestAccountID},
+	pb.AccountService_UploadLogo_FullMethodName:           {Permission: account.PermissionManageProfile, Account: account.RequestAccountID},
+	pb.AccountService_UploadKYCDocument_FullMethodName:    {Permission: account.PermissionManageProfile, Account: account.RequestAccountID},
+	pb.AccountService_ListKYCDocuments_FullMethodName:     {Permission: account.PermissionManageProfile, Account: account.RequestAccountID},
+	pb.AccountService_GetProfile_FullMethodName:           {Account: account.RequestAccountID},
+	pb.AccountService_ListMembers_FullMethodName:          {Account: account.RequestAccountID},
+	pb.AccountService_RemoveMember_FullMethodName:         {Account: account.RequestAccountID},
+	pb.AccountService_AcceptInvitation_FullMethodName:     {},
+	pb.AccountService_ListMemberships_FullMethodName:      {},
+	pb.AccountService_SwitchAccount_FullMethodName:        {},
+	pb.AccountService_ListLoginHistory_FullMethodName:     {},
+	pb.AccountService_GetAccountByID_FullMethodName:       {}, // The server checks the user is a teammate.
+	pb.AccountService_UpdateAccount_FullMethodName:        {},
+	pb.AccountService_DeleteAccount_FullMethodName:        {},
+	pb.AccountService_RequestDataExport_FullMethodName:    {},
+	pb.AccountService_RequestDataErasure_FullMethodName:   {},
+	pb.AccountService_ListDataRequests_FullMethodName:     {},
+	pb.AccountService_GetDataExport_FullMethodName:        {},
+
+	// The user's own sessions and security settings, named by account_id.
+	pb.AccountService_ListSessions_FullMethodName:               {User: account.RequestAccountID},
+	pb.AccountService_RevokeSession_FullMethodName:              {User: account.RequestAccountID},
+	pb.AccountService_RevokeAllSessions_FullMethodName:          {User: account.RequestAccountID},
+	pb.AccountService_SendVerificationEmail_FullMethodName:      {User: account.RequestAccountID},
+	pb.AccountService_BeginTwoFactorEnrollment_FullMethodName:   {User: account.RequestAccountID},
+	pb.AccountService_ConfirmTwoFactorEnrollment_FullMethodName: {User: account.RequestAccountID},
+	pb.AccountService_DisableTwoFactor_FullMethodName:           {User: account.RequestAccountID},
+	pb.AccountService_RegenerateRecoveryCodes_FullMethodName:    {User: account.RequestAccountID},
+
+	// Compliance staff review merchants' KYC.
+	pb.AccountService_ListPendingKYC_FullMethodName: {Permission: account.PermissionReviewKYC},
+	pb.AccountService_GetKYCDocument_FullMethodName: {Permission: account.PermissionReviewKYC},
+	pb.AccountService_ReviewKYC_FullMethodName:      {Permission: account.PermissionReviewKYC},
+
+	// Called by other services.
+	pb.AccountService_ListAccounts_FullMethodName:       {Permission: account.PermissionInternal},
+	pb.AccountService_GetLogo_FullMethodName:            {Permission: account.PermissionInternal},
+	pb.AccountService_GetBusinessProfile_FullMethodName: {Permission: account.PermissionInternal},
+	pb.AccountService_GetKYCStatus_FullMethodName:       {Permission: account.PermissionInternal},
 }
 
 func main() {
@@ -33,10 +106,53 @@ func main() {
 	})
 	defer r.Close()
 	log.Println("server starting on port 8081 ...")
-	  
 
-	s := account.NewAccountService(r)
-	log.Fatal(account.NewGRPCServer(s, 8081))
+	tokens, err := account.NewTokenManager(cfg.TokenSecret, cfg.StaffSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	if err != nil {
+		log.Fatalf("Failed to set up tokens: %v", err)
+	}
+	twoFactor, err := account.NewTwoFactorAuthenticator(cfg.TwoFactorIssuer, cfg.TwoFactorKey)
+	if err != nil {
+		log.Fatalf("Failed to set up two-factor authentication: %v", err)
+	}
+
+	var mailer account.Mailer = account.NewMemoryMailer()
+	if cfg.SMTPHost != "" {
+		mailer = account.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	} else {
+		log.Println("SMTP_HOST is not set; account emails will not be delivered")
+	}
+	blobs, err := account.NewLocalBlobStore(cfg.BlobDir)
+	if err != nil {
+		log.Fatalf("Failed to open blob store: %v", err)
+	}
+
+	// Payment goes first since it refuses to erase accounts with money
+	// outstanding. The shipment service will join once it has a server.
+	credentials := grpc.WithPerRPCCredentials(account.ServiceCredentials(tokens, "account"))
+	payments, err := datasubject.NewClient(cfg.PaymentURL, credentials)
+	if err != nil {
+		log.Fatalf("Failed to connect to payment service: %v", err)
+	}
+	defer payments.Close()
+	shops, err := datasubject.NewClient(cfg.ShopifyURL, credentials)
+	if err != nil {
+		log.Fatalf("Failed to connect to shopify service: %v", err)
+	}
+	defer shops.Close()
+	participants := []datasubject.Participant{
+		{Name: "payment", Provider: payments},
+		{Name: "shopify", Provider: shops},
+	}
+
+	plans, err := payment.NewClient(cfg.PaymentURL, credentials)
+	if err != nil {
+		log.Fatalf("Failed to connect to payment service: %v", err)
+	}
+	defer plans.Close()
+
+	s := account.NewAccountService(r, tokens, twoFactor, mailer, blobs, cfg.AppURL, participants, plans)
+	go account.NewDataRequestWorker(s).Run(context.Background(), time.Minute)
+	log.Fatal(account.NewGRPCServer(s, 8081, grpc.UnaryInterceptor(account.UnaryServerInterceptor(tokens, policies))))
 
-	
 }
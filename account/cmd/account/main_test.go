@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Shridhar2104/logilo/account/pb"
+)
+
+// public are the RPCs used to sign up, sign in and recover an account, which
+// are called without a token.
+var public = map[string]bool{
+	pb.AccountService_CreateAccount_FullMethodName:                true,
+	pb.AccountService_Login_FullMethodName:                        true,
+	pb.AccountService_GetAccountByEmailAndPassword_FullMethodName: true,
+	pb.AccountService_CompleteLogin_FullMethodName:                true,
+	pb.AccountService_RefreshToken_FullMethodName:                 true,
+	pb.AccountService_VerifyEmail_FullMethodName:                  true,
+	pb.AccountService_RequestPasswordReset_FullMethodName:         true,
+	pb.AccountService_ResetPassword_FullMethodName:                true,
+	pb.AccountService_AuthenticateAPIKey_FullMethodName:           true,
+}
+
+func TestEveryRPCHasAPolicy(t *testing.T) {
+	desc := pb.AccountService_ServiceDesc
+	methods := map[string]bool{}
+	for _, m := range desc.Methods {
+		methods["/"+desc.ServiceName+"/"+m.MethodName] = true
+	}
+	for _, s := range desc.Streams {
+		methods["/"+desc.ServiceName+"/"+s.StreamName] = true
+	}
+
+	for method := range methods {
+		_, ok := policies[method]
+		if !ok && !public[method] {
+			t.Errorf("%s has no policy", method)
+		}
+		if ok && public[method] {
+			t.Errorf("%s is public but has a policy", method)
+		}
+	}
+	for method := range policies {
+		if !methods[method] {
+			t.Errorf("policy for unknown rpc %s", method)
+		}
+	}
+}
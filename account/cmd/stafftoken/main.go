@@ -0,0 +1,51 @@
+// Command stafftoken issues an access token for a member of staff, who calls
+// the platform RPCs with it, e.g.
+//
+//	stafftoken -name priya@logilo.in -scopes platform:billing -ttl 8h
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/logilo/account"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	StaffSecret string `envconfig:"STAFF_TOKEN_SECRET" required:"true"`
+}
+
+func main() {
+	name := flag.String("name", "", "who the token is issued to; recorded as the actor of their actions")
+	scopes := flag.String("scopes", "", "comma-separated platform permissions to grant")
+	ttl := flag.Duration("ttl", 8*time.Hour, "how long the token is valid")
+	flag.Parse()
+
+	var cfg Config
+	if err := envconfig.Process("", &cfg); err != nil {
+		log.Fatalf("Failed to process environment variables: %v", err)
+	}
+
+	var permissions []account.Permission
+	for _, scope := range strings.Split(*scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			permissions = append(permissions, account.Permission(scope))
+		}
+	}
+
+	tokens, err := account.NewTokenManager("", cfg.StaffSecret, 0, 0)
+	if err != nil {
+		log.Fatalf("Failed to set up tokens: %v", err)
+	}
+	token, expiresAt, err := tokens.IssueForStaff(strings.TrimSpace(*name), permissions, *ttl)
+	if err != nil {
+		log.Fatalf("Failed to issue token: %v", err)
+	}
+	log.Printf("Token for %s expires at %s", *name, expiresAt.Format(time.RFC3339))
+	fmt.Println(token)
+}
@@ -0,0 +1,95 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+)
+
+// Reasons a login failed.
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureLockedOut          = "locked_out"
+)
+
+const (
+	defaultLoginHistory = 50
+	maxLoginHistory     = 200
+)
+
+// LoginEvent is a login attempt in an account's history.
+type LoginEvent struct {
+	ID            string
+	AccountID     string
+	Device        Device
+	Network       string // The network the IP belongs to, which stands in for its location.
+	Success       bool
+	FailureReason string
+	NewDevice     bool // The first successful login from this device or network.
+	CreatedAt     time.Time
+}
+
+// ListLoginHistory returns the user's most recent login attempts, newest first.
+// A limit of 0 returns the default number.
+func (s *accountService) ListLoginHistory(ctx context.Context, userID string, limit int) ([]LoginEvent, error) {
+	if limit <= 0 {
+		limit = defaultLoginHistory
+	}
+	return s.repo.ListLoginEvents(ctx, userID, min(limit, maxLoginHistory))
+}
+
+// recordLogin adds a successful login to the user's history and emails them
+// if it came from a device or network they have not logged in from before.
+func (s *accountService) recordLogin(ctx context.Context, user *Account, device Device) {
+	event := LoginEvent{
+		AccountID: user.ID.String(),
+		Device:    device,
+		Network:   networkOf(device.IP),
+		Success:   true,
+	}
+	seenDevice, seenNetwork, returning, err := s.repo.LoginSourceSeen(ctx, event.AccountID, device.UserAgent, event.Network)
+	if err != nil {
+		log.Printf("Failed to check login source for account %s: %v", event.AccountID, err)
+		return
+	}
+	event.NewDevice = returning && !(seenDevice && seenNetwork)
+	if err := s.repo.AddLoginEvent(ctx, event); err != nil {
+		log.Printf("Failed to record login for account %s: %v", event.AccountID, err)
+		return
+	}
+	if !event.NewDevice {
+		return
+	}
+
+	err = s.mailer.Send(ctx, Message{
+		To:      user.Email,
+		Subject: "New sign-in to your Logilo account",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account was just signed in to from a new device or location.\n\nTime: %s\nDevice: %s\nIP address: %s\n\nIf this was you, there is nothing to do. If not, reset your password and sign out of all sessions at %s/settings/security\n",
+			user.Name, time.Now().UTC().Format("2 Jan 2006 15:04 MST"), orUnknown(device.UserAgent), orUnknown(device.IP), s.appURL),
+	})
+	if err != nil {
+		log.Printf("Failed to email new sign-in alert to account %s: %v", event.AccountID, err)
+	}
+}
+
+// networkOf returns the network of ip: its /24 for IPv4 and /48 for IPv6.
+// Addresses in the same network are treated as the same location.
+func networkOf(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ip
+	}
+	if v4 := addr.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: addr.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,204 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Policy is what an RPC requires of the access token it is called with.
+type Policy struct {
+	// Permission is required of the caller's role; any member may call the
+	// RPC if it is empty. Only staff tokens hold platform permissions, and
+	// they can only call RPCs that require one.
+	Permission Permission
+
+	// Account returns the account the request acts for, which must be the one
+	// the token acts for. It is nil for requests that do not act for one.
+	Account func(req any) string
+
+	// User returns the user the request acts as, which must be the token's
+	// user. It is nil for requests that do not name one.
+	User func(req any) string
+
+	// Internal lets other services call the RPC too, for any account, with
+	// staff tokens granting PermissionInternal.
+	Internal bool
+}
+
+type claimsKey struct{}
+
+// UnaryServerInterceptor authenticates calls to the RPCs in policies, keyed by
+// full method name, with the access token in their authorization metadata.
+// The gateway forwards its callers' tokens, and services call each other with
+// staff tokens from ServiceCredentials. Requests that carry an actor_id must
+// name the token's user, so API keys cannot make them. Other RPCs are called
+// as before.
+func UnaryServerInterceptor(tokens *TokenManager, policies map[string]Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		policy, ok := policies[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		claims, err := authenticateCall(ctx, tokens, policy)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizeRequest(claims, policy, req); err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, claimsKey{}, claims), req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs. Each
+// message the client sends is checked against the policy as it is received.
+func StreamServerInterceptor(tokens *TokenManager, policies map[string]Policy) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		policy, ok := policies[info.FullMethod]
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		claims, err := authenticateCall(ss.Context(), tokens, policy)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ss.Context(), claimsKey{}, claims),
+			claims:       claims,
+			policy:       policy,
+		})
+	}
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	claims *Claims
+	policy Policy
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return authorizeRequest(s.claims, s.policy, m)
+}
+
+// authenticateCall verifies the call's access token and that it grants the
+// policy's permission.
+func authenticateCall(ctx context.Context, tokens *TokenManager, policy Policy) (*Claims, error) {
+	claims, err := incomingClaims(ctx, tokens)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if claims.Staff != "" && policy.Internal && claims.Can(PermissionInternal) {
+		return claims, nil
+	}
+	if claims.Staff != "" && !PlatformPermission(policy.Permission) {
+		return nil, status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+	if policy.Permission != "" && !claims.Can(policy.Permission) {
+		return nil, status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+	return claims, nil
+}
+
+// authorizeRequest checks that req acts as the token's user and for its
+// account.
+func authorizeRequest(claims *Claims, policy Policy, req any) error {
+	if claims.Staff != "" && policy.Internal && claims.Can(PermissionInternal) {
+		return nil
+	}
+	if r, ok := req.(interface{ GetActorId() string }); ok && (claims.UserID == "" || r.GetActorId() != claims.UserID) {
+		return status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+	if policy.User != nil && (claims.UserID == "" || policy.User(req) != claims.UserID) {
+		return status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+	if policy.Account != nil && policy.Account(req) != claims.AccountID {
+		return status.Error(codes.PermissionDenied, ErrNotTeamMember.Error())
+	}
+	return nil
+}
+
+// RequestAccountID returns the account_id of requests that have one.
+func RequestAccountID(req any) string {
+	if r, ok := req.(interface{ GetAccountId() string }); ok {
+		return r.GetAccountId()
+	}
+	return ""
+}
+
+// ClaimsFromContext returns the claims of the access token a call was
+// authenticated with by UnaryServerInterceptor.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+func incomingClaims(ctx context.Context, tokens *TokenManager) (*Claims, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, ErrInvalidToken
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return tokens.Verify(token, TokenAccess)
+}
+
+// serviceTokenTTL is the lifetime of the staff tokens services call each
+// other with. They are renewed a minute before they expire.
+const serviceTokenTTL = 10 * time.Minute
+
+// ServiceCredentials authenticates a service's calls to other services with
+// staff tokens naming the service and granting PermissionInternal. Pass it to
+// a client with grpc.WithPerRPCCredentials.
+func ServiceCredentials(tokens *TokenManager, service string) credentials.PerRPCCredentials {
+	return &serviceCredentials{tokens: tokens, service: service}
+}
+
+type serviceCredentials struct {
+	tokens  *TokenManager
+	service string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (c *serviceCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Until(c.expiresAt) < time.Minute {
+		token, expiresAt, err := c.tokens.IssueForStaff(c.service, []Permission{PermissionInternal}, serviceTokenTTL)
+		if err != nil {
+			return nil, err
+		}
+		c.token, c.expiresAt = token, expiresAt
+	}
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+// RequireTransportSecurity is false: the services talk over the private
+// network without TLS.
+func (c *serviceCredentials) RequireTransportSecurity() bool {
+	return false
+}
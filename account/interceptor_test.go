@@ -0,0 +1,194 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shridhar2104/logilo/account/pb"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// interceptorTokens signs the tokens the interceptor tests call with.
+type interceptorTokens struct {
+	t *testing.T
+	m *TokenManager
+}
+
+func newInterceptorTokens(t *testing.T) *interceptorTokens {
+	m, err := NewTokenManager("user secret", "staff secret", time.Minute, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &interceptorTokens{t, m}
+}
+
+// user returns an access token for userID acting for accountID with role.
+func (tk *interceptorTokens) user(userID, accountID, role string) string {
+	pair, err := tk.m.Issue(&Account{ID: uuid.MustParse(userID)}, &Membership{AccountID: accountID, Role: role}, "sess")
+	if err != nil {
+		tk.t.Fatal(err)
+	}
+	return pair.AccessToken
+}
+
+func (tk *interceptorTokens) key(accountID string, scopes ...Permission) string {
+	token, _, err := tk.m.IssueForAPIKey(&APIKey{ID: "key", AccountID: accountID, Scopes: scopes}, true, time.Minute)
+	if err != nil {
+		tk.t.Fatal(err)
+	}
+	return token
+}
+
+func (tk *interceptorTokens) staff(scopes ...Permission) string {
+	token, _, err := tk.m.IssueForStaff("ops@logilo.in", scopes, time.Minute)
+	if err != nil {
+		tk.t.Fatal(err)
+	}
+	return token
+}
+
+func withToken(token string) context.Context {
+	if token == "" {
+		return context.Background()
+	}
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const (
+		owner = "00000000-0000-0000-0000-000000000001"
+		other = "00000000-0000-0000-0000-000000000002"
+	)
+	tk := newInterceptorTokens(t)
+	policies := map[string]Policy{
+		"/team":     {Permission: PermissionManageTeam, Account: RequestAccountID},
+		"/internal": {Permission: PermissionViewBilling, Account: RequestAccountID, Internal: true},
+		"/kyc":      {Permission: PermissionReviewKYC},
+		"/open":     {},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		req    any
+		want   codes.Code
+	}{
+		{"own team", "/team", tk.user(owner, owner, RoleOwner), &pb.ListMembersRequest{ActorId: owner, AccountId: owner}, codes.OK},
+		{"no token", "/team", "", &pb.ListMembersRequest{ActorId: owner, AccountId: owner}, codes.Unauthenticated},
+		{"forged token", "/team", tk.user(owner, owner, RoleOwner) + "x", &pb.ListMembersRequest{ActorId: owner, AccountId: owner}, codes.Unauthenticated},
+		{"actor is another user", "/team", tk.user(owner, owner, RoleOwner), &pb.ListMembersRequest{ActorId: other, AccountId: owner}, codes.PermissionDenied},
+		{"another account", "/team", tk.user(owner, owner, RoleOwner), &pb.ListMembersRequest{ActorId: owner, AccountId: other}, codes.PermissionDenied},
+		{"role without the permission", "/team", tk.user(other, owner, RoleFinance), &pb.ListMembersRequest{ActorId: other, AccountId: owner}, codes.PermissionDenied},
+		{"api key on an actor request", "/team", tk.key(owner, PermissionManageTeam), &pb.ListMembersRequest{AccountId: owner}, codes.PermissionDenied},
+		{"api key with the scope", "/internal", tk.key(owner, PermissionViewBilling), &pb.GetKYCStatusRequest{AccountId: owner}, codes.OK},
+		{"api key without the scope", "/internal", tk.key(owner, PermissionViewShipments), &pb.GetKYCStatusRequest{AccountId: owner}, codes.PermissionDenied},
+		{"service on an internal rpc", "/internal", tk.staff(PermissionInternal), &pb.GetKYCStatusRequest{AccountId: other}, codes.OK},
+		{"service on a merchant rpc", "/team", tk.staff(PermissionInternal), &pb.ListMembersRequest{AccountId: owner}, codes.PermissionDenied},
+		{"staff with the platform permission", "/kyc", tk.staff(PermissionReviewKYC), &pb.ListPendingKYCRequest{}, codes.OK},
+		{"service on a staff rpc", "/kyc", tk.staff(PermissionInternal), &pb.ListPendingKYCRequest{}, codes.PermissionDenied},
+		{"owner on a staff rpc", "/kyc", tk.user(owner, owner, RoleOwner), &pb.ListPendingKYCRequest{}, codes.PermissionDenied},
+		{"rpc with an empty policy", "/open", tk.user(owner, owner, RoleViewer), &pb.ListPendingKYCRequest{}, codes.OK},
+		{"rpc without a policy", "/public", "", &pb.ListPendingKYCRequest{}, codes.OK},
+	}
+	interceptor := UnaryServerInterceptor(tk.m, policies)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				if _, ok := ClaimsFromContext(ctx); !ok && tt.method != "/public" {
+					t.Error("handler called without claims")
+				}
+				return nil, nil
+			}
+			_, err := interceptor(withToken(tt.token), tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("interceptor() code = %v, want %v (%v)", got, tt.want, err)
+			}
+			if called != (tt.want == codes.OK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+func TestInterceptorRolePermissions(t *testing.T) {
+	const user = "00000000-0000-0000-0000-000000000001"
+	tk := newInterceptorTokens(t)
+
+	// What each team role may do, written out rather than read from
+	// rolePermissions so that a change to a role shows up here.
+	granted := map[string][]Permission{
+		RoleOwner:   {PermissionManageTeam, PermissionViewShipments, PermissionManageShipments, PermissionViewBilling, PermissionManageBilling, PermissionManageAPIKeys, PermissionManageProfile},
+		RoleAdmin:   {PermissionManageTeam, PermissionViewShipments, PermissionManageShipments, PermissionViewBilling, PermissionManageBilling, PermissionManageAPIKeys, PermissionManageProfile},
+		RoleOps:     {PermissionViewShipments, PermissionManageShipments},
+		RoleFinance: {PermissionViewShipments, PermissionViewBilling, PermissionManageBilling},
+		RoleViewer:  {PermissionViewShipments},
+	}
+	all := append([]Permission{PermissionManageTeam, PermissionViewShipments, PermissionManageShipments, PermissionViewBilling,
+		PermissionManageBilling, PermissionManageAPIKeys, PermissionManageProfile}, platformPermissions...)
+
+	for role, perms := range granted {
+		token := tk.user(user, user, role)
+		for _, p := range all {
+			want := codes.PermissionDenied
+			for _, g := range perms {
+				if g == p {
+					want = codes.OK
+				}
+			}
+			interceptor := UnaryServerInterceptor(tk.m, map[string]Policy{"/rpc": {Permission: p, Account: RequestAccountID}})
+			_, err := interceptor(withToken(token), &pb.GetProfileRequest{ActorId: user, AccountId: user}, &grpc.UnaryServerInfo{FullMethod: "/rpc"},
+				func(context.Context, any) (any, error) { return nil, nil })
+			if got := status.Code(err); got != want {
+				t.Errorf("%s calling an rpc needing %s: code = %v, want %v", role, p, got, want)
+			}
+		}
+	}
+}
+
+// recvStream is a server stream receiving msgs.
+type recvStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []proto.Message
+}
+
+func (s *recvStream) Context() context.Context { return s.ctx }
+
+func (s *recvStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.msgs[0])
+	s.msgs = s.msgs[1:]
+	return nil
+}
+
+func TestStreamServerInterceptorChecksEachMessage(t *testing.T) {
+	const (
+		owner = "00000000-0000-0000-0000-000000000001"
+		other = "00000000-0000-0000-0000-000000000002"
+	)
+	tk := newInterceptorTokens(t)
+	interceptor := StreamServerInterceptor(tk.m, map[string]Policy{"/stream": {Permission: PermissionManageTeam, Account: RequestAccountID}})
+	ss := &recvStream{
+		ctx:  withToken(tk.user(owner, owner, RoleOwner)),
+		msgs: []proto.Message{&pb.ListMembersRequest{ActorId: owner, AccountId: owner}, &pb.ListMembersRequest{ActorId: owner, AccountId: other}},
+	}
+
+	err := interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/stream"}, func(srv any, stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&pb.ListMembersRequest{}); err != nil {
+			t.Fatalf("first message: %v", err)
+		}
+		return stream.RecvMsg(&pb.ListMembersRequest{})
+	})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("second message for another account: code = %v, want %v", got, codes.PermissionDenied)
+	}
+}
@@ -0,0 +1,222 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KYC review statuses of an account.
+const (
+	KYCStatusNotSubmitted = "not_submitted"
+	KYCStatusPending      = "pending"
+	KYCStatusApproved     = "approved"
+	KYCStatusRejected     = "rejected"
+)
+
+// KYC document types.
+const (
+	DocumentPAN             = "pan"
+	DocumentGSTCertificate  = "gst_certificate"
+	DocumentCancelledCheque = "cancelled_cheque"
+	DocumentAddressProof    = "address_proof"
+)
+
+// maxKYCDocumentSize keeps uploads within the default gRPC message size.
+const maxKYCDocumentSize = 3 << 20
+
+var (
+	ErrInvalidDocument        = errors.New("document must be a PDF, PNG or JPEG file of at most 3 MB")
+	ErrInvalidDocumentType    = errors.New("invalid document type")
+	ErrKYCDocumentNotFound    = errors.New("kyc document not found")
+	ErrKYCNotPending          = errors.New("kyc is not awaiting review")
+	ErrKYCIncomplete          = errors.New("kyc cannot be approved without a profile and PAN document")
+	ErrMissingRejectionReason = errors.New("a reason is required to reject kyc")
+)
+
+var documentTypes = []string{DocumentPAN, DocumentGSTCertificate, DocumentCancelledCheque, DocumentAddressProof}
+
+// KYCDocument is a document uploaded for KYC review. The file itself is kept
+// in the blob store.
+type KYCDocument struct {
+	ID          string
+	AccountID   string
+	Type        string
+	Filename    string
+	ContentType string
+	Size        int
+	UploadedBy  string
+	UploadedAt  time.Time
+
+	blobKey string
+}
+
+// KYCSubmission is an account awaiting KYC review, with its documents.
+type KYCSubmission struct {
+	Profile   Profile
+	Documents []KYCDocument
+}
+
+// UploadKYCDocument stores a KYC document and submits the account for review,
+// unless its KYC is already approved.
+func (s *accountService) UploadKYCDocument(ctx context.Context, actorID string, accountID string, docType string, filename string, data []byte) (*KYCDocument, error) {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageProfile); err != nil {
+		return nil, err
+	}
+	if !validDocumentType(docType) {
+		return nil, ErrInvalidDocumentType
+	}
+	contentType := http.DetectContentType(data)
+	if len(data) == 0 || len(data) > maxKYCDocumentSize ||
+		(contentType != "application/pdf" && contentType != "image/png" && contentType != "image/jpeg") {
+		return nil, ErrInvalidDocument
+	}
+
+	doc := KYCDocument{
+		ID:          uuid.NewString(),
+		AccountID:   accountID,
+		Type:        docType,
+		Filename:    strings.TrimSpace(filename),
+		ContentType: contentType,
+		Size:        len(data),
+		UploadedBy:  actorID,
+	}
+	doc.blobKey = fmt.Sprintf("kyc/%s/%s", accountID, doc.ID)
+	if err := s.blobs.Put(ctx, doc.blobKey, data); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.AddKYCDocument(ctx, doc)
+	if err != nil {
+		s.blobs.Delete(ctx, doc.blobKey)
+		return nil, err
+	}
+	return created, nil
+}
+
+// ListKYCDocuments returns the documents the account has uploaded.
+func (s *accountService) ListKYCDocuments(ctx context.Context, actorID string, accountID string) ([]KYCDocument, error) {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageProfile); err != nil {
+		return nil, err
+	}
+	return s.repo.ListKYCDocuments(ctx, accountID)
+}
+
+// GetKYCDocument returns a KYC document and its file, for reviewers.
+func (s *accountService) GetKYCDocument(ctx context.Context, accountID string, documentID string) (*KYCDocument, []byte, error) {
+	doc, err := s.repo.GetKYCDocument(ctx, accountID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := s.blobs.Get(ctx, doc.blobKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, data, nil
+}
+
+// ListPendingKYC returns the accounts awaiting KYC review, oldest first.
+func (s *accountService) ListPendingKYC(ctx context.Context) ([]KYCSubmission, error) {
+	profiles, err := s.repo.ListProfilesByKYCStatus(ctx, KYCStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	submissions := make([]KYCSubmission, len(profiles))
+	for i, p := range profiles {
+		docs, err := s.repo.ListKYCDocuments(ctx, p.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		submissions[i] = KYCSubmission{Profile: p, Documents: docs}
+	}
+	return submissions, nil
+}
+
+// ReviewKYC approves or rejects an account's pending KYC and emails the
+// account the outcome. Approval needs a complete profile and a PAN document;
+// rejection needs a reason.
+func (s *accountService) ReviewKYC(ctx context.Context, reviewer string, accountID string, approve bool, reason string) (*Profile, error) {
+	status := KYCStatusRejected
+	if approve {
+		status = KYCStatusApproved
+		p, err := s.repo.GetProfile(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := s.repo.ListKYCDocuments(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if p.PAN == "" || !hasDocument(docs, DocumentPAN) {
+			return nil, ErrKYCIncomplete
+		}
+		reason = ""
+	} else if strings.TrimSpace(reason) == "" {
+		return nil, ErrMissingRejectionReason
+	}
+
+	p, err := s.repo.ReviewKYC(ctx, accountID, reviewer, status, strings.TrimSpace(reason))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.notifyKYCReview(ctx, p); err != nil {
+		log.Printf("Failed to email kyc review to account %s: %v", accountID, err)
+	}
+	return p, nil
+}
+
+// KYCApproved reports whether the account's KYC has been approved.
+func (s *accountService) KYCApproved(ctx context.Context, accountID string) (bool, error) {
+	p, err := s.repo.GetProfile(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+	return p.KYCStatus == KYCStatusApproved, nil
+}
+
+// EmailVerified reports whether the account's email address has been verified.
+func (s *accountService) EmailVerified(ctx context.Context, accountID string) (bool, error) {
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+	return account.EmailVerifiedAt != nil, nil
+}
+
+func (s *accountService) notifyKYCReview(ctx context.Context, p *Profile) error {
+	account, err := s.repo.GetAccountByID(ctx, p.AccountID)
+	if err != nil {
+		return err
+	}
+	msg := Message{To: account.Email, Subject: "Your KYC has been approved"}
+	msg.Body = fmt.Sprintf("Hi %s,\n\nThe KYC documents for %s have been approved. COD remittances and payouts are now enabled.\n", account.Name, p.CompanyName)
+	if p.KYCStatus == KYCStatusRejected {
+		msg.Subject = "Your KYC needs attention"
+		msg.Body = fmt.Sprintf("Hi %s,\n\nWe could not approve the KYC documents for %s:\n\n%s\n\nUpload corrected documents to submit them for review again.\n", account.Name, p.CompanyName, p.KYCRejectionReason)
+	}
+	return s.mailer.Send(ctx, msg)
+}
+
+func validDocumentType(docType string) bool {
+	for _, t := range documentTypes {
+		if t == docType {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDocument(docs []KYCDocument, docType string) bool {
+	for _, d := range docs {
+		if d.Type == docType {
+			return true
+		}
+	}
+	return false
+}
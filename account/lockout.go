@@ -0,0 +1,135 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Failed logins are counted per email address and per client IP. Once either
+// count reaches its threshold, logins for it are refused for a lockout that
+// doubles with every further failure. Counting by email address rather than
+// account means unknown addresses lock out exactly like real ones.
+const (
+	emailLockoutThreshold = 5
+	ipLockoutThreshold    = 20 // Higher, since many users can share an address.
+	baseLockout           = 30 * time.Second
+	maxLockout            = time.Hour
+
+	// Failures older than these windows are forgotten.
+	emailFailureWindow = 24 * time.Hour
+	ipFailureWindow    = time.Hour
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrTooManyAttempts    = errors.New("too many failed login attempts")
+)
+
+// loginThrottle is one of the counters a login attempt is checked against.
+type loginThrottle struct {
+	key       string
+	threshold int
+	window    time.Duration
+}
+
+func loginThrottles(email string, device Device) []loginThrottle {
+	throttles := []loginThrottle{{"email:" + strings.ToLower(strings.TrimSpace(email)), emailLockoutThreshold, emailFailureWindow}}
+	if device.IP != "" {
+		throttles = append(throttles, loginThrottle{"ip:" + device.IP, ipLockoutThreshold, ipFailureWindow})
+	}
+	return throttles
+}
+
+// checkCredentials validates the email and password, refusing attempts while
+// the email address or the device's IP is locked out. Every failure returns
+// ErrInvalidCredentials or ErrTooManyAttempts, whether or not the account
+// exists.
+//
+// Each attempt is counted as a failure before the password is compared, so
+// that concurrent attempts cannot all slip in under the threshold: attempts
+// that find others counted ahead of them, taking the count into a lockout, are
+// refused.
+func (s *accountService) checkCredentials(ctx context.Context, email string, password string, device Device) (*Account, error) {
+	throttles := loginThrottles(email, device)
+	seen := make([]int, len(throttles))
+	for i, t := range throttles {
+		failures, last, err := s.repo.LoginFailures(ctx, t.key, t.window)
+		if err != nil {
+			return nil, err
+		}
+		if wait := time.Until(last.Add(lockoutFor(failures, t.threshold))); wait > 0 {
+			s.recordFailedLogin(ctx, email, device, LoginFailureLockedOut)
+			return nil, fmt.Errorf("%w: try again in %s", ErrTooManyAttempts, wait.Round(time.Second))
+		}
+		seen[i] = failures
+	}
+
+	for i, t := range throttles {
+		failures, err := s.repo.RecordLoginFailure(ctx, t.key, t.window)
+		if err != nil {
+			return nil, err
+		}
+		if lockout := lockoutFor(failures-1, t.threshold); failures > seen[i]+1 && lockout > 0 {
+			s.recordFailedLogin(ctx, email, device, LoginFailureLockedOut)
+			return nil, fmt.Errorf("%w: try again in %s", ErrTooManyAttempts, lockout)
+		}
+	}
+
+	account, err := s.repo.GetAccountByEmailAndPassword(ctx, email, password)
+	if errors.Is(err, ErrInvalidCredentials) {
+		s.recordFailedLogin(ctx, email, device, LoginFailureInvalidCredentials)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The email counter is cleared, but only this attempt is taken back from
+	// the others: one good password must not unlock an address that is
+	// guessing at other accounts.
+	if err := s.repo.ClearLoginFailures(ctx, throttles[0].key); err != nil {
+		return nil, err
+	}
+	for _, t := range throttles[1:] {
+		if err := s.repo.RetractLoginFailure(ctx, t.key); err != nil {
+			return nil, err
+		}
+	}
+	return account, nil
+}
+
+// recordFailedLogin adds a failed attempt to the login history of the account
+// with the email address, if there is one.
+func (s *accountService) recordFailedLogin(ctx context.Context, email string, device Device, reason string) {
+	account, err := s.repo.GetAccountByEmail(ctx, email)
+	if errors.Is(err, ErrAccountNotFound) {
+		return
+	}
+	if err == nil {
+		err = s.repo.AddLoginEvent(ctx, LoginEvent{
+			AccountID:     account.ID.String(),
+			Device:        device,
+			Network:       networkOf(device.IP),
+			FailureReason: reason,
+		})
+	}
+	if err != nil {
+		log.Printf("Failed to record failed login for %s: %v", email, err)
+	}
+}
+
+// lockoutFor returns how long logins are refused after failures, given the
+// threshold at which lockouts start.
+func lockoutFor(failures int, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+	if failures-threshold >= 7 {
+		return maxLockout
+	}
+	return min(baseLockout<<(failures-threshold), maxLockout)
+}
@@ -0,0 +1,180 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLockoutFor(t *testing.T) {
+	tests := []struct {
+		failures  int
+		threshold int
+		want      time.Duration
+	}{
+		{0, 5, 0},
+		{4, 5, 0},
+		{5, 5, 30 * time.Second},
+		{6, 5, time.Minute},
+		{9, 5, 8 * time.Minute},
+		{11, 5, 32 * time.Minute},
+		{12, 5, time.Hour},
+		{100, 5, time.Hour},
+		{19, 20, 0},
+		{20, 20, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := lockoutFor(tt.failures, tt.threshold); got != tt.want {
+			t.Errorf("lockoutFor(%d, %d) = %v, want %v", tt.failures, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+// loginRepository counts login failures in memory. One account, with password
+// "right", exists.
+type loginRepository struct {
+	Repository
+	account  *Account
+	failures map[string]int
+	last     map[string]time.Time
+	compared int
+
+	// race, if set, runs between reading a count and recording a failure, as
+	// a concurrent attempt would.
+	race func()
+}
+
+func newLoginRepository() *loginRepository {
+	return &loginRepository{
+		account:  &Account{ID: uuid.New(), Email: "merchant@example.com"},
+		failures: map[string]int{},
+		last:     map[string]time.Time{},
+	}
+}
+
+func (r *loginRepository) LoginFailures(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	return r.failures[key], r.last[key], nil
+}
+
+func (r *loginRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	if r.race != nil {
+		race := r.race
+		r.race = nil
+		race()
+	}
+	r.failures[key]++
+	r.last[key] = time.Now()
+	return r.failures[key], nil
+}
+
+func (r *loginRepository) RetractLoginFailure(ctx context.Context, key string) error {
+	r.failures[key]--
+	return nil
+}
+
+func (r *loginRepository) ClearLoginFailures(ctx context.Context, key string) error {
+	delete(r.failures, key)
+	return nil
+}
+
+func (r *loginRepository) GetAccountByEmailAndPassword(ctx context.Context, email string, password string) (*Account, error) {
+	r.compared++
+	if email != r.account.Email || password != "right" {
+		return nil, ErrInvalidCredentials
+	}
+	return r.account, nil
+}
+
+func (r *loginRepository) GetAccountByEmail(ctx context.Context, email string) (*Account, error) {
+	return nil, ErrAccountNotFound
+}
+
+func TestCheckCredentialsLocksOutEmail(t *testing.T) {
+	repo := newLoginRepository()
+	s := &accountService{repo: repo}
+	ctx := context.Background()
+
+	for i := 0; i < emailLockoutThreshold; i++ {
+		device := Device{IP: fmt.Sprintf("203.0.113.%d", i+1)} // A new address each time.
+		if _, err := s.checkCredentials(ctx, "Merchant@example.com ", "wrong", device); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: error = %v, want %v", i+1, err, ErrInvalidCredentials)
+		}
+	}
+	if _, err := s.checkCredentials(ctx, "merchant@example.com", "right", Device{IP: "198.51.100.1"}); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("right password while locked out: error = %v, want %v", err, ErrTooManyAttempts)
+	}
+	if repo.compared != emailLockoutThreshold {
+		t.Errorf("compared %d passwords, want %d", repo.compared, emailLockoutThreshold)
+	}
+
+	// Once the lockout lapses the right password gets in and clears the count.
+	repo.last["email:merchant@example.com"] = time.Now().Add(-baseLockout)
+	if _, err := s.checkCredentials(ctx, "merchant@example.com", "right", Device{IP: "198.51.100.1"}); err != nil {
+		t.Fatalf("right password after the lockout: %v", err)
+	}
+	if n := repo.failures["email:merchant@example.com"]; n != 0 {
+		t.Errorf("email failures after logging in = %d, want 0", n)
+	}
+	if n := repo.failures["ip:198.51.100.1"]; n != 0 {
+		t.Errorf("ip failures after logging in = %d, want 0", n)
+	}
+}
+
+func TestCheckCredentialsLocksOutIP(t *testing.T) {
+	repo := newLoginRepository()
+	s := &accountService{repo: repo}
+	ctx := context.Background()
+	device := Device{IP: "203.0.113.9"}
+
+	for i := 0; i < ipLockoutThreshold; i++ {
+		email := uuid.NewString() + "@example.com" // Guessing at a new account each time.
+		if _, err := s.checkCredentials(ctx, email, "wrong", device); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: error = %v, want %v", i+1, err, ErrInvalidCredentials)
+		}
+	}
+	if _, err := s.checkCredentials(ctx, "merchant@example.com", "right", device); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("login from a locked out address: error = %v, want %v", err, ErrTooManyAttempts)
+	}
+	if _, err := s.checkCredentials(ctx, "merchant@example.com", "right", Device{IP: "198.51.100.1"}); err != nil {
+		t.Errorf("login from another address: %v", err)
+	}
+
+	// A good password from the address does not wipe out its failures.
+	repo.last["ip:"+device.IP] = time.Now().Add(-baseLockout)
+	if _, err := s.checkCredentials(ctx, "merchant@example.com", "right", device); err != nil {
+		t.Fatalf("login after the lockout: %v", err)
+	}
+	if n := repo.failures["ip:"+device.IP]; n != ipLockoutThreshold {
+		t.Errorf("ip failures after logging in = %d, want %d", n, ipLockoutThreshold)
+	}
+}
+
+func TestCheckCredentialsRefusesConcurrentAttempts(t *testing.T) {
+	repo := newLoginRepository()
+	s := &accountService{repo: repo}
+	ctx := context.Background()
+	key := "email:merchant@example.com"
+	repo.failures[key] = emailLockoutThreshold - 1
+	repo.last[key] = time.Now()
+
+	// Another attempt is counted after this one read the count, taking it to
+	// the threshold.
+	repo.race = func() { repo.failures[key]++ }
+	if _, err := s.checkCredentials(ctx, "merchant@example.com", "right", Device{}); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("error = %v, want %v", err, ErrTooManyAttempts)
+	}
+	if repo.compared != 0 {
+		t.Errorf("compared %d passwords, want 0", repo.compared)
+	}
+
+	// Below the threshold, concurrent attempts are let through.
+	repo.failures[key] = 1
+	repo.race = func() { repo.failures[key]++ }
+	if _, err := s.checkCredentials(ctx, "merchant@example.com", "wrong", Device{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
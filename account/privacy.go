@@ -0,0 +1,441 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/logilo/datasubject"
+	"github.com/google/uuid"
+)
+
+// Kinds of data-subject request.
+const (
+	DataRequestExport  = "export"
+	DataRequestErasure = "erasure"
+)
+
+// Statuses of a data-subject request.
+const (
+	DataRequestPending   = "pending"
+	DataRequestRunning   = "running"
+	DataRequestCompleted = "completed"
+	DataRequestFailed    = "failed"
+	DataRequestExpired   = "expired" // The export archive has been deleted.
+)
+
+const (
+	dataExportTTL          = 7 * 24 * time.Hour
+	maxDataRequestAttempts = 5
+	dataRequestRetryDelay  = 10 * time.Minute // Multiplied by the attempts so far.
+
+	// A request still running after this long is assumed abandoned by a
+	// crashed worker and is claimed again.
+	staleDataRequest = time.Hour
+)
+
+// accountParticipant names the account service's own folder in exports.
+const accountParticipant = "account"
+
+var (
+	ErrDataRequestNotFound   = errors.New("data request not found")
+	ErrDataRequestInProgress = errors.New("a request of this kind is already in progress")
+	ErrDataExportUnavailable = errors.New("data export is not ready or has expired")
+)
+
+// DataRequest is a merchant's request to export or erase the data held about
+// them across every service.
+type DataRequest struct {
+	ID          string
+	AccountID   string
+	Kind        string
+	Status      string
+	Attempts    int
+	LastError   string
+	Retained    []string // For erasures, what each service kept and why.
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time // When an export's archive is deleted.
+
+	archiveKey string // Blob key of the export archive.
+}
+
+// RequestDataExport queues an export of the data held about the user and
+// their merchant account. The archive can be downloaded once it completes.
+func (s *accountService) RequestDataExport(ctx context.Context, actorID string) (*DataRequest, error) {
+	if _, err := s.repo.GetAccountByID(ctx, actorID); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateDataRequest(ctx, DataRequest{ID: uuid.NewString(), AccountID: actorID, Kind: DataRequestExport})
+}
+
+// RequestDataErasure queues the erasure of the user and their merchant
+// account after checking their password. Like deleting the account, it needs
+// the other team members removed first.
+func (s *accountService) RequestDataErasure(ctx context.Context, actorID string, password string) (*DataRequest, error) {
+	account, err := s.repo.GetAccountByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkCredentials(ctx, account.Email, password, Device{}); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) > 1 {
+		return nil, ErrTeamHasMembers
+	}
+	return s.repo.CreateDataRequest(ctx, DataRequest{ID: uuid.NewString(), AccountID: actorID, Kind: DataRequestErasure})
+}
+
+// ListDataRequests returns the user's data requests, newest first.
+func (s *accountService) ListDataRequests(ctx context.Context, actorID string) ([]DataRequest, error) {
+	return s.repo.ListDataRequests(ctx, actorID)
+}
+
+// GetDataExport returns the zip archive of a completed, unexpired export.
+func (s *accountService) GetDataExport(ctx context.Context, actorID string, requestID string) (*DataRequest, []byte, error) {
+	req, err := s.repo.GetDataRequest(ctx, actorID, requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Kind != DataRequestExport || req.Status != DataRequestCompleted || req.archiveKey == "" ||
+		(req.ExpiresAt != nil && time.Now().After(*req.ExpiresAt)) {
+		return nil, nil, ErrDataExportUnavailable
+	}
+	data, err := s.blobs.Get(ctx, req.archiveKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, data, nil
+}
+
+// ProcessDataRequests deletes expired export archives and then works through
+// the due requests, fanning each out to every participating service.
+func (s *accountService) ProcessDataRequests(ctx context.Context) error {
+	keys, err := s.repo.ExpireDataExports(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete expired data export %s: %v", key, err)
+		}
+	}
+
+	for {
+		req, err := s.repo.ClaimDataRequest(ctx, time.Now().Add(-staleDataRequest))
+		if errors.Is(err, ErrDataRequestNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch req.Kind {
+		case DataRequestExport:
+			err = s.runDataExport(ctx, req)
+		case DataRequestErasure:
+			err = s.runDataErasure(ctx, req)
+		default:
+			err = fmt.Errorf("unknown data request kind %q", req.Kind)
+		}
+		if err == nil {
+			continue
+		}
+
+		log.Printf("Data request %s failed on attempt %d: %v", req.ID, req.Attempts, err)
+		var retryAt *time.Time
+		if req.Attempts < maxDataRequestAttempts {
+			next := time.Now().Add(time.Duration(req.Attempts) * dataRequestRetryDelay)
+			retryAt = &next
+		}
+		if err := s.repo.FailDataRequest(ctx, req.ID, err.Error(), retryAt); err != nil {
+			return err
+		}
+	}
+}
+
+// runDataExport collects every participant's files into a zip archive, one
+// folder per service, and emails the user that it is ready.
+func (s *accountService) runDataExport(ctx context.Context, req *DataRequest) error {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, p := range s.dataParticipants() {
+		files, err := p.Provider.ExportSubjectData(ctx, req.AccountID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		for _, f := range files {
+			w, err := archive.Create(p.Name + "/" + f.Name)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(f.Content); err != nil {
+				return err
+			}
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("data-exports/%s/%s.zip", req.AccountID, req.ID)
+	if err := s.blobs.Put(ctx, key, buf.Bytes()); err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(dataExportTTL)
+	if err := s.repo.CompleteDataRequest(ctx, req.ID, key, nil, &expiresAt); err != nil {
+		s.blobs.Delete(ctx, key)
+		return err
+	}
+
+	account, err := s.repo.GetAccountByID(ctx, req.AccountID)
+	if err == nil {
+		err = s.mailer.Send(ctx, Message{
+			To:      account.Email,
+			Subject: "Your Logilo data export is ready",
+			Body: fmt.Sprintf("Hi %s,\n\nThe export of your Logilo data is ready to download at %s/settings/privacy until %s.\n",
+				account.Name, s.appURL, expiresAt.UTC().Format("2 Jan 2006 15:04 MST")),
+		})
+	}
+	if err != nil {
+		log.Printf("Failed to email data export to account %s: %v", req.AccountID, err)
+	}
+	return nil
+}
+
+// runDataErasure has every participant erase the account's data, the account
+// service last so the request can be retried until the others have succeeded,
+// and emails the user what was kept.
+func (s *accountService) runDataErasure(ctx context.Context, req *DataRequest) error {
+	// Looked up first, since the address is about to be erased. A retry after
+	// the account service's own erasure finds no account and sends no email.
+	account, err := s.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil && !errors.Is(err, ErrAccountNotFound) {
+		return err
+	}
+
+	var retained []string
+	for _, p := range s.dataParticipants() {
+		erasure, err := p.Provider.EraseSubjectData(ctx, req.AccountID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		for _, r := range erasure.Retained {
+			retained = append(retained, p.Name+": "+r)
+		}
+	}
+	if err := s.repo.CompleteDataRequest(ctx, req.ID, "", retained, nil); err != nil {
+		return err
+	}
+
+	if account != nil {
+		err := s.mailer.Send(ctx, Message{
+			To:      account.Email,
+			Subject: "Your Logilo account has been erased",
+			Body: fmt.Sprintf("Hi %s,\n\nYour Logilo account and the personal data held about you have been erased. The following records are kept because the law requires it:\n\n- %s\n",
+				account.Name, strings.Join(retained, "\n- ")),
+		})
+		if err != nil {
+			log.Printf("Failed to email erasure of account %s: %v", req.AccountID, err)
+		}
+	}
+	return nil
+}
+
+// dataParticipants returns the services data requests fan out to, ending with
+// the account service itself.
+func (s *accountService) dataParticipants() []datasubject.Participant {
+	participants := make([]datasubject.Participant, 0, len(s.participants)+1)
+	participants = append(participants, s.participants...)
+	return append(participants, datasubject.Participant{Name: accountParticipant, Provider: s})
+}
+
+// ExportSubjectData returns what the account service holds about the account:
+// its login, profile, KYC documents, logo, team, API keys, sessions and login
+// history. Secrets such as password and token hashes are left out.
+func (s *accountService) ExportSubjectData(ctx context.Context, accountID string) ([]datasubject.File, error) {
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	twoFactor, err := s.repo.GetTwoFactor(ctx, accountID)
+	if err != nil && !errors.Is(err, ErrTwoFactorNotEnabled) {
+		return nil, err
+	}
+	var twoFactorEnabledAt *time.Time
+	if twoFactor != nil {
+		twoFactorEnabledAt = twoFactor.EnabledAt
+	}
+
+	profile, err := s.repo.GetProfile(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.repo.ListKYCDocuments(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.repo.ListActiveSessions(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	logins, err := s.repo.ListLoginEvents(ctx, accountID, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := s.repo.ListMemberships(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	invitations, err := s.repo.ListInvitations(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range invitations {
+		invitations[i].Hash = ""
+	}
+	keys, err := s.repo.ListAPIKeys(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.repo.ListDataRequests(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []struct {
+		name string
+		v    any
+	}{
+		{"account.json", map[string]any{
+			"id":                    account.ID,
+			"name":                  account.Name,
+			"email":                 account.Email,
+			"email_verified_at":     account.EmailVerifiedAt,
+			"two_factor_enabled_at": twoFactorEnabledAt,
+			"two_factor_required":   account.TwoFactorRequired,
+			"created_at":            account.CreatedAt,
+			"updated_at":            account.UpdatedAt,
+		}},
+		{"profile.json", profile},
+		{"kyc_documents.json", docs},
+		{"sessions.json", sessions},
+		{"login_history.json", logins},
+		{"memberships.json", memberships},
+		{"team_members.json", members},
+		{"invitations.json", invitations},
+		{"api_keys.json", keys},
+		{"data_requests.json", requests},
+	}
+	files := make([]datasubject.File, 0, len(records)+len(docs)+1)
+	for _, r := range records {
+		f, err := datasubject.JSONFile(r.name, r.v)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	if profile.HasLogo {
+		data, err := s.blobs.Get(ctx, profile.logoKey)
+		if err != nil {
+			return nil, err
+		}
+		name := "logo.png"
+		if profile.LogoContentType == "image/jpeg" {
+			name = "logo.jpg"
+		}
+		files = append(files, datasubject.File{Name: name, Content: data})
+	}
+	for _, doc := range docs {
+		data, err := s.blobs.Get(ctx, doc.blobKey)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, datasubject.File{Name: "kyc_documents/" + doc.ID + "-" + safeFilename(doc.Filename), Content: data})
+	}
+	return files, nil
+}
+
+// EraseSubjectData anonymizes the account so it can never log in again and
+// deletes its personal data and files. The company name, PAN and GSTIN are
+// kept, since they identify the taxpayer on the invoices and ledgers other
+// services must retain.
+func (s *accountService) EraseSubjectData(ctx context.Context, accountID string) (*datasubject.Erasure, error) {
+	keys, err := s.repo.EraseAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+	return &datasubject.Erasure{
+		Erased: []string{
+			"name, email address and password",
+			"sessions, login history and two-factor authentication",
+			"API keys, invitations and memberships of other teams",
+			"contact phone, billing address, brand name and logo",
+			"KYC documents",
+			"data export archives",
+		},
+		Retained: []string{
+			"company name, PAN and GSTIN, which identify the taxpayer on retained invoices and ledgers (CGST Act, section 36; Companies Act, section 128)",
+			"a record of data export and erasure requests, as evidence they were carried out (DPDP Act, section 8)",
+		},
+	}, nil
+}
+
+// safeFilename keeps an uploaded file's name from escaping its folder in an
+// archive.
+func safeFilename(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if name == "" || strings.Trim(name, ".") == "" {
+		return "document"
+	}
+	return name
+}
+
+// DataRequestWorker periodically runs due data export and erasure requests.
+type DataRequestWorker struct {
+	service Service
+}
+
+// NewDataRequestWorker creates a worker that runs data requests through the
+// service.
+func NewDataRequestWorker(service Service) *DataRequestWorker {
+	return &DataRequestWorker{service: service}
+}
+
+// Run processes due data requests every interval until ctx is cancelled.
+func (w *DataRequestWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.service.ProcessDataRequests(ctx); err != nil {
+			log.Printf("Failed to process data requests: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/logilo/payment/invoice"
+	"github.com/google/uuid"
+)
+
+const maxLogoSize = 512 << 10
+
+var (
+	ErrInvalidProfile = errors.New("invalid profile")
+	ErrInvalidLogo    = errors.New("logo must be a PNG or JPEG image of at most 512 KB")
+	ErrLogoNotFound   = errors.New("account has no logo")
+)
+
+var (
+	panPattern        = regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+	phonePattern      = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+	postalCodePattern = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+)
+
+// Address is a postal address in India.
+type Address struct {
+	Line1      string
+	Line2      string
+	City       string
+	State      string
+	PostalCode string
+}
+
+// Profile holds a merchant's business details and KYC review status.
+type Profile struct {
+	AccountID      string
+	CompanyName    string
+	GSTIN          string // Empty for merchants not registered for GST.
+	PAN            string
+	BillingAddress Address
+	ContactPhone   string
+	BrandName      string // Printed on shipping labels.
+
+	HasLogo         bool
+	LogoContentType string
+
+	KYCStatus          string
+	KYCSubmittedAt     *time.Time
+	KYCReviewedAt      *time.Time
+	KYCReviewedBy      string
+	KYCRejectionReason string
+
+	UpdatedAt time.Time
+
+	logoKey string // Blob key of the label logo.
+}
+
+// GetProfile returns the account's profile. Any team member may see it.
+func (s *accountService) GetProfile(ctx context.Context, actorID string, accountID string) (*Profile, error) {
+	if _, err := s.repo.GetMembership(ctx, accountID, actorID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetProfile(ctx, accountID)
+}
+
+// UpdateProfile sets the account's business details. Changing the company
+// name, PAN or GSTIN of an approved account sends its KYC back for review.
+func (s *accountService) UpdateProfile(ctx context.Context, actorID string, accountID string, p Profile) (*Profile, error) {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageProfile); err != nil {
+		return nil, err
+	}
+	p.AccountID = accountID
+	if err := normalizeProfile(&p); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetProfile(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	reviewed := current.CompanyName != p.CompanyName || current.PAN != p.PAN || current.GSTIN != p.GSTIN
+	if err := s.repo.PutProfile(ctx, p, reviewed); err != nil {
+		return nil, err
+	}
+	return s.repo.GetProfile(ctx, accountID)
+}
+
+// UploadLogo replaces the logo printed on the account's shipping labels.
+func (s *accountService) UploadLogo(ctx context.Context, actorID string, accountID string, data []byte) (*Profile, error) {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageProfile); err != nil {
+		return nil, err
+	}
+	contentType := http.DetectContentType(data)
+	if len(data) == 0 || len(data) > maxLogoSize || (contentType != "image/png" && contentType != "image/jpeg") {
+		return nil, ErrInvalidLogo
+	}
+
+	key := fmt.Sprintf("logos/%s/%s", accountID, uuid.NewString())
+	if err := s.blobs.Put(ctx, key, data); err != nil {
+		return nil, err
+	}
+	previous, err := s.repo.SetLogo(ctx, accountID, key, contentType)
+	if err != nil {
+		s.blobs.Delete(ctx, key)
+		return nil, err
+	}
+	if previous != "" {
+		if err := s.blobs.Delete(ctx, previous); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetProfile(ctx, accountID)
+}
+
+// GetBusinessProfile returns the account's profile for other services, such as
+// invoicing, without a team member acting.
+func (s *accountService) GetBusinessProfile(ctx context.Context, accountID string) (*Profile, error) {
+	return s.repo.GetProfile(ctx, accountID)
+}
+
+// GetLogo returns the account's label logo and its content type.
+func (s *accountService) GetLogo(ctx context.Context, accountID string) ([]byte, string, error) {
+	p, err := s.repo.GetProfile(ctx, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !p.HasLogo {
+		return nil, "", ErrLogoNotFound
+	}
+	data, err := s.blobs.Get(ctx, p.logoKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, p.LogoContentType, nil
+}
+
+// normalizeProfile tidies the profile's fields and checks them. The GSTIN, if
+// any, must be registered to the PAN.
+func normalizeProfile(p *Profile) error {
+	p.CompanyName = strings.TrimSpace(p.CompanyName)
+	p.PAN = strings.ToUpper(strings.TrimSpace(p.PAN))
+	p.GSTIN = strings.ToUpper(strings.TrimSpace(p.GSTIN))
+	p.ContactPhone = strings.NewReplacer(" ", "", "-", "").Replace(p.ContactPhone)
+	p.BrandName = strings.TrimSpace(p.BrandName)
+	a := &p.BillingAddress
+	a.Line1, a.Line2 = strings.TrimSpace(a.Line1), strings.TrimSpace(a.Line2)
+	a.City, a.State = strings.TrimSpace(a.City), strings.TrimSpace(a.State)
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+
+	switch {
+	case p.CompanyName == "":
+		return fmt.Errorf("%w: company name is required", ErrInvalidProfile)
+	case !panPattern.MatchString(p.PAN):
+		return fmt.Errorf("%w: PAN must look like ABCDE1234F", ErrInvalidProfile)
+	case p.GSTIN != "" && invoice.ValidateGSTIN(p.GSTIN) != nil:
+		return fmt.Errorf("%w: malformed GSTIN", ErrInvalidProfile)
+	case p.GSTIN != "" && p.GSTIN[2:12] != p.PAN:
+		return fmt.Errorf("%w: GSTIN is not registered to the PAN", ErrInvalidProfile)
+	case !phonePattern.MatchString(p.ContactPhone):
+		return fmt.Errorf("%w: malformed contact phone", ErrInvalidProfile)
+	case a.Line1 == "" || a.City == "" || a.State == "":
+		return fmt.Errorf("%w: billing address needs a street, city and state", ErrInvalidProfile)
+	case !postalCodePattern.MatchString(a.PostalCode):
+		return fmt.Errorf("%w: malformed PIN code", ErrInvalidProfile)
+	}
+	return nil
+}
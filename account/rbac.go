@@ -0,0 +1,90 @@
+package account
+
+import "errors"
+
+// Team roles, from most to least privileged.
+const (
+	RoleOwner   = "owner"   // The merchant; there is exactly one per account.
+	RoleAdmin   = "admin"   // Everything but ownership; manages the team.
+	RoleOps     = "ops"     // Books and manages shipments.
+	RoleFinance = "finance" // Sees and funds the wallet, invoices and remittances.
+	RoleViewer  = "viewer"  // Read-only access to shipments.
+)
+
+// Permission is an action a role may take on an account.
+type Permission string
+
+const (
+	PermissionManageTeam      Permission = "team:manage"
+	PermissionViewShipments   Permission = "shipments:view"
+	PermissionManageShipments Permission = "shipments:manage"
+	PermissionViewBilling     Permission = "billing:view"
+	PermissionManageBilling   Permission = "billing:manage"
+	PermissionManageAPIKeys   Permission = "api_keys:manage"
+	PermissionManageProfile   Permission = "profile:manage"
+)
+
+// Platform permissions are held by Logilo's own staff and services, through
+// staff tokens, and never by a team role or API key.
+const (
+	PermissionInternal       Permission = "platform:internal" // Calls from one service to another.
+	PermissionOperateBilling Permission = "platform:billing"  // Remittance batches, courier statements, credit lines and invoicing runs.
+	PermissionReviewKYC      Permission = "platform:kyc"      // Merchants' KYC documents and their approval.
+	PermissionAdminWallets   Permission = "platform:wallets"  // Wallet freezes, manual adjustments and the audit log.
+)
+
+var platformPermissions = []Permission{PermissionInternal, PermissionOperateBilling, PermissionReviewKYC, PermissionAdminWallets}
+
+var (
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrNotTeamMember    = errors.New("not a member of this account")
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner:   {PermissionManageTeam, PermissionViewShipments, PermissionManageShipments, PermissionViewBilling, PermissionManageBilling, PermissionManageAPIKeys, PermissionManageProfile},
+	RoleAdmin:   {PermissionManageTeam, PermissionViewShipments, PermissionManageShipments, PermissionViewBilling, PermissionManageBilling, PermissionManageAPIKeys, PermissionManageProfile},
+	RoleOps:     {PermissionViewShipments, PermissionManageShipments},
+	RoleFinance: {PermissionViewShipments, PermissionViewBilling, PermissionManageBilling},
+	RoleViewer:  {PermissionViewShipments},
+}
+
+// ValidRole reports whether role is one of the team roles.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether role grants permission.
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PlatformPermission reports whether permission is one of the platform
+// permissions.
+func PlatformPermission(permission Permission) bool {
+	for _, p := range platformPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// canAssign reports whether a member with role actor may give a member the
+// role, or change or remove a member who has it. Only owners manage admins,
+// and the owner role is never assigned.
+func canAssign(actor string, role string) bool {
+	switch actor {
+	case RoleOwner:
+		return role != RoleOwner
+	case RoleAdmin:
+		return role == RoleOps || role == RoleFinance || role == RoleViewer
+	}
+	return false
+}
@@ -5,10 +5,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 	"golang.org/x/crypto/bcrypt"  // For password hashing and validation
 
-	_ "github.com/lib/pq" // Import PostgreSQL driver
+	"github.com/google/uuid"
+	"github.com/lib/pq" // PostgreSQL driver and array support
 )
 
 // Repository defines the interface for interacting with the accounts database.
@@ -18,6 +21,75 @@ type Repository interface {
 	GetAccountByEmailAndPassword(ctx context.Context, email, password string) (*Account, error) // Retrieve an account by email and password
 	ListAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error) // List accounts with pagination
 	Ping() error                                  // Check database connection health
+
+	CreateSession(ctx context.Context, session Session, refreshID string) error                                                        // Start a session holding refreshID
+	RotateSession(ctx context.Context, sessionID, presentedID, nextID string, device Device, expiresAt time.Time) error               // Replace the session's refresh token
+	ListActiveSessions(ctx context.Context, accountID string) ([]Session, error)                                                       // List unrevoked, unexpired sessions
+	RevokeSession(ctx context.Context, accountID, sessionID, reason string) error                                                     // Revoke one session
+	RevokeAllSessions(ctx context.Context, accountID, exceptSessionID, reason string) (int, error)                                    // Revoke all sessions but one
+	LoginFailures(ctx context.Context, key string, window time.Duration) (int, time.Time, error)                                      // Count recent failed logins and return the latest
+	RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int, error)                                            // Count a failed login and return the new count
+	RetractLoginFailure(ctx context.Context, key string) error                                                                         // Uncount a login that turned out to succeed
+	ClearLoginFailures(ctx context.Context, key string) error                                                                          // Forget failed logins
+	AddLoginEvent(ctx context.Context, event LoginEvent) error                                                                         // Add a login attempt to the history
+	ListLoginEvents(ctx context.Context, accountID string, limit int) ([]LoginEvent, error)                                            // List recent login attempts
+	LoginSourceSeen(ctx context.Context, accountID, userAgent, network string) (bool, bool, bool, error)                               // Whether the device, the network and any login have been seen
+
+	GetAccountByID(ctx context.Context, id string) (*Account, error)                                                                   // Retrieve an account by id
+	GetAccountByEmail(ctx context.Context, email string) (*Account, error)                                                             // Retrieve an account by email
+	UpdatePassword(ctx context.Context, accountID, password string) error                                                              // Hash and store a new password
+	UpdateAccount(ctx context.Context, accountID, name, email, password, sessionID string) error                                       // Change the name, email and password at once, unverifying a new email
+	DeleteAccount(ctx context.Context, accountID string) error                                                                         // Deactivate an account and revoke its access
+	MarkEmailVerified(ctx context.Context, accountID, email string) error                                                              // Mark the account's current email verified
+	CreateOneTimeToken(ctx context.Context, token OneTimeToken, limit int, window time.Duration) error                                 // Store a token, rate limited per account and purpose
+	ConsumeOneTimeToken(ctx context.Context, purpose, hash string) (*OneTimeToken, error)                                              // Use up an unexpired token
+
+	GetTwoFactor(ctx context.Context, accountID string) (*TwoFactor, error)                                                            // Retrieve the TOTP enrollment, pending or enabled
+	PutPendingTwoFactor(ctx context.Context, accountID string, sealedSecret []byte) error                                              // Store a secret awaiting confirmation
+	EnableTwoFactor(ctx context.Context, accountID string, step int64, recoveryHashes []string) error                                  // Confirm the pending secret
+	DeleteTwoFactor(ctx context.Context, accountID string) error                                                                       // Remove the enrollment and recovery codes
+	ReplaceRecoveryCodes(ctx context.Context, accountID string, recoveryHashes []string) error                                         // Swap in new recovery codes
+	AdvanceTwoFactorStep(ctx context.Context, accountID string, step int64) error                                                      // Record a used TOTP step
+	UseRecoveryCode(ctx context.Context, accountID, hash string) error                                                                 // Use up a recovery code
+	CreateLoginChallenge(ctx context.Context, hash, accountID string, expiresAt time.Time) error                                       // Store a second-factor challenge
+	AttemptLoginChallenge(ctx context.Context, hash string, maxAttempts int) (string, error)                                           // Count an attempt and return the account id
+	CompleteLoginChallenge(ctx context.Context, hash string) error                                                                     // Use up a challenge
+	TwoFactorRequiredByTeams(ctx context.Context, userID string) (bool, error)                                                         // Whether any of the user's teams requires two-factor
+
+	GetMembership(ctx context.Context, accountID, userID string) (*Membership, error)                                                  // Retrieve a user's role in a team
+	ListMembers(ctx context.Context, accountID string) ([]Member, error)                                                               // List a team
+	ListMemberships(ctx context.Context, userID string) ([]Membership, error)                                                          // List a user's teams
+	SetMemberRole(ctx context.Context, accountID, userID, role string) error                                                           // Change a member's role
+	RemoveMember(ctx context.Context, accountID, userID string) error                                                                  // Remove a member
+	CreateInvitation(ctx context.Context, invitation Invitation) (*Invitation, error)                                                  // Store an invitation
+	ListInvitations(ctx context.Context, accountID string) ([]Invitation, error)                                                       // List pending invitations
+	RevokeInvitation(ctx context.Context, accountID, invitationID string) error                                                        // Withdraw an invitation
+	AcceptInvitation(ctx context.Context, hash, userID, email string) (*Membership, error)                                             // Use up an invitation and join its team
+	ReissueSession(ctx context.Context, sessionID, userID, nextID string, expiresAt time.Time) error                                   // Replace an active session's refresh token
+	SetTwoFactorRequired(ctx context.Context, accountID string, required bool) error                                                   // Set the team's two-factor requirement
+
+	CreateAPIKey(ctx context.Context, key APIKey, hash string) (*APIKey, error)                                                        // Store an API key
+	ListAPIKeys(ctx context.Context, accountID string) ([]APIKey, error)                                                               // List active API keys
+	RevokeAPIKey(ctx context.Context, accountID, keyID string) error                                                                   // Revoke an API key
+	UseAPIKey(ctx context.Context, hash string) (*APIKey, error)                                                                       // Look up an active API key and record its use
+
+	GetProfile(ctx context.Context, accountID string) (*Profile, error)                                                                // Retrieve the merchant profile, empty if unset
+	PutProfile(ctx context.Context, profile Profile, resetKYC bool) error                                                              // Store business details, optionally sending approved KYC back for review
+	SetLogo(ctx context.Context, accountID, key, contentType string) (string, error)                                                   // Set the logo and return the previous blob key
+	AddKYCDocument(ctx context.Context, doc KYCDocument) (*KYCDocument, error)                                                         // Store a document and submit KYC for review
+	ListKYCDocuments(ctx context.Context, accountID string) ([]KYCDocument, error)                                                     // List an account's KYC documents
+	GetKYCDocument(ctx context.Context, accountID, documentID string) (*KYCDocument, error)                                            // Retrieve a KYC document
+	ListProfilesByKYCStatus(ctx context.Context, status string) ([]Profile, error)                                                     // List profiles in a KYC status, oldest submission first
+	ReviewKYC(ctx context.Context, accountID, reviewer, status, reason string) (*Profile, error)                                       // Record the review of pending KYC
+
+	CreateDataRequest(ctx context.Context, req DataRequest) (*DataRequest, error)                                                      // Queue a data request, one open request per kind
+	ListDataRequests(ctx context.Context, accountID string) ([]DataRequest, error)                                                     // List an account's data requests
+	GetDataRequest(ctx context.Context, accountID, requestID string) (*DataRequest, error)                                             // Retrieve a data request
+	ClaimDataRequest(ctx context.Context, staleBefore time.Time) (*DataRequest, error)                                                 // Start the next due request, or one left running since staleBefore
+	CompleteDataRequest(ctx context.Context, requestID, archiveKey string, retained []string, expiresAt *time.Time) error              // Record a finished request
+	FailDataRequest(ctx context.Context, requestID, message string, retryAt *time.Time) error                                          // Record a failed attempt, retrying at retryAt unless nil
+	ExpireDataExports(ctx context.Context, now time.Time) ([]string, error)                                                            // Expire old exports and return their archive keys
+	EraseAccount(ctx context.Context, accountID string) ([]string, error)                                                              // Anonymize an account and return the blob keys of its files
 }
 
 // postgresRepository is the PostgreSQL implementation of the Repository interface.
@@ -65,9 +137,15 @@ func (r *postgresRepository) PutAccount(ctx context.Context, account Account) er
 	}
 
 	// Prepare the SQL query to insert a new account
+	// Every account's own user owns its team.
 	query := `
-		INSERT INTO accounts (id, name, email, password, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, $6)
+		WITH account AS (
+			INSERT INTO accounts (id, name, email, password, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		)
+		INSERT INTO team_members (account_id, user_id, role)
+		SELECT id, id, 'owner' FROM account
 	`
 
 	_, err = r.db.ExecContext(ctx, query, account.ID, account.Name, account.Email, string(hashedPassword), account.CreatedAt, account.UpdatedAt)
@@ -78,26 +156,29 @@ func (r *postgresRepository) PutAccount(ctx context.Context, account Account) er
 }
 
 // GetAccountByEmailAndPassword retrieves an account by its email and validates its password.
+// An unknown email and a wrong password both return ErrInvalidCredentials.
 func (r *postgresRepository) GetAccountByEmailAndPassword(ctx context.Context, email, password string) (*Account, error) {
 	// Check if the email exists in the database
 	query := `
-		SELECT id, name, email, password, created_at, updated_at 
+		SELECT id, name, email, password, email_verified_at, require_two_factor, created_at, updated_at 
 		FROM accounts 
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`
 	row := r.db.QueryRowContext(ctx, query, email)
 
 	var account Account
-	if err := row.Scan(&account.ID, &account.Name, &account.Email, &account.Password, &account.CreatedAt, &account.UpdatedAt); err != nil {
+	if err := row.Scan(&account.ID, &account.Name, &account.Email, &account.Password, &account.EmailVerifiedAt, &account.TwoFactorRequired, &account.CreatedAt, &account.UpdatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("email not found: %w", err)
+			// Compare against a dummy hash so unknown emails take as long as wrong passwords
+			bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+			return nil, ErrInvalidCredentials
 		}
 		return nil, fmt.Errorf("failed to query account: %w", err)
 	}
 
 	// Validate the password
 	if err := bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(password)); err != nil {
-		return nil, errors.New("invalid password")
+		return nil, ErrInvalidCredentials
 	}
 
 	return &account, nil
@@ -131,3 +212,1543 @@ func (r *postgresRepository) ListAccounts(ctx context.Context, skip uint64, take
 	}
 	return accounts, nil
 }
+
+// CreateSession records a new session whose valid refresh token is refreshID.
+func (r *postgresRepository) CreateSession(ctx context.Context, session Session, refreshID string) error {
+	query := `
+		INSERT INTO sessions (id, account_id, refresh_token_id, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.ExecContext(ctx, query, session.ID, session.UserID, refreshID, session.Device.UserAgent, session.Device.IP, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+	return nil
+}
+
+// RotateSession replaces the session's refresh token presentedID with nextID.
+// If presentedID is a refresh token the session has already rotated away from,
+// the session is revoked and ErrRefreshTokenReused is returned.
+func (r *postgresRepository) RotateSession(ctx context.Context, sessionID, presentedID, nextID string, device Device, expiresAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET refresh_token_id = $3, user_agent = $4, ip = $5, expires_at = $6, last_used_at = NOW()
+		WHERE id = $1 AND refresh_token_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+	`, sessionID, presentedID, nextID, device.UserAgent, device.IP, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 1 {
+		return nil
+	}
+
+	// The session is gone, revoked, expired, or holds a newer refresh token.
+	// Only the last case is reuse, and it kills the session.
+	res, err = r.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE id = $1 AND refresh_token_id <> $2 AND revoked_at IS NULL
+	`, sessionID, presentedID, RevokedTokenReuse)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 1 {
+		return ErrRefreshTokenReused
+	}
+	return ErrSessionRevoked
+}
+
+// ListActiveSessions retrieves the account's unrevoked, unexpired sessions.
+func (r *postgresRepository) ListActiveSessions(ctx context.Context, accountID string) ([]Session, error) {
+	query := `
+		SELECT id, account_id, user_agent, ip, created_at, last_used_at, expires_at
+		FROM sessions
+		WHERE account_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var s Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Device.UserAgent, &s.Device.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the account's active sessions.
+func (r *postgresRepository) RevokeSession(ctx context.Context, accountID, sessionID, reason string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE id = $1 AND account_id = $2 AND revoked_at IS NULL
+	`, sessionID, accountID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAllSessions revokes the account's active sessions other than
+// exceptSessionID and returns how many it revoked.
+func (r *postgresRepository) RevokeAllSessions(ctx context.Context, accountID, exceptSessionID, reason string) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE account_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`, accountID, exceptSessionID, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// GetAccountByID retrieves an active account by its id.
+func (r *postgresRepository) GetAccountByID(ctx context.Context, id string) (*Account, error) {
+	return r.getAccount(ctx, "id", id)
+}
+
+// GetAccountByEmail retrieves an active account by its email.
+func (r *postgresRepository) GetAccountByEmail(ctx context.Context, email string) (*Account, error) {
+	return r.getAccount(ctx, "email", email)
+}
+
+func (r *postgresRepository) getAccount(ctx context.Context, column, value string) (*Account, error) {
+	query := `
+		SELECT id, name, email, email_verified_at, require_two_factor, created_at, updated_at
+		FROM accounts
+		WHERE ` + column + ` = $1 AND deleted_at IS NULL
+	`
+	var account Account
+	err := r.db.QueryRowContext(ctx, query, value).Scan(&account.ID, &account.Name, &account.Email, &account.EmailVerifiedAt, &account.TwoFactorRequired, &account.CreatedAt, &account.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account: %w", err)
+	}
+	return &account, nil
+}
+
+// UpdatePassword hashes and stores a new password for the account.
+func (r *postgresRepository) UpdatePassword(ctx context.Context, accountID, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, `UPDATE accounts SET password = $2, updated_at = NOW() WHERE id = $1`, accountID, string(hashedPassword))
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
+// UpdateAccount sets the account's name and email. A changed email is marked
+// unverified. It returns ErrEmailInUse if another account has the email. A
+// non-empty password is hashed and stored too, and revokes every session but
+// sessionID, all in the same transaction.
+func (r *postgresRepository) UpdateAccount(ctx context.Context, accountID, name, email, password, sessionID string) (err error) {
+	var hashedPassword []byte
+	if password != "" {
+		if hashedPassword, err = bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost); err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET name = $2, email = $3, updated_at = NOW(),
+			email_verified_at = CASE WHEN email = $3 THEN email_verified_at END
+		WHERE id = $1 AND deleted_at IS NULL
+	`, accountID, name, email)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrEmailInUse
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAccountNotFound
+	}
+	if password == "" {
+		return nil
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE accounts SET password = $2 WHERE id = $1`, accountID, string(hashedPassword)); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE account_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`, accountID, sessionID, RevokedPasswordChanged)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// DeleteAccount marks the account deleted, revokes its sessions, API keys and
+// pending invitations, and removes it from other teams.
+func (r *postgresRepository) DeleteAccount(ctx context.Context, accountID string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAccountNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2
+		WHERE account_id = $1 AND revoked_at IS NULL
+	`, accountID, RevokedAccountDeleted)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	statements := []string{
+		`UPDATE api_keys SET revoked_at = NOW() WHERE account_id = $1 AND revoked_at IS NULL`,
+		`UPDATE invitations SET revoked_at = NOW() WHERE account_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`,
+		`DELETE FROM team_members WHERE user_id = $1 AND role <> 'owner'`,
+	}
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt, accountID); err != nil {
+			return fmt.Errorf("failed to revoke access of deleted account: %w", err)
+		}
+	}
+	return nil
+}
+
+// MarkEmailVerified marks the account's email verified if it is still email.
+func (r *postgresRepository) MarkEmailVerified(ctx context.Context, accountID, email string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE accounts SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $1 AND email = $2
+	`, accountID, email)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// The account changed its address after the token was sent.
+		return ErrInvalidOneTimeToken
+	}
+	return nil
+}
+
+// CreateOneTimeToken stores a token and supersedes the account's earlier unused
+// tokens of the same purpose. It returns ErrTooManyRequests if limit tokens of
+// the purpose were already created for the account within window.
+func (r *postgresRepository) CreateOneTimeToken(ctx context.Context, token OneTimeToken, limit int, window time.Duration) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Lock the account so concurrent requests cannot both pass the limit.
+	if _, err = tx.ExecContext(ctx, `SELECT 1 FROM accounts WHERE id = $1 FOR UPDATE`, token.AccountID); err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	var recent int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM one_time_tokens
+		WHERE account_id = $1 AND purpose = $2 AND created_at > NOW() - make_interval(secs => $3)
+	`, token.AccountID, token.Purpose, window.Seconds()).Scan(&recent)
+	if err != nil {
+		return fmt.Errorf("failed to count tokens: %w", err)
+	}
+	if recent >= limit {
+		return ErrTooManyRequests
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE one_time_tokens SET used_at = NOW()
+		WHERE account_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, token.AccountID, token.Purpose)
+	if err != nil {
+		return fmt.Errorf("failed to supersede tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO one_time_tokens (token_hash, account_id, email, purpose, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, token.Hash, token.AccountID, token.Email, token.Purpose, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert token: %w", err)
+	}
+	return nil
+}
+
+// ConsumeOneTimeToken marks an unused, unexpired token used and returns it.
+func (r *postgresRepository) ConsumeOneTimeToken(ctx context.Context, purpose, hash string) (*OneTimeToken, error) {
+	token := OneTimeToken{Purpose: purpose, Hash: hash}
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE one_time_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING account_id, email, expires_at
+	`, hash, purpose).Scan(&token.AccountID, &token.Email, &token.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidOneTimeToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+	return &token, nil
+}
+
+// GetTwoFactor retrieves the account's TOTP enrollment. It returns
+// ErrTwoFactorNotEnabled if the account has none.
+func (r *postgresRepository) GetTwoFactor(ctx context.Context, accountID string) (*TwoFactor, error) {
+	tf := TwoFactor{AccountID: accountID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT sealed_secret, enabled_at, last_step FROM account_two_factor WHERE account_id = $1
+	`, accountID).Scan(&tf.SealedSecret, &tf.EnabledAt, &tf.LastStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query two-factor enrollment: %w", err)
+	}
+	return &tf, nil
+}
+
+// PutPendingTwoFactor stores a TOTP secret awaiting confirmation, replacing an
+// earlier unconfirmed one.
+func (r *postgresRepository) PutPendingTwoFactor(ctx context.Context, accountID string, sealedSecret []byte) error {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO account_two_factor (account_id, sealed_secret)
+		VALUES ($1, $2)
+		ON CONFLICT (account_id) DO UPDATE
+		SET sealed_secret = EXCLUDED.sealed_secret, last_step = 0, created_at = NOW()
+		WHERE account_two_factor.enabled_at IS NULL
+	`, accountID, sealedSecret)
+	if err != nil {
+		return fmt.Errorf("failed to store two-factor secret: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// EnableTwoFactor confirms the pending secret with the step of the code that
+// proved it and stores the account's recovery codes.
+func (r *postgresRepository) EnableTwoFactor(ctx context.Context, accountID string, step int64, recoveryHashes []string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE account_two_factor SET enabled_at = NOW(), last_step = $2
+		WHERE account_id = $1 AND enabled_at IS NULL AND last_step < $2
+	`, accountID, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTwoFactorNotPending
+	}
+	return r.replaceRecoveryCodes(ctx, tx, accountID, recoveryHashes)
+}
+
+// DeleteTwoFactor removes the account's TOTP enrollment and recovery codes.
+func (r *postgresRepository) DeleteTwoFactor(ctx context.Context, accountID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM account_two_factor WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete two-factor enrollment: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes discards the account's recovery codes and stores new ones.
+func (r *postgresRepository) ReplaceRecoveryCodes(ctx context.Context, accountID string, recoveryHashes []string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return r.replaceRecoveryCodes(ctx, tx, accountID, recoveryHashes)
+}
+
+func (r *postgresRepository) replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, accountID string, recoveryHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range recoveryHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO recovery_codes (account_id, code_hash) VALUES ($1, $2)`, accountID, hash); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// AdvanceTwoFactorStep records step as the last used TOTP step. It returns
+// ErrInvalidTwoFactorCode if a code from that step or a later one was already
+// used, so each code works once.
+func (r *postgresRepository) AdvanceTwoFactorStep(ctx context.Context, accountID string, step int64) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE account_two_factor SET last_step = $2
+		WHERE account_id = $1 AND last_step < $2
+	`, accountID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record two-factor step: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// UseRecoveryCode marks one of the account's unused recovery codes used.
+func (r *postgresRepository) UseRecoveryCode(ctx context.Context, accountID, hash string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE recovery_codes SET used_at = NOW()
+		WHERE account_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, accountID, hash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// CreateLoginChallenge stores a second-factor challenge for the account.
+func (r *postgresRepository) CreateLoginChallenge(ctx context.Context, hash, accountID string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_challenges (token_hash, account_id, expires_at) VALUES ($1, $2, $3)
+	`, hash, accountID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert login challenge: %w", err)
+	}
+	return nil
+}
+
+// AttemptLoginChallenge uses up one attempt of an open challenge and returns
+// its account id. Challenges that are used, expired or out of attempts return
+// ErrInvalidChallenge.
+func (r *postgresRepository) AttemptLoginChallenge(ctx context.Context, hash string, maxAttempts int) (string, error) {
+	var accountID string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE login_challenges SET attempts = attempts + 1
+		WHERE token_hash = $1 AND completed_at IS NULL AND expires_at > NOW() AND attempts < $2
+		RETURNING account_id
+	`, hash, maxAttempts).Scan(&accountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrInvalidChallenge
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to attempt login challenge: %w", err)
+	}
+	return accountID, nil
+}
+
+// CompleteLoginChallenge marks a challenge answered so it cannot be used again.
+func (r *postgresRepository) CompleteLoginChallenge(ctx context.Context, hash string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE login_challenges SET completed_at = NOW()
+		WHERE token_hash = $1 AND completed_at IS NULL
+	`, hash)
+	if err != nil {
+		return fmt.Errorf("failed to complete login challenge: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrInvalidChallenge
+	}
+	return nil
+}
+
+// TwoFactorRequiredByTeams reports whether any team the user belongs to
+// requires two-factor authentication.
+func (r *postgresRepository) TwoFactorRequiredByTeams(ctx context.Context, userID string) (bool, error) {
+	var required bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM team_members m JOIN accounts a ON a.id = m.account_id
+			WHERE m.user_id = $1 AND a.require_two_factor
+		)
+	`, userID).Scan(&required)
+	if err != nil {
+		return false, fmt.Errorf("failed to query two-factor requirement: %w", err)
+	}
+	return required, nil
+}
+
+// GetMembership retrieves the user's role in the account's team. It returns
+// ErrNotTeamMember if the user is not on the team.
+func (r *postgresRepository) GetMembership(ctx context.Context, accountID, userID string) (*Membership, error) {
+	m := Membership{AccountID: accountID, UserID: userID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT a.name, m.role, m.created_at
+		FROM team_members m JOIN accounts a ON a.id = m.account_id
+		WHERE m.account_id = $1 AND m.user_id = $2 AND a.deleted_at IS NULL
+	`, accountID, userID).Scan(&m.AccountName, &m.Role, &m.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotTeamMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query membership: %w", err)
+	}
+	return &m, nil
+}
+
+// ListMembers retrieves the account's team, owner first.
+func (r *postgresRepository) ListMembers(ctx context.Context, accountID string) ([]Member, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.user_id, u.name, u.email, m.role, m.created_at
+		FROM team_members m JOIN accounts u ON u.id = m.user_id
+		WHERE m.account_id = $1 AND u.deleted_at IS NULL
+		ORDER BY m.role = 'owner' DESC, m.created_at
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []Member{}
+	for rows.Next() {
+		m := Member{Membership: Membership{AccountID: accountID}}
+		if err := rows.Scan(&m.UserID, &m.Name, &m.Email, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return members, nil
+}
+
+// ListMemberships retrieves the teams the user belongs to, their own first.
+func (r *postgresRepository) ListMemberships(ctx context.Context, userID string) ([]Membership, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.account_id, a.name, m.role, m.created_at
+		FROM team_members m JOIN accounts a ON a.id = m.account_id
+		WHERE m.user_id = $1 AND a.deleted_at IS NULL
+		ORDER BY m.account_id = m.user_id DESC, a.name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memberships: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := []Membership{}
+	for rows.Next() {
+		m := Membership{UserID: userID}
+		if err := rows.Scan(&m.AccountID, &m.AccountName, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan membership: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return memberships, nil
+}
+
+// SetMemberRole changes the role of a member other than the owner.
+func (r *postgresRepository) SetMemberRole(ctx context.Context, accountID, userID, role string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE team_members SET role = $3
+		WHERE account_id = $1 AND user_id = $2 AND role <> 'owner'
+	`, accountID, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotTeamMember
+	}
+	return nil
+}
+
+// RemoveMember removes a member other than the owner from the team.
+func (r *postgresRepository) RemoveMember(ctx context.Context, accountID, userID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM team_members WHERE account_id = $1 AND user_id = $2 AND role <> 'owner'
+	`, accountID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotTeamMember
+	}
+	return nil
+}
+
+// CreateInvitation stores an invitation and returns it with its id. A new
+// invitation to the same address replaces a pending one.
+func (r *postgresRepository) CreateInvitation(ctx context.Context, invitation Invitation) (*Invitation, error) {
+	err := r.db.QueryRowContext(ctx, `
+		WITH superseded AS (
+			UPDATE invitations SET revoked_at = NOW()
+			WHERE account_id = $1 AND lower(email) = lower($2) AND accepted_at IS NULL AND revoked_at IS NULL
+		)
+		INSERT INTO invitations (id, account_id, email, role, invited_by, token_hash, expires_at)
+		VALUES ($7, $1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, invitation.AccountID, invitation.Email, invitation.Role, invitation.InvitedBy, invitation.Hash, invitation.ExpiresAt, uuid.NewString()).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+// ListInvitations retrieves the account's pending invitations, newest first.
+func (r *postgresRepository) ListInvitations(ctx context.Context, accountID string) ([]Invitation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, email, role, invited_by, created_at, expires_at
+		FROM invitations
+		WHERE account_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []Invitation{}
+	for rows.Next() {
+		inv := Invitation{AccountID: accountID}
+		if err := rows.Scan(&inv.ID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.CreatedAt, &inv.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation withdraws one of the account's pending invitations.
+func (r *postgresRepository) RevokeInvitation(ctx context.Context, accountID, invitationID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE invitations SET revoked_at = NOW()
+		WHERE id = $1 AND account_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, invitationID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation uses up a pending invitation sent to email and adds the
+// user to its team with the invited role. Existing members take the invited
+// role unless they own the team.
+func (r *postgresRepository) AcceptInvitation(ctx context.Context, hash, userID, email string) (m *Membership, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	m = &Membership{UserID: userID}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE invitations SET accepted_at = NOW()
+		WHERE token_hash = $1 AND lower(email) = lower($2)
+			AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING account_id, role
+	`, hash, email).Scan(&m.AccountID, &m.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO team_members (account_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_id, user_id) DO UPDATE
+		SET role = CASE WHEN team_members.role = 'owner' THEN team_members.role ELSE EXCLUDED.role END
+		RETURNING role, created_at
+	`, m.AccountID, userID, m.Role).Scan(&m.Role, &m.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	if err = tx.QueryRowContext(ctx, `SELECT name FROM accounts WHERE id = $1`, m.AccountID).Scan(&m.AccountName); err != nil {
+		return nil, fmt.Errorf("failed to query account: %w", err)
+	}
+	return m, nil
+}
+
+// ReissueSession replaces the refresh token of one of the user's active
+// sessions without a rotation check, for tokens reissued by the account
+// service itself.
+func (r *postgresRepository) ReissueSession(ctx context.Context, sessionID, userID, nextID string, expiresAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE sessions SET refresh_token_id = $3, expires_at = $4, last_used_at = NOW()
+		WHERE id = $1 AND account_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+	`, sessionID, userID, nextID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to reissue session: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// SetTwoFactorRequired sets whether the account's team must use two-factor authentication.
+func (r *postgresRepository) SetTwoFactorRequired(ctx context.Context, accountID string, required bool) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE accounts SET require_two_factor = $2, updated_at = NOW() WHERE id = $1
+	`, accountID, required)
+	if err != nil {
+		return fmt.Errorf("failed to update two-factor requirement: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
+// CreateAPIKey stores an API key by the hash of its secret and returns it with
+// its id.
+func (r *postgresRepository) CreateAPIKey(ctx context.Context, key APIKey, hash string) (*APIKey, error) {
+	key.ID = uuid.NewString()
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (id, account_id, name, prefix, key_hash, scopes, rate_limit, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`, key.ID, key.AccountID, key.Name, key.Prefix, hash, pq.Array(scopeStrings(key.Scopes)), key.RateLimit, key.CreatedBy).Scan(&key.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert api key: %w", err)
+	}
+	return &key, nil
+}
+
+// ListAPIKeys retrieves the account's active API keys, newest first.
+func (r *postgresRepository) ListAPIKeys(ctx context.Context, accountID string) ([]APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE account_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes one of the account's active API keys.
+func (r *postgresRepository) RevokeAPIKey(ctx context.Context, accountID, keyID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND account_id = $2 AND revoked_at IS NULL
+	`, keyID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// UseAPIKey retrieves the active API key with the hash and sets its last-used
+// time. It returns ErrInvalidAPIKey if there is none.
+func (r *postgresRepository) UseAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns, hash)
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to use api key: %w", err)
+	}
+	return key, nil
+}
+
+const apiKeyColumns = `id, account_id, name, prefix, scopes, rate_limit, created_by, created_at, last_used_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*APIKey, error) {
+	var key APIKey
+	var scopes []string
+	err := row.Scan(&key.ID, &key.AccountID, &key.Name, &key.Prefix, pq.Array(&scopes), &key.RateLimit, &key.CreatedBy, &key.CreatedAt, &key.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, Permission(scope))
+	}
+	return &key, nil
+}
+
+func scopeStrings(scopes []Permission) []string {
+	out := make([]string, len(scopes))
+	for i, scope := range scopes {
+		out[i] = string(scope)
+	}
+	return out
+}
+
+// GetProfile retrieves the account's merchant profile. An account that has not
+// set one up gets an empty profile with KYC not submitted.
+func (r *postgresRepository) GetProfile(ctx context.Context, accountID string) (*Profile, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+profileColumns+`
+		FROM merchant_profiles
+		WHERE account_id = $1
+	`, accountID)
+	p, err := scanProfile(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Profile{AccountID: accountID, KYCStatus: KYCStatusNotSubmitted}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profile: %w", err)
+	}
+	return p, nil
+}
+
+// PutProfile stores the profile's business details. If resetKYC is set, an
+// approved KYC goes back to pending review.
+func (r *postgresRepository) PutProfile(ctx context.Context, p Profile, resetKYC bool) error {
+	a := p.BillingAddress
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO merchant_profiles (account_id, company_name, gstin, pan, address_line1, address_line2, city, state, postal_code, contact_phone, brand_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (account_id) DO UPDATE
+		SET company_name = EXCLUDED.company_name, gstin = EXCLUDED.gstin, pan = EXCLUDED.pan,
+			address_line1 = EXCLUDED.address_line1, address_line2 = EXCLUDED.address_line2,
+			city = EXCLUDED.city, state = EXCLUDED.state, postal_code = EXCLUDED.postal_code,
+			contact_phone = EXCLUDED.contact_phone, brand_name = EXCLUDED.brand_name,
+			kyc_status = CASE WHEN $12 AND merchant_profiles.kyc_status = 'approved' THEN 'pending' ELSE merchant_profiles.kyc_status END,
+			kyc_submitted_at = CASE WHEN $12 AND merchant_profiles.kyc_status = 'approved' THEN NOW() ELSE merchant_profiles.kyc_submitted_at END,
+			updated_at = NOW()
+	`, p.AccountID, p.CompanyName, p.GSTIN, p.PAN, a.Line1, a.Line2, a.City, a.State, a.PostalCode, p.ContactPhone, p.BrandName, resetKYC)
+	if err != nil {
+		return fmt.Errorf("failed to upsert profile: %w", err)
+	}
+	return nil
+}
+
+// SetLogo sets the blob key of the account's logo and returns the key it
+// replaces, if any.
+func (r *postgresRepository) SetLogo(ctx context.Context, accountID, key, contentType string) (previous string, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = tx.QueryRowContext(ctx, `SELECT logo_key FROM merchant_profiles WHERE account_id = $1 FOR UPDATE`, accountID).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to query logo: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO merchant_profiles (account_id, logo_key, logo_content_type)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_id) DO UPDATE
+		SET logo_key = EXCLUDED.logo_key, logo_content_type = EXCLUDED.logo_content_type, updated_at = NOW()
+	`, accountID, key, contentType)
+	if err != nil {
+		return "", fmt.Errorf("failed to set logo: %w", err)
+	}
+	return previous, nil
+}
+
+// AddKYCDocument stores the document and, unless the account's KYC is already
+// approved, submits it for review.
+func (r *postgresRepository) AddKYCDocument(ctx context.Context, doc KYCDocument) (_ *KYCDocument, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO kyc_documents (id, account_id, type, filename, content_type, size, blob_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING uploaded_at
+	`, doc.ID, doc.AccountID, doc.Type, doc.Filename, doc.ContentType, doc.Size, doc.blobKey, doc.UploadedBy).Scan(&doc.UploadedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert kyc document: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO merchant_profiles (account_id, kyc_status, kyc_submitted_at)
+		VALUES ($1, 'pending', NOW())
+		ON CONFLICT (account_id) DO UPDATE
+		SET kyc_status = 'pending', kyc_submitted_at = NOW(), kyc_rejection_reason = '', updated_at = NOW()
+		WHERE merchant_profiles.kyc_status <> 'approved'
+	`, doc.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit kyc: %w", err)
+	}
+	return &doc, nil
+}
+
+// ListKYCDocuments retrieves the account's KYC documents, newest first.
+func (r *postgresRepository) ListKYCDocuments(ctx context.Context, accountID string) ([]KYCDocument, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+kycDocumentColumns+`
+		FROM kyc_documents
+		WHERE account_id = $1
+		ORDER BY uploaded_at DESC
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query kyc documents: %w", err)
+	}
+	defer rows.Close()
+
+	docs := []KYCDocument{}
+	for rows.Next() {
+		doc, err := scanKYCDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan kyc document: %w", err)
+		}
+		docs = append(docs, *doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return docs, nil
+}
+
+// GetKYCDocument retrieves one of the account's KYC documents.
+func (r *postgresRepository) GetKYCDocument(ctx context.Context, accountID, documentID string) (*KYCDocument, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+kycDocumentColumns+`
+		FROM kyc_documents
+		WHERE id = $1 AND account_id = $2
+	`, documentID, accountID)
+	doc, err := scanKYCDocument(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrKYCDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query kyc document: %w", err)
+	}
+	return doc, nil
+}
+
+// ListProfilesByKYCStatus retrieves the profiles whose KYC is in the status,
+// oldest submission first.
+func (r *postgresRepository) ListProfilesByKYCStatus(ctx context.Context, status string) ([]Profile, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+profileColumns+`
+		FROM merchant_profiles
+		WHERE kyc_status = $1
+		ORDER BY kyc_submitted_at ASC
+	`, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []Profile{}
+	for rows.Next() {
+		p, err := scanProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan profile: %w", err)
+		}
+		profiles = append(profiles, *p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return profiles, nil
+}
+
+// ReviewKYC records the review of the account's pending KYC. It returns
+// ErrKYCNotPending if the KYC is not awaiting review.
+func (r *postgresRepository) ReviewKYC(ctx context.Context, accountID, reviewer, status, reason string) (*Profile, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE merchant_profiles
+		SET kyc_status = $3, kyc_reviewed_at = NOW(), kyc_reviewed_by = $2, kyc_rejection_reason = $4, updated_at = NOW()
+		WHERE account_id = $1 AND kyc_status = 'pending'
+		RETURNING `+profileColumns, accountID, reviewer, status, reason)
+	p, err := scanProfile(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrKYCNotPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review kyc: %w", err)
+	}
+	return p, nil
+}
+
+const profileColumns = `account_id, company_name, gstin, pan, address_line1, address_line2, city, state, postal_code,
+	contact_phone, brand_name, logo_key, logo_content_type,
+	kyc_status, kyc_submitted_at, kyc_reviewed_at, kyc_reviewed_by, kyc_rejection_reason, updated_at`
+
+func scanProfile(row interface{ Scan(...any) error }) (*Profile, error) {
+	var p Profile
+	a := &p.BillingAddress
+	err := row.Scan(&p.AccountID, &p.CompanyName, &p.GSTIN, &p.PAN, &a.Line1, &a.Line2, &a.City, &a.State, &a.PostalCode,
+		&p.ContactPhone, &p.BrandName, &p.logoKey, &p.LogoContentType,
+		&p.KYCStatus, &p.KYCSubmittedAt, &p.KYCReviewedAt, &p.KYCReviewedBy, &p.KYCRejectionReason, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	p.HasLogo = p.logoKey != ""
+	return &p, nil
+}
+
+const kycDocumentColumns = `id, account_id, type, filename, content_type, size, blob_key, uploaded_by, uploaded_at`
+
+func scanKYCDocument(row interface{ Scan(...any) error }) (*KYCDocument, error) {
+	var doc KYCDocument
+	err := row.Scan(&doc.ID, &doc.AccountID, &doc.Type, &doc.Filename, &doc.ContentType, &doc.Size, &doc.blobKey, &doc.UploadedBy, &doc.UploadedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// dummyPasswordHash returns a bcrypt hash to compare passwords against when an
+// email is not found.
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
+// LoginFailures returns the number of failed logins counted against key and
+// the time of the latest. Counts whose latest failure is older than window are
+// treated as zero.
+func (r *postgresRepository) LoginFailures(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	var failures int
+	var last time.Time
+	err := r.db.QueryRowContext(ctx, `
+		SELECT failures, last_failure_at
+		FROM login_failures
+		WHERE key = $1 AND last_failure_at > NOW() - make_interval(secs => $2)
+	`, key, window.Seconds()).Scan(&failures, &last)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to query login failures: %w", err)
+	}
+	return failures, last, nil
+}
+
+// RecordLoginFailure counts a failed login against key, starting the count
+// again if the previous failure is older than window, and returns the new
+// count. Concurrent failures each get their own count.
+func (r *postgresRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO login_failures (key, failures, last_failure_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE WHEN login_failures.last_failure_at > NOW() - make_interval(secs => $2)
+				THEN login_failures.failures + 1 ELSE 1 END,
+			last_failure_at = NOW()
+		RETURNING failures
+	`, key, window.Seconds()).Scan(&failures)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return failures, nil
+}
+
+// RetractLoginFailure takes back a failed login counted against key.
+func (r *postgresRepository) RetractLoginFailure(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE login_failures SET failures = failures - 1
+		WHERE key = $1 AND failures > 0
+	`, key)
+	if err != nil {
+		return fmt.Errorf("failed to retract login failure: %w", err)
+	}
+	return nil
+}
+
+// ClearLoginFailures forgets the failed logins counted against key.
+func (r *postgresRepository) ClearLoginFailures(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM login_failures WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("failed to clear login failures: %w", err)
+	}
+	return nil
+}
+
+// AddLoginEvent inserts a login attempt into the account's history.
+func (r *postgresRepository) AddLoginEvent(ctx context.Context, event LoginEvent) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_events (id, account_id, user_agent, ip, network, success, failure_reason, new_device)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, uuid.NewString(), event.AccountID, event.Device.UserAgent, event.Device.IP, event.Network, event.Success, event.FailureReason, event.NewDevice)
+	if err != nil {
+		return fmt.Errorf("failed to insert login event: %w", err)
+	}
+	return nil
+}
+
+// ListLoginEvents retrieves the account's most recent login attempts, newest
+// first.
+func (r *postgresRepository) ListLoginEvents(ctx context.Context, accountID string, limit int) ([]LoginEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, account_id, user_agent, ip, network, success, failure_reason, new_device, created_at
+		FROM login_events
+		WHERE account_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, accountID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query login events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []LoginEvent{}
+	for rows.Next() {
+		var e LoginEvent
+		if err := rows.Scan(&e.ID, &e.AccountID, &e.Device.UserAgent, &e.Device.IP, &e.Network, &e.Success, &e.FailureReason, &e.NewDevice, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan login event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return events, nil
+}
+
+// LoginSourceSeen reports whether the account has logged in successfully from
+// the user agent, from the network, and at all before.
+func (r *postgresRepository) LoginSourceSeen(ctx context.Context, accountID, userAgent, network string) (seenDevice, seenNetwork, returning bool, err error) {
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(BOOL_OR(user_agent = $2), FALSE), COALESCE(BOOL_OR(network = $3), FALSE), COUNT(*) > 0
+		FROM login_events
+		WHERE account_id = $1 AND success
+	`, accountID, userAgent, network).Scan(&seenDevice, &seenNetwork, &returning)
+	if err != nil {
+		return false, false, false, fmt.Errorf("failed to query login events: %w", err)
+	}
+	return seenDevice, seenNetwork, returning, nil
+}
+
+// CreateDataRequest stores a pending data request. Only one export and one
+// erasure can be open for an account at a time.
+func (r *postgresRepository) CreateDataRequest(ctx context.Context, req DataRequest) (*DataRequest, error) {
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO data_subject_requests (id, account_id, kind, status)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+dataRequestColumns,
+		req.ID, req.AccountID, req.Kind, DataRequestPending)
+	created, err := scanDataRequest(row)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, ErrDataRequestInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert data request: %w", err)
+	}
+	return created, nil
+}
+
+// ListDataRequests retrieves the account's data requests, newest first.
+func (r *postgresRepository) ListDataRequests(ctx context.Context, accountID string) ([]DataRequest, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+dataRequestColumns+`
+		FROM data_subject_requests
+		WHERE account_id = $1
+		ORDER BY created_at DESC
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []DataRequest{}
+	for rows.Next() {
+		req, err := scanDataRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data request: %w", err)
+		}
+		requests = append(requests, *req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return requests, nil
+}
+
+// GetDataRequest retrieves one of the account's data requests.
+func (r *postgresRepository) GetDataRequest(ctx context.Context, accountID, requestID string) (*DataRequest, error) {
+	if _, err := uuid.Parse(requestID); err != nil {
+		return nil, ErrDataRequestNotFound
+	}
+	req, err := scanDataRequest(r.db.QueryRowContext(ctx, `
+		SELECT `+dataRequestColumns+`
+		FROM data_subject_requests
+		WHERE id = $1 AND account_id = $2
+	`, requestID, accountID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDataRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data request: %w", err)
+	}
+	return req, nil
+}
+
+// ClaimDataRequest marks the oldest due pending request running and counts
+// the attempt. Requests left running since staleBefore are claimed again.
+// Concurrent workers never claim the same request.
+func (r *postgresRepository) ClaimDataRequest(ctx context.Context, staleBefore time.Time) (*DataRequest, error) {
+	req, err := scanDataRequest(r.db.QueryRowContext(ctx, `
+		UPDATE data_subject_requests
+		SET status = $1, attempts = attempts + 1, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM data_subject_requests
+			WHERE (status = $2 AND next_attempt_at <= NOW()) OR (status = $1 AND updated_at < $3)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+dataRequestColumns,
+		DataRequestRunning, DataRequestPending, staleBefore))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDataRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim data request: %w", err)
+	}
+	return req, nil
+}
+
+// CompleteDataRequest records a finished request with its export archive or
+// the records kept by an erasure.
+func (r *postgresRepository) CompleteDataRequest(ctx context.Context, requestID, archiveKey string, retained []string, expiresAt *time.Time) error {
+	if retained == nil {
+		retained = []string{}
+	}
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE data_subject_requests
+		SET status = $2, archive_key = $3, retained = $4, expires_at = $5, last_error = '',
+			completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $6
+	`, requestID, DataRequestCompleted, archiveKey, pq.Array(retained), expiresAt, DataRequestRunning)
+	if err != nil {
+		return fmt.Errorf("failed to complete data request: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrDataRequestNotFound
+	}
+	return nil
+}
+
+// FailDataRequest records a failed attempt. The request is retried at retryAt,
+// or marked failed if retryAt is nil.
+func (r *postgresRepository) FailDataRequest(ctx context.Context, requestID, message string, retryAt *time.Time) error {
+	status := DataRequestFailed
+	if retryAt != nil {
+		status = DataRequestPending
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE data_subject_requests
+		SET status = $2, last_error = $3, next_attempt_at = COALESCE($4, next_attempt_at), updated_at = NOW()
+		WHERE id = $1 AND status = $5
+	`, requestID, status, message, retryAt, DataRequestRunning)
+	if err != nil {
+		return fmt.Errorf("failed to record data request failure: %w", err)
+	}
+	return nil
+}
+
+// ExpireDataExports marks completed exports past their expiry expired and
+// returns the keys of their archives, which are no longer referenced.
+func (r *postgresRepository) ExpireDataExports(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE data_subject_requests d
+		SET status = $1, archive_key = '', updated_at = NOW()
+		FROM (
+			SELECT id, archive_key FROM data_subject_requests
+			WHERE kind = $2 AND status = $3 AND expires_at <= $4
+			FOR UPDATE
+		) expired
+		WHERE d.id = expired.id
+		RETURNING expired.archive_key
+	`, DataRequestExpired, DataRequestExport, DataRequestCompleted, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire data exports: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan archive key: %w", err)
+		}
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return keys, nil
+}
+
+// EraseAccount anonymizes the account, deletes its sessions, credentials,
+// login history, API keys, invitations and KYC documents, removes it from
+// other teams and clears the personal details of its profile. The company
+// name, PAN and GSTIN are kept. It returns the blob keys of the files that
+// are no longer referenced: the logo, KYC documents and export archives.
+// Erasing an erased account does nothing.
+func (r *postgresRepository) EraseAccount(ctx context.Context, accountID string) (keys []string, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var email string
+	err = tx.QueryRowContext(ctx, `SELECT email FROM accounts WHERE id = $1 FOR UPDATE`, accountID).Scan(&email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT logo_key FROM merchant_profiles WHERE account_id = $1 AND logo_key <> ''
+		UNION ALL SELECT blob_key FROM kyc_documents WHERE account_id = $1
+		UNION ALL SELECT archive_key FROM data_subject_requests WHERE account_id = $1 AND archive_key <> ''
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blob keys: %w", err)
+	}
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan blob key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET name = 'Erased account', email = 'erased-' || id || '@erased.invalid', password = '',
+			email_verified_at = NULL, require_two_factor = FALSE,
+			deleted_at = COALESCE(deleted_at, NOW()), updated_at = NOW()
+		WHERE id = $1
+	`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize account: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM login_failures WHERE key = $1`, "email:"+strings.ToLower(email))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete login failures: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM invitations WHERE account_id = $1 OR LOWER(email) = LOWER($2)`, accountID, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete invitations: %w", err)
+	}
+
+	statements := []string{
+		`DELETE FROM sessions WHERE account_id = $1`,
+		`DELETE FROM one_time_tokens WHERE account_id = $1`,
+		`DELETE FROM account_two_factor WHERE account_id = $1`,
+		`DELETE FROM login_challenges WHERE account_id = $1`,
+		`DELETE FROM login_events WHERE account_id = $1`,
+		`DELETE FROM api_keys WHERE account_id = $1`,
+		`DELETE FROM kyc_documents WHERE account_id = $1`,
+		`DELETE FROM team_members WHERE user_id = $1 AND role <> 'owner'`,
+		`UPDATE merchant_profiles
+		SET address_line1 = '', address_line2 = '', city = '', state = '', postal_code = '',
+			contact_phone = '', brand_name = '', logo_key = '', logo_content_type = '',
+			kyc_rejection_reason = '', updated_at = NOW()
+		WHERE account_id = $1`,
+		`UPDATE data_subject_requests
+		SET archive_key = '', status = CASE WHEN status = 'completed' AND kind = 'export' THEN 'expired' ELSE status END,
+			updated_at = NOW()
+		WHERE account_id = $1 AND archive_key <> ''`,
+	}
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt, accountID); err != nil {
+			return nil, fmt.Errorf("failed to erase account data: %w", err)
+		}
+	}
+	return keys, nil
+}
+
+const dataRequestColumns = `id, account_id, kind, status, attempts, last_error, retained, archive_key,
+	created_at, completed_at, expires_at`
+
+func scanDataRequest(row interface{ Scan(...any) error }) (*DataRequest, error) {
+	var req DataRequest
+	err := row.Scan(&req.ID, &req.AccountID, &req.Kind, &req.Status, &req.Attempts, &req.LastError, pq.Array(&req.Retained),
+		&req.archiveKey, &req.CreatedAt, &req.CompletedAt, &req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
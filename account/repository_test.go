@@ -0,0 +1,94 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Shridhar2104/logilo/internal/sqltest"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUpdateAccount(t *testing.T) {
+	errRevoke := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		password string
+		script   func(m *sqltest.Mock)
+		wantErr  error
+	}{
+		{
+			name: "name and email",
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				m.ExpectExec("SET name = $2, email = $3").WithArgs("acct", "Asha", "asha@example.com").WillReturnResult(1)
+				m.ExpectCommit()
+			},
+		},
+		{
+			name:     "with a password",
+			password: "correct horse battery",
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				m.ExpectExec("SET name = $2, email = $3").WillReturnResult(1)
+				m.ExpectExec("SET password = $2")
+				m.ExpectExec("UPDATE sessions SET revoked_at").WithArgs("acct", "sess", RevokedPasswordChanged).WillReturnResult(2)
+				m.ExpectCommit()
+			},
+		},
+		{
+			name:     "sessions not revoked",
+			password: "correct horse battery",
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				m.ExpectExec("SET name = $2, email = $3").WillReturnResult(1)
+				m.ExpectExec("SET password = $2")
+				m.ExpectExec("UPDATE sessions SET revoked_at").WillReturnError(errRevoke)
+				m.ExpectRollback()
+			},
+			wantErr: errRevoke,
+		},
+		{
+			name:     "deleted account",
+			password: "correct horse battery",
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				m.ExpectExec("SET name = $2, email = $3").WillReturnResult(0)
+				m.ExpectRollback()
+			},
+			wantErr: ErrAccountNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m := sqltest.Open(t)
+			tt.script(m)
+
+			r := &postgresRepository{db: db}
+			err := r.UpdateAccount(context.Background(), "acct", "Asha", "asha@example.com", tt.password, "sess")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateAccount() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateAccountHashesPassword(t *testing.T) {
+	db, m := sqltest.Open(t)
+	m.ExpectBegin()
+	m.ExpectExec("SET name = $2, email = $3").WillReturnResult(1)
+	stored := m.ExpectExec("SET password = $2")
+	m.ExpectExec("UPDATE sessions SET revoked_at")
+	m.ExpectCommit()
+
+	r := &postgresRepository{db: db}
+	if err := r.UpdateAccount(context.Background(), "acct", "Asha", "asha@example.com", "correct horse battery", "sess"); err != nil {
+		t.Fatal(err)
+	}
+	hash, _ := stored.Args[1].(string)
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("correct horse battery")); err != nil {
+		t.Errorf("stored password %q does not match: %v", hash, err)
+	}
+}
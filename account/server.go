@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"slices"
+	"time"
 
 	"github.com/Shridhar2104/logilo/account/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 // grpcServer implements the gRPC server and embeds UnimplementedAccountServiceServer
@@ -19,8 +23,8 @@ type grpcServer struct {
 }
 
 // NewGRPCServer initializes and starts the gRPC server.
-// It takes a Service instance and the server's listening port as parameters.
-func NewGRPCServer(service Service, port int) error {
+// It takes a Service instance, the server's listening port and options such as interceptors as parameters.
+func NewGRPCServer(service Service, port int, opts ...grpc.ServerOption) error {
 	// Create a TCP listener at the specified port
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
@@ -28,7 +32,7 @@ func NewGRPCServer(service Service, port int) error {
 	}
 
 	// Initialize a new gRPC server instance
-	server := grpc.NewServer()
+	server := grpc.NewServer(opts...)
 
 	// Register the gRPC server with the service implementation
 	pb.RegisterAccountServiceServer(server, &grpcServer{service: service})
@@ -61,11 +65,11 @@ func (s *grpcServer) CreateAccount(ctx context.Context, r *pb.CreateAccountReque
 	}, nil
 }
 
-// GetAccountByID fetches account details based on the provided ID.
-// It queries the service layer and sends the account details back via gRPC.
+// GetAccountByEmailAndPassword validates an email and password and sends the
+// account details back via gRPC.
 func (s *grpcServer) GetAccountByEmailAndPassword(ctx context.Context, r *pb.GetAccountByEmailAndPasswordRequest) (*pb.GetAccountByEmailAndPasswordResponse, error) {
 	// Query the service layer for account details by email
-	a, err := s.service.LoginAccount(ctx, r.Email, r.Password)
+	a, err := s.service.LoginAccount(ctx, r.Email, r.Password, Device{UserAgent: r.UserAgent, IP: r.Ip})
 	if err != nil {
 		log.Printf("Error while authenticating account: %v", err)
 		return nil, fmt.Errorf("error while authenticating account: %w", err)
@@ -106,3 +110,708 @@ func (s *grpcServer) ListAccounts(ctx context.Context, r *pb.ListAccountsRequest
 		Accounts: grpcAccounts,
 	}, nil
 }
+
+// GetAccountByID fetches an active account by its id.
+func (s *grpcServer) GetAccountByID(ctx context.Context, r *pb.GetAccountByIDRequest) (*pb.GetAccountByIDResponse, error) {
+	// Users can look up themselves and the members of the team they act for.
+	if claims, ok := ClaimsFromContext(ctx); ok && claims.UserID != r.Id {
+		members, err := s.service.ListMembers(ctx, claims.UserID, claims.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("error while getting account: %w", err)
+		}
+		if !slices.ContainsFunc(members, func(m Member) bool { return m.UserID == r.Id }) {
+			return nil, fmt.Errorf("error while getting account: %w", ErrAccountNotFound)
+		}
+	}
+
+	a, err := s.service.GetAccount(ctx, r.Id)
+	if err != nil {
+		log.Printf("Error while getting account: %v", err)
+		return nil, fmt.Errorf("error while getting account: %w", err)
+	}
+	return &pb.GetAccountByIDResponse{Account: toProtoAccount(a)}, nil
+}
+
+// UpdateAccount changes the caller's name, email or password.
+func (s *grpcServer) UpdateAccount(ctx context.Context, r *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+	a, err := s.service.UpdateAccount(ctx, r.ActorId, r.SessionId, AccountUpdate{
+		Name:            r.Name,
+		Email:           r.Email,
+		Password:        r.Password,
+		CurrentPassword: r.CurrentPassword,
+	})
+	if err != nil {
+		log.Printf("Error while updating account: %v", err)
+		return nil, fmt.Errorf("error while updating account: %w", err)
+	}
+	return &pb.UpdateAccountResponse{Account: toProtoAccount(a)}, nil
+}
+
+// DeleteAccount deactivates the caller's account.
+func (s *grpcServer) DeleteAccount(ctx context.Context, r *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	if err := s.service.DeleteAccount(ctx, r.ActorId, r.Password); err != nil {
+		log.Printf("Error while deleting account: %v", err)
+		return nil, fmt.Errorf("error while deleting account: %w", err)
+	}
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+// Login validates the credentials and returns the account with a new token
+// pair, or with a challenge if it has two-factor authentication enabled.
+func (s *grpcServer) Login(ctx context.Context, r *pb.LoginRequest) (*pb.LoginResponse, error) {
+	res, err := s.service.Login(ctx, r.Email, r.Password, Device{UserAgent: r.UserAgent, IP: r.Ip})
+	if err != nil {
+		log.Printf("Error while logging in: %v", err)
+		return nil, fmt.Errorf("error while logging in: %w", err)
+	}
+	return toProtoLoginResponse(res), nil
+}
+
+// CompleteLogin answers a two-factor challenge and returns a new token pair.
+func (s *grpcServer) CompleteLogin(ctx context.Context, r *pb.CompleteLoginRequest) (*pb.LoginResponse, error) {
+	res, err := s.service.CompleteLogin(ctx, r.ChallengeToken, r.Code, Device{UserAgent: r.UserAgent, IP: r.Ip})
+	if err != nil {
+		log.Printf("Error while completing login: %v", err)
+		return nil, fmt.Errorf("error while completing login: %w", err)
+	}
+	return toProtoLoginResponse(res), nil
+}
+
+// RefreshToken rotates the session's refresh token and returns a new token pair.
+func (s *grpcServer) RefreshToken(ctx context.Context, r *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	pair, err := s.service.RefreshToken(ctx, r.RefreshToken, Device{UserAgent: r.UserAgent, IP: r.Ip})
+	if err != nil {
+		log.Printf("Error while refreshing token: %v", err)
+		return nil, fmt.Errorf("error while refreshing token: %w", err)
+	}
+
+	return &pb.RefreshTokenResponse{Tokens: toProtoTokenPair(pair)}, nil
+}
+
+// ListSessions returns the account's active sessions.
+func (s *grpcServer) ListSessions(ctx context.Context, r *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	sessions, err := s.service.ListSessions(ctx, r.AccountId)
+	if err != nil {
+		log.Printf("Error while listing sessions: %v", err)
+		return nil, fmt.Errorf("error while listing sessions: %w", err)
+	}
+
+	res := &pb.ListSessionsResponse{}
+	for _, session := range sessions {
+		res.Sessions = append(res.Sessions, &pb.Session{
+			Id:         session.ID,
+			UserAgent:  session.Device.UserAgent,
+			Ip:         session.Device.IP,
+			CreatedAt:  session.CreatedAt.Unix(),
+			LastUsedAt: session.LastUsedAt.Unix(),
+			ExpiresAt:  session.ExpiresAt.Unix(),
+		})
+	}
+	return res, nil
+}
+
+// ListLoginHistory returns the user's recent login attempts.
+func (s *grpcServer) ListLoginHistory(ctx context.Context, r *pb.ListLoginHistoryRequest) (*pb.ListLoginHistoryResponse, error) {
+	events, err := s.service.ListLoginHistory(ctx, r.ActorId, int(r.Limit))
+	if err != nil {
+		log.Printf("Error while listing login history: %v", err)
+		return nil, fmt.Errorf("error while listing login history: %w", err)
+	}
+
+	res := &pb.ListLoginHistoryResponse{}
+	for _, e := range events {
+		res.Events = append(res.Events, &pb.LoginEvent{
+			Id:            e.ID,
+			UserAgent:     e.Device.UserAgent,
+			Ip:            e.Device.IP,
+			Network:       e.Network,
+			Success:       e.Success,
+			FailureReason: e.FailureReason,
+			NewDevice:     e.NewDevice,
+			CreatedAt:     e.CreatedAt.Unix(),
+		})
+	}
+	return res, nil
+}
+
+// RequestDataExport queues an export of the user's data.
+func (s *grpcServer) RequestDataExport(ctx context.Context, r *pb.RequestDataExportRequest) (*pb.DataRequestResponse, error) {
+	req, err := s.service.RequestDataExport(ctx, r.ActorId)
+	if err != nil {
+		log.Printf("Error while requesting data export: %v", err)
+		return nil, fmt.Errorf("error while requesting data export: %w", err)
+	}
+	return &pb.DataRequestResponse{Request: toProtoDataRequest(req)}, nil
+}
+
+// RequestDataErasure queues the erasure of the user's data.
+func (s *grpcServer) RequestDataErasure(ctx context.Context, r *pb.RequestDataErasureRequest) (*pb.DataRequestResponse, error) {
+	req, err := s.service.RequestDataErasure(ctx, r.ActorId, r.Password)
+	if err != nil {
+		log.Printf("Error while requesting data erasure: %v", err)
+		return nil, fmt.Errorf("error while requesting data erasure: %w", err)
+	}
+	return &pb.DataRequestResponse{Request: toProtoDataRequest(req)}, nil
+}
+
+// ListDataRequests returns the user's data export and erasure requests.
+func (s *grpcServer) ListDataRequests(ctx context.Context, r *pb.ListDataRequestsRequest) (*pb.ListDataRequestsResponse, error) {
+	requests, err := s.service.ListDataRequests(ctx, r.ActorId)
+	if err != nil {
+		log.Printf("Error while listing data requests: %v", err)
+		return nil, fmt.Errorf("error while listing data requests: %w", err)
+	}
+
+	res := &pb.ListDataRequestsResponse{}
+	for i := range requests {
+		res.Requests = append(res.Requests, toProtoDataRequest(&requests[i]))
+	}
+	return res, nil
+}
+
+// GetDataExport returns the archive of a completed data export.
+func (s *grpcServer) GetDataExport(ctx context.Context, r *pb.GetDataExportRequest) (*pb.GetDataExportResponse, error) {
+	req, data, err := s.service.GetDataExport(ctx, r.ActorId, r.RequestId)
+	if err != nil {
+		log.Printf("Error while getting data export: %v", err)
+		return nil, fmt.Errorf("error while getting data export: %w", err)
+	}
+	return &pb.GetDataExportResponse{Request: toProtoDataRequest(req), Data: data}, nil
+}
+
+// RevokeSession ends one of the account's sessions.
+func (s *grpcServer) RevokeSession(ctx context.Context, r *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	if err := s.service.RevokeSession(ctx, r.AccountId, r.SessionId, r.Reason); err != nil {
+		log.Printf("Error while revoking session: %v", err)
+		return nil, fmt.Errorf("error while revoking session: %w", err)
+	}
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+// RevokeAllSessions ends all of the account's sessions except the one given.
+func (s *grpcServer) RevokeAllSessions(ctx context.Context, r *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	n, err := s.service.RevokeAllSessions(ctx, r.AccountId, r.ExceptSessionId)
+	if err != nil {
+		log.Printf("Error while revoking sessions: %v", err)
+		return nil, fmt.Errorf("error while revoking sessions: %w", err)
+	}
+	return &pb.RevokeAllSessionsResponse{Revoked: int32(n)}, nil
+}
+
+// SendVerificationEmail emails the account a link to verify its address.
+func (s *grpcServer) SendVerificationEmail(ctx context.Context, r *pb.SendVerificationEmailRequest) (*pb.SendVerificationEmailResponse, error) {
+	if err := s.service.SendVerificationEmail(ctx, r.AccountId); err != nil {
+		log.Printf("Error while sending verification email: %v", err)
+		return nil, fmt.Errorf("error while sending verification email: %w", err)
+	}
+	return &pb.SendVerificationEmailResponse{}, nil
+}
+
+// VerifyEmail consumes an email verification token.
+func (s *grpcServer) VerifyEmail(ctx context.Context, r *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	if err := s.service.VerifyEmail(ctx, r.Token); err != nil {
+		log.Printf("Error while verifying email: %v", err)
+		return nil, fmt.Errorf("error while verifying email: %w", err)
+	}
+	return &pb.VerifyEmailResponse{}, nil
+}
+
+// RequestPasswordReset emails a password-reset link if the address has an account.
+func (s *grpcServer) RequestPasswordReset(ctx context.Context, r *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	if err := s.service.RequestPasswordReset(ctx, r.Email); err != nil {
+		log.Printf("Error while requesting password reset: %v", err)
+		return nil, fmt.Errorf("error while requesting password reset: %w", err)
+	}
+	return &pb.RequestPasswordResetResponse{}, nil
+}
+
+// ResetPassword consumes a password-reset token and sets the new password.
+func (s *grpcServer) ResetPassword(ctx context.Context, r *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if err := s.service.ResetPassword(ctx, r.Token, r.NewPassword); err != nil {
+		log.Printf("Error while resetting password: %v", err)
+		return nil, fmt.Errorf("error while resetting password: %w", err)
+	}
+	return &pb.ResetPasswordResponse{}, nil
+}
+
+// BeginTwoFactorEnrollment generates a pending TOTP secret for the account.
+func (s *grpcServer) BeginTwoFactorEnrollment(ctx context.Context, r *pb.BeginTwoFactorEnrollmentRequest) (*pb.BeginTwoFactorEnrollmentResponse, error) {
+	enrollment, err := s.service.BeginTwoFactorEnrollment(ctx, r.AccountId)
+	if err != nil {
+		log.Printf("Error while starting two-factor enrollment: %v", err)
+		return nil, fmt.Errorf("error while starting two-factor enrollment: %w", err)
+	}
+	return &pb.BeginTwoFactorEnrollmentResponse{Secret: enrollment.Secret, OtpauthUri: enrollment.URI}, nil
+}
+
+// ConfirmTwoFactorEnrollment enables two-factor authentication and returns recovery codes.
+func (s *grpcServer) ConfirmTwoFactorEnrollment(ctx context.Context, r *pb.TwoFactorCodeRequest) (*pb.RecoveryCodesResponse, error) {
+	codes, err := s.service.ConfirmTwoFactorEnrollment(ctx, r.AccountId, r.Code)
+	if err != nil {
+		log.Printf("Error while confirming two-factor enrollment: %v", err)
+		return nil, fmt.Errorf("error while confirming two-factor enrollment: %w", err)
+	}
+	return &pb.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off.
+func (s *grpcServer) DisableTwoFactor(ctx context.Context, r *pb.TwoFactorCodeRequest) (*pb.DisableTwoFactorResponse, error) {
+	if err := s.service.DisableTwoFactor(ctx, r.AccountId, r.Code); err != nil {
+		log.Printf("Error while disabling two-factor authentication: %v", err)
+		return nil, fmt.Errorf("error while disabling two-factor authentication: %w", err)
+	}
+	return &pb.DisableTwoFactorResponse{}, nil
+}
+
+// RegenerateRecoveryCodes replaces the account's recovery codes.
+func (s *grpcServer) RegenerateRecoveryCodes(ctx context.Context, r *pb.TwoFactorCodeRequest) (*pb.RecoveryCodesResponse, error) {
+	codes, err := s.service.RegenerateRecoveryCodes(ctx, r.AccountId, r.Code)
+	if err != nil {
+		log.Printf("Error while regenerating recovery codes: %v", err)
+		return nil, fmt.Errorf("error while regenerating recovery codes: %w", err)
+	}
+	return &pb.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// InviteMember emails an invitation to join the account's team.
+func (s *grpcServer) InviteMember(ctx context.Context, r *pb.InviteMemberRequest) (*pb.InviteMemberResponse, error) {
+	inv, err := s.service.InviteMember(ctx, r.ActorId, r.AccountId, r.Email, r.Role)
+	if err != nil {
+		log.Printf("Error while inviting member: %v", err)
+		return nil, fmt.Errorf("error while inviting member: %w", err)
+	}
+	return &pb.InviteMemberResponse{Invitation: toProtoInvitation(inv)}, nil
+}
+
+// ListInvitations returns the account's pending invitations.
+func (s *grpcServer) ListInvitations(ctx context.Context, r *pb.ListInvitationsRequest) (*pb.ListInvitationsResponse, error) {
+	invitations, err := s.service.ListInvitations(ctx, r.ActorId, r.AccountId)
+	if err != nil {
+		log.Printf("Error while listing invitations: %v", err)
+		return nil, fmt.Errorf("error while listing invitations: %w", err)
+	}
+
+	res := &pb.ListInvitationsResponse{}
+	for i := range invitations {
+		res.Invitations = append(res.Invitations, toProtoInvitation(&invitations[i]))
+	}
+	return res, nil
+}
+
+// RevokeInvitation withdraws a pending invitation.
+func (s *grpcServer) RevokeInvitation(ctx context.Context, r *pb.RevokeInvitationRequest) (*pb.RevokeInvitationResponse, error) {
+	if err := s.service.RevokeInvitation(ctx, r.ActorId, r.AccountId, r.InvitationId); err != nil {
+		log.Printf("Error while revoking invitation: %v", err)
+		return nil, fmt.Errorf("error while revoking invitation: %w", err)
+	}
+	return &pb.RevokeInvitationResponse{}, nil
+}
+
+// AcceptInvitation adds the user to the inviting team.
+func (s *grpcServer) AcceptInvitation(ctx context.Context, r *pb.AcceptInvitationRequest) (*pb.AcceptInvitationResponse, error) {
+	m, err := s.service.AcceptInvitation(ctx, r.ActorId, r.Token)
+	if err != nil {
+		log.Printf("Error while accepting invitation: %v", err)
+		return nil, fmt.Errorf("error while accepting invitation: %w", err)
+	}
+	return &pb.AcceptInvitationResponse{Membership: toProtoMembership(m)}, nil
+}
+
+// ListMembers returns the account's team.
+func (s *grpcServer) ListMembers(ctx context.Context, r *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	members, err := s.service.ListMembers(ctx, r.ActorId, r.AccountId)
+	if err != nil {
+		log.Printf("Error while listing members: %v", err)
+		return nil, fmt.Errorf("error while listing members: %w", err)
+	}
+
+	res := &pb.ListMembersResponse{}
+	for i := range members {
+		res.Members = append(res.Members, &pb.Member{
+			Membership: toProtoMembership(&members[i].Membership),
+			Name:       members[i].Name,
+			Email:      members[i].Email,
+		})
+	}
+	return res, nil
+}
+
+// SetMemberRole changes a member's role.
+func (s *grpcServer) SetMemberRole(ctx context.Context, r *pb.SetMemberRoleRequest) (*pb.SetMemberRoleResponse, error) {
+	if err := s.service.SetMemberRole(ctx, r.ActorId, r.AccountId, r.UserId, r.Role); err != nil {
+		log.Printf("Error while setting member role: %v", err)
+		return nil, fmt.Errorf("error while setting member role: %w", err)
+	}
+	return &pb.SetMemberRoleResponse{}, nil
+}
+
+// RemoveMember removes a member from the team.
+func (s *grpcServer) RemoveMember(ctx context.Context, r *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error) {
+	if err := s.service.RemoveMember(ctx, r.ActorId, r.AccountId, r.UserId); err != nil {
+		log.Printf("Error while removing member: %v", err)
+		return nil, fmt.Errorf("error while removing member: %w", err)
+	}
+	return &pb.RemoveMemberResponse{}, nil
+}
+
+// ListMemberships returns the teams the user belongs to.
+func (s *grpcServer) ListMemberships(ctx context.Context, r *pb.ListMembershipsRequest) (*pb.ListMembershipsResponse, error) {
+	memberships, err := s.service.ListMemberships(ctx, r.ActorId)
+	if err != nil {
+		log.Printf("Error while listing memberships: %v", err)
+		return nil, fmt.Errorf("error while listing memberships: %w", err)
+	}
+
+	res := &pb.ListMembershipsResponse{}
+	for i := range memberships {
+		res.Memberships = append(res.Memberships, toProtoMembership(&memberships[i]))
+	}
+	return res, nil
+}
+
+// SwitchAccount reissues the session's tokens for another of the user's teams.
+func (s *grpcServer) SwitchAccount(ctx context.Context, r *pb.SwitchAccountRequest) (*pb.SwitchAccountResponse, error) {
+	pair, err := s.service.SwitchAccount(ctx, r.ActorId, r.SessionId, r.AccountId)
+	if err != nil {
+		log.Printf("Error while switching account: %v", err)
+		return nil, fmt.Errorf("error while switching account: %w", err)
+	}
+	return &pb.SwitchAccountResponse{Tokens: toProtoTokenPair(pair)}, nil
+}
+
+// SetTwoFactorRequired sets whether the team must use two-factor authentication.
+func (s *grpcServer) SetTwoFactorRequired(ctx context.Context, r *pb.SetTwoFactorRequiredRequest) (*pb.SetTwoFactorRequiredResponse, error) {
+	if err := s.service.SetTwoFactorRequired(ctx, r.ActorId, r.AccountId, r.Required); err != nil {
+		log.Printf("Error while setting two-factor requirement: %v", err)
+		return nil, fmt.Errorf("error while setting two-factor requirement: %w", err)
+	}
+	return &pb.SetTwoFactorRequiredResponse{}, nil
+}
+
+// CreateAPIKey creates an API key for the account.
+func (s *grpcServer) CreateAPIKey(ctx context.Context, r *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	scopes := make([]Permission, len(r.Scopes))
+	for i, scope := range r.Scopes {
+		scopes[i] = Permission(scope)
+	}
+	key, secret, err := s.service.CreateAPIKey(ctx, r.ActorId, r.AccountId, r.Name, scopes, int(r.RateLimit))
+	if err != nil {
+		log.Printf("Error while creating api key: %v", err)
+		return nil, fmt.Errorf("error while creating api key: %w", err)
+	}
+	return &pb.CreateAPIKeyResponse{ApiKey: toProtoAPIKey(key), Secret: secret}, nil
+}
+
+// ListAPIKeys returns the account's active API keys.
+func (s *grpcServer) ListAPIKeys(ctx context.Context, r *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	keys, err := s.service.ListAPIKeys(ctx, r.ActorId, r.AccountId)
+	if err != nil {
+		log.Printf("Error while listing api keys: %v", err)
+		return nil, fmt.Errorf("error while listing api keys: %w", err)
+	}
+
+	res := &pb.ListAPIKeysResponse{}
+	for i := range keys {
+		res.ApiKeys = append(res.ApiKeys, toProtoAPIKey(&keys[i]))
+	}
+	return res, nil
+}
+
+// RevokeAPIKey revokes one of the account's API keys.
+func (s *grpcServer) RevokeAPIKey(ctx context.Context, r *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	if err := s.service.RevokeAPIKey(ctx, r.ActorId, r.AccountId, r.KeyId); err != nil {
+		log.Printf("Error while revoking api key: %v", err)
+		return nil, fmt.Errorf("error while revoking api key: %w", err)
+	}
+	return &pb.RevokeAPIKeyResponse{}, nil
+}
+
+// AuthenticateAPIKey exchanges an API key for a short-lived access token.
+func (s *grpcServer) AuthenticateAPIKey(ctx context.Context, r *pb.AuthenticateAPIKeyRequest) (*pb.AuthenticateAPIKeyResponse, error) {
+	t, err := s.service.AuthenticateAPIKey(ctx, r.Secret)
+	if err != nil {
+		log.Printf("Error while authenticating api key: %v", err)
+		return nil, fmt.Errorf("error while authenticating api key: %w", err)
+	}
+	return &pb.AuthenticateAPIKeyResponse{
+		ApiKey:      toProtoAPIKey(t.Key),
+		AccessToken: t.AccessToken,
+		ExpiresAt:   t.ExpiresAt.Unix(),
+	}, nil
+}
+
+// GetProfile returns the account's merchant profile.
+func (s *grpcServer) GetProfile(ctx context.Context, r *pb.GetProfileRequest) (*pb.ProfileResponse, error) {
+	p, err := s.service.GetProfile(ctx, r.ActorId, r.AccountId)
+	if err != nil {
+		log.Printf("Error while getting profile: %v", err)
+		return nil, fmt.Errorf("error while getting profile: %w", err)
+	}
+	return &pb.ProfileResponse{Profile: toProtoProfile(p)}, nil
+}
+
+// GetBusinessProfile returns the account's merchant profile to other services.
+func (s *grpcServer) GetBusinessProfile(ctx context.Context, r *pb.GetBusinessProfileRequest) (*pb.ProfileResponse, error) {
+	p, err := s.service.GetBusinessProfile(ctx, r.AccountId)
+	if err != nil {
+		log.Printf("Error while getting business profile: %v", err)
+		return nil, fmt.Errorf("error while getting business profile: %w", err)
+	}
+	return &pb.ProfileResponse{Profile: toProtoProfile(p)}, nil
+}
+
+// UpdateProfile sets the account's business details.
+func (s *grpcServer) UpdateProfile(ctx context.Context, r *pb.UpdateProfileRequest) (*pb.ProfileResponse, error) {
+	p, err := s.service.UpdateProfile(ctx, r.ActorId, r.AccountId, fromProtoProfile(r.Profile))
+	if err != nil {
+		log.Printf("Error while updating profile: %v", err)
+		return nil, fmt.Errorf("error while updating profile: %w", err)
+	}
+	return &pb.ProfileResponse{Profile: toProtoProfile(p)}, nil
+}
+
+// UploadLogo replaces the account's label logo.
+func (s *grpcServer) UploadLogo(ctx context.Context, r *pb.UploadLogoRequest) (*pb.ProfileResponse, error) {
+	p, err := s.service.UploadLogo(ctx, r.ActorId, r.AccountId, r.Data)
+	if err != nil {
+		log.Printf("Error while uploading logo: %v", err)
+		return nil, fmt.Errorf("error while uploading logo: %w", err)
+	}
+	return &pb.ProfileResponse{Profile: toProtoProfile(p)}, nil
+}
+
+// GetLogo returns the account's label logo.
+func (s *grpcServer) GetLogo(ctx context.Context, r *pb.GetLogoRequest) (*pb.GetLogoResponse, error) {
+	data, contentType, err := s.service.GetLogo(ctx, r.AccountId)
+	if err != nil {
+		log.Printf("Error while getting logo: %v", err)
+		return nil, fmt.Errorf("error while getting logo: %w", err)
+	}
+	return &pb.GetLogoResponse{Data: data, ContentType: contentType}, nil
+}
+
+// UploadKYCDocument stores a document and submits the account's KYC for review.
+func (s *grpcServer) UploadKYCDocument(ctx context.Context, r *pb.UploadKYCDocumentRequest) (*pb.UploadKYCDocumentResponse, error) {
+	doc, err := s.service.UploadKYCDocument(ctx, r.ActorId, r.AccountId, r.Type, r.Filename, r.Data)
+	if err != nil {
+		log.Printf("Error while uploading kyc document: %v", err)
+		return nil, fmt.Errorf("error while uploading kyc document: %w", err)
+	}
+	return &pb.UploadKYCDocumentResponse{Document: toProtoKYCDocument(doc)}, nil
+}
+
+// ListKYCDocuments returns the account's KYC documents.
+func (s *grpcServer) ListKYCDocuments(ctx context.Context, r *pb.ListKYCDocumentsRequest) (*pb.ListKYCDocumentsResponse, error) {
+	docs, err := s.service.ListKYCDocuments(ctx, r.ActorId, r.AccountId)
+	if err != nil {
+		log.Printf("Error while listing kyc documents: %v", err)
+		return nil, fmt.Errorf("error while listing kyc documents: %w", err)
+	}
+
+	res := &pb.ListKYCDocumentsResponse{}
+	for i := range docs {
+		res.Documents = append(res.Documents, toProtoKYCDocument(&docs[i]))
+	}
+	return res, nil
+}
+
+// GetKYCDocument returns a KYC document and its file for review.
+func (s *grpcServer) GetKYCDocument(ctx context.Context, r *pb.GetKYCDocumentRequest) (*pb.GetKYCDocumentResponse, error) {
+	doc, data, err := s.service.GetKYCDocument(ctx, r.AccountId, r.DocumentId)
+	if err != nil {
+		log.Printf("Error while getting kyc document: %v", err)
+		return nil, fmt.Errorf("error while getting kyc document: %w", err)
+	}
+	return &pb.GetKYCDocumentResponse{Document: toProtoKYCDocument(doc), Data: data}, nil
+}
+
+// ListPendingKYC returns the accounts awaiting KYC review.
+func (s *grpcServer) ListPendingKYC(ctx context.Context, r *pb.ListPendingKYCRequest) (*pb.ListPendingKYCResponse, error) {
+	submissions, err := s.service.ListPendingKYC(ctx)
+	if err != nil {
+		log.Printf("Error while listing pending kyc: %v", err)
+		return nil, fmt.Errorf("error while listing pending kyc: %w", err)
+	}
+
+	res := &pb.ListPendingKYCResponse{}
+	for i := range submissions {
+		sub := &pb.KYCSubmission{Profile: toProtoProfile(&submissions[i].Profile)}
+		for j := range submissions[i].Documents {
+			sub.Documents = append(sub.Documents, toProtoKYCDocument(&submissions[i].Documents[j]))
+		}
+		res.Submissions = append(res.Submissions, sub)
+	}
+	return res, nil
+}
+
+// ReviewKYC approves or rejects an account's pending KYC. The reviewer is the
+// member of staff the call's token was issued to.
+func (s *grpcServer) ReviewKYC(ctx context.Context, r *pb.ReviewKYCRequest) (*pb.ProfileResponse, error) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok || claims.Staff == "" {
+		return nil, status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+	p, err := s.service.ReviewKYC(ctx, claims.Staff, r.AccountId, r.Approve, r.Reason)
+	if err != nil {
+		log.Printf("Error while reviewing kyc: %v", err)
+		return nil, fmt.Errorf("error while reviewing kyc: %w", err)
+	}
+	return &pb.ProfileResponse{Profile: toProtoProfile(p)}, nil
+}
+
+// GetKYCStatus reports whether the account's KYC is approved and its email
+// address verified.
+func (s *grpcServer) GetKYCStatus(ctx context.Context, r *pb.GetKYCStatusRequest) (*pb.GetKYCStatusResponse, error) {
+	approved, err := s.service.KYCApproved(ctx, r.AccountId)
+	if err != nil {
+		log.Printf("Error while getting kyc status: %v", err)
+		return nil, fmt.Errorf("error while getting kyc status: %w", err)
+	}
+	verified, err := s.service.EmailVerified(ctx, r.AccountId)
+	if err != nil {
+		log.Printf("Error while getting kyc status: %v", err)
+		return nil, fmt.Errorf("error while getting kyc status: %w", err)
+	}
+	return &pb.GetKYCStatusResponse{Approved: approved, EmailVerified: verified}, nil
+}
+
+func toProtoLoginResponse(res *LoginResult) *pb.LoginResponse {
+	out := &pb.LoginResponse{Account: toProtoAccount(res.Account)}
+	if res.Tokens != nil {
+		out.Tokens = toProtoTokenPair(res.Tokens)
+	}
+	if res.Challenge != nil {
+		out.ChallengeToken = res.Challenge.Token
+		out.ChallengeExpiresAt = res.Challenge.ExpiresAt.Unix()
+	}
+	return out
+}
+
+func toProtoAccount(a *Account) *pb.Account {
+	return &pb.Account{
+		Id:              a.ID.String(),
+		Name:            a.Name,
+		Email:           a.Email,
+		EmailVerifiedAt: unixOrZero(a.EmailVerifiedAt),
+	}
+}
+
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func toProtoTokenPair(pair *TokenPair) *pb.TokenPair {
+	return &pb.TokenPair{
+		SessionId:        pair.SessionID,
+		AccessToken:      pair.AccessToken,
+		RefreshToken:     pair.RefreshToken,
+		AccessExpiresAt:  pair.AccessExpiresAt.Unix(),
+		RefreshExpiresAt: pair.RefreshExpiresAt.Unix(),
+	}
+}
+
+func toProtoMembership(m *Membership) *pb.Membership {
+	return &pb.Membership{
+		AccountId:   m.AccountID,
+		AccountName: m.AccountName,
+		UserId:      m.UserID,
+		Role:        m.Role,
+		CreatedAt:   m.CreatedAt.Unix(),
+	}
+}
+
+func toProtoInvitation(inv *Invitation) *pb.Invitation {
+	return &pb.Invitation{
+		Id:        inv.ID,
+		AccountId: inv.AccountID,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		InvitedBy: inv.InvitedBy,
+		CreatedAt: inv.CreatedAt.Unix(),
+		ExpiresAt: inv.ExpiresAt.Unix(),
+	}
+}
+
+func toProtoAPIKey(key *APIKey) *pb.APIKey {
+	return &pb.APIKey{
+		Id:         key.ID,
+		AccountId:  key.AccountID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopeStrings(key.Scopes),
+		RateLimit:  int32(key.RateLimit),
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt.Unix(),
+		LastUsedAt: unixOrZero(key.LastUsedAt),
+	}
+}
+
+func toProtoProfile(p *Profile) *pb.Profile {
+	a := p.BillingAddress
+	return &pb.Profile{
+		AccountId:   p.AccountID,
+		CompanyName: p.CompanyName,
+		Gstin:       p.GSTIN,
+		Pan:         p.PAN,
+		BillingAddress: &pb.PostalAddress{
+			Line1:      a.Line1,
+			Line2:      a.Line2,
+			City:       a.City,
+			State:      a.State,
+			PostalCode: a.PostalCode,
+		},
+		ContactPhone:       p.ContactPhone,
+		BrandName:          p.BrandName,
+		HasLogo:            p.HasLogo,
+		KycStatus:          p.KYCStatus,
+		KycSubmittedAt:     unixOrZero(p.KYCSubmittedAt),
+		KycReviewedAt:      unixOrZero(p.KYCReviewedAt),
+		KycReviewedBy:      p.KYCReviewedBy,
+		KycRejectionReason: p.KYCRejectionReason,
+		UpdatedAt:          p.UpdatedAt.Unix(),
+	}
+}
+
+func fromProtoProfile(p *pb.Profile) Profile {
+	out := Profile{
+		CompanyName:  p.GetCompanyName(),
+		GSTIN:        p.GetGstin(),
+		PAN:          p.GetPan(),
+		ContactPhone: p.GetContactPhone(),
+		BrandName:    p.GetBrandName(),
+	}
+	if a := p.GetBillingAddress(); a != nil {
+		out.BillingAddress = Address{Line1: a.Line1, Line2: a.Line2, City: a.City, State: a.State, PostalCode: a.PostalCode}
+	}
+	return out
+}
+
+func toProtoKYCDocument(doc *KYCDocument) *pb.KYCDocument {
+	return &pb.KYCDocument{
+		Id:          doc.ID,
+		AccountId:   doc.AccountID,
+		Type:        doc.Type,
+		Filename:    doc.Filename,
+		ContentType: doc.ContentType,
+		Size:        int32(doc.Size),
+		UploadedBy:  doc.UploadedBy,
+		UploadedAt:  doc.UploadedAt.Unix(),
+	}
+}
+
+func toProtoDataRequest(req *DataRequest) *pb.DataRequest {
+	return &pb.DataRequest{
+		Id:          req.ID,
+		Kind:        req.Kind,
+		Status:      req.Status,
+		Attempts:    int32(req.Attempts),
+		LastError:   req.LastError,
+		Retained:    req.Retained,
+		CreatedAt:   req.CreatedAt.Unix(),
+		CompletedAt: unixOrZero(req.CompletedAt),
+		ExpiresAt:   unixOrZero(req.ExpiresAt),
+	}
+}
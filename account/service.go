@@ -2,8 +2,14 @@ package account
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
 	"time"
 
+	"github.com/Shridhar2104/logilo/datasubject"
 	"github.com/google/uuid"
 	// "golang.org/x/crypto/bcrypt" // Import for password hashing
 )
@@ -11,28 +17,116 @@ import (
 // Service interface defines the operations provided by the Account service.
 type Service interface {
 	CreateAccount(ctx context.Context, name string, password string, email string) (*Account, error) // Create a new account
-	LoginAccount(ctx context.Context, email string, password string) (*Account, error)               // Login and validate account
+	LoginAccount(ctx context.Context, email string, password string, device Device) (*Account, error) // Login and validate account
 	ListAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error)                  // List accounts with pagination
+	GetAccount(ctx context.Context, id string) (*Account, error)                                    // Fetch an account by id
+	UpdateAccount(ctx context.Context, actorID, sessionID string, update AccountUpdate) (*Account, error) // Change name, email or password
+	DeleteAccount(ctx context.Context, actorID, password string) error                              // Deactivate an account
+	Login(ctx context.Context, email string, password string, device Device) (*LoginResult, error)         // Validate credentials and start a session or challenge
+	CompleteLogin(ctx context.Context, challengeToken string, code string, device Device) (*LoginResult, error) // Answer a two-factor challenge
+	RefreshToken(ctx context.Context, refreshToken string, device Device) (*TokenPair, error)              // Rotate a session's refresh token
+	ListSessions(ctx context.Context, userID string) ([]Session, error)                                    // List a user's active sessions
+	RevokeSession(ctx context.Context, userID string, sessionID string, reason string) error               // End one session
+	RevokeAllSessions(ctx context.Context, userID string, exceptSessionID string) (int, error)             // End all sessions but one
+	ListLoginHistory(ctx context.Context, userID string, limit int) ([]LoginEvent, error)                   // List recent login attempts
+	SendVerificationEmail(ctx context.Context, accountID string) error                                     // Email an address verification link
+	VerifyEmail(ctx context.Context, token string) error                                                   // Consume a verification token
+	RequestPasswordReset(ctx context.Context, email string) error                                          // Email a password-reset link
+	ResetPassword(ctx context.Context, token string, newPassword string) error                             // Consume a reset token and set the password
+	BeginTwoFactorEnrollment(ctx context.Context, accountID string) (*TwoFactorEnrollment, error)          // Generate a pending TOTP secret
+	ConfirmTwoFactorEnrollment(ctx context.Context, accountID string, code string) ([]string, error)       // Enable TOTP and return recovery codes
+	DisableTwoFactor(ctx context.Context, accountID string, code string) error                             // Turn TOTP off
+	RegenerateRecoveryCodes(ctx context.Context, accountID string, code string) ([]string, error)          // Replace recovery codes
+
+	InviteMember(ctx context.Context, actorID, accountID, email, role string) (*Invitation, error) // Invite an email address to the team
+	ListInvitations(ctx context.Context, actorID, accountID string) ([]Invitation, error)          // List pending invitations
+	RevokeInvitation(ctx context.Context, actorID, accountID, invitationID string) error           // Withdraw an invitation
+	AcceptInvitation(ctx context.Context, actorID, token string) (*Membership, error)              // Join a team
+	ListMembers(ctx context.Context, actorID, accountID string) ([]Member, error)                  // List the team
+	SetMemberRole(ctx context.Context, actorID, accountID, userID, role string) error              // Change a member's role
+	RemoveMember(ctx context.Context, actorID, accountID, userID string) error                     // Remove a member, or leave
+	ListMemberships(ctx context.Context, userID string) ([]Membership, error)                       // List the user's teams
+	SwitchAccount(ctx context.Context, userID, sessionID, accountID string) (*TokenPair, error)    // Act for another team
+	SetTwoFactorRequired(ctx context.Context, actorID, accountID string, required bool) error      // Require two-factor for the team
+
+	CreateAPIKey(ctx context.Context, actorID, accountID, name string, scopes []Permission, rateLimit int) (*APIKey, string, error) // Create an API key
+	ListAPIKeys(ctx context.Context, actorID, accountID string) ([]APIKey, error)                                                   // List active API keys
+	RevokeAPIKey(ctx context.Context, actorID, accountID, keyID string) error                                                       // Revoke an API key
+	AuthenticateAPIKey(ctx context.Context, secret string) (*APIKeyToken, error)                                                    // Exchange an API key for an access token
+
+	GetProfile(ctx context.Context, actorID, accountID string) (*Profile, error)                                                         // Get the merchant profile
+	UpdateProfile(ctx context.Context, actorID, accountID string, p Profile) (*Profile, error)                                           // Set business details
+	UploadLogo(ctx context.Context, actorID, accountID string, data []byte) (*Profile, error)                                           // Replace the label logo
+	GetLogo(ctx context.Context, accountID string) ([]byte, string, error)                                                              // Get the label logo and its content type
+	GetBusinessProfile(ctx context.Context, accountID string) (*Profile, error)                                                          // Get the profile for invoicing
+	UploadKYCDocument(ctx context.Context, actorID, accountID, docType, filename string, data []byte) (*KYCDocument, error)              // Upload a document for KYC review
+	ListKYCDocuments(ctx context.Context, actorID, accountID string) ([]KYCDocument, error)                                             // List uploaded KYC documents
+	GetKYCDocument(ctx context.Context, accountID, documentID string) (*KYCDocument, []byte, error)                                     // Get a KYC document for review
+	ListPendingKYC(ctx context.Context) ([]KYCSubmission, error)                                                                        // List accounts awaiting KYC review
+	ReviewKYC(ctx context.Context, reviewer, accountID string, approve bool, reason string) (*Profile, error)                           // Approve or reject KYC
+	KYCApproved(ctx context.Context, accountID string) (bool, error)                                                                    // Report whether KYC is approved
+	EmailVerified(ctx context.Context, accountID string) (bool, error)                                                                  // Report whether the account's email is verified
+
+	RequestDataExport(ctx context.Context, actorID string) (*DataRequest, error)                                // Queue an export of the user's data
+	RequestDataErasure(ctx context.Context, actorID, password string) (*DataRequest, error)                     // Queue the erasure of the user's data
+	ListDataRequests(ctx context.Context, actorID string) ([]DataRequest, error)                                // List the user's data requests
+	GetDataExport(ctx context.Context, actorID, requestID string) (*DataRequest, []byte, error)                 // Get a completed export's archive
+	ProcessDataRequests(ctx context.Context) error                                                              // Run due data requests across every service
 }
 
+// Reasons sessions are revoked when the account changes.
+const (
+	RevokedPasswordChanged = "password_changed"
+	RevokedAccountDeleted  = "account_deleted"
+)
+
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrEmailInUse      = errors.New("email address is already in use")
+	ErrInvalidEmail    = errors.New("invalid email address")
+	ErrInvalidName     = errors.New("name cannot be empty")
+	ErrTeamHasMembers  = errors.New("remove the other team members before deleting the account")
+)
+
 // Account struct represents the Account entity in the system.
 type Account struct {
 	ID        uuid.UUID `json:"id"`         // Unique identifier for the account
 	Name      string    `json:"name"`       // Account holder's name
 	Password  string    `json:"password"`   // Account password (hashed ideally)
 	Email     string    `json:"email"`      // Account holder's email
+	EmailVerifiedAt *time.Time `json:"email_verified_at"` // When the email address was verified; nil until then
+	TwoFactorRequired bool `json:"two_factor_required"` // Whether team members must use two-factor authentication
 	CreatedAt time.Time `json:"created_at"` // Timestamp when the account was created
 	UpdatedAt time.Time `json:"updated_at"` // Timestamp when the account was last updated
 }
 
+// AccountUpdate holds the account details to change. Empty fields are left
+// as they are. Changing the email or password needs the current password.
+type AccountUpdate struct {
+	Name            string
+	Email           string
+	Password        string
+	CurrentPassword string
+}
+
 // accountService is a concrete implementation of the Service interface.
 type accountService struct {
-	repo Repository // Dependency on the Repository interface for database operations
+	repo   Repository    // Dependency on the Repository interface for database operations
+	tokens *TokenManager // Signs and verifies access and refresh tokens
+	mailer Mailer        // Delivers verification and password-reset emails
+	appURL string        // Base URL of the web app that emailed links point to
+
+	twoFactor    *TwoFactorAuthenticator   // Generates and checks TOTP secrets
+	blobs        BlobStore                 // Keeps KYC documents, label logos and data exports
+	participants []datasubject.Participant // Other services that data requests fan out to
+	plans        PlanChecker               // Whether merchants' plans include API access
 }
 
 // NewAccountService is a constructor for accountService, returning a Service implementation.
-func NewAccountService(repo Repository) Service {
-	return &accountService{repo}
+// participants are the other services holding merchant data; erasures run
+// through them in order, so services that may refuse should come first.
+func NewAccountService(repo Repository, tokens *TokenManager, twoFactor *TwoFactorAuthenticator, mailer Mailer, blobs BlobStore, appURL string, participants []datasubject.Participant, plans PlanChecker) Service {
+	return &accountService{repo: repo, tokens: tokens, twoFactor: twoFactor, mailer: mailer, blobs: blobs, appURL: appURL, participants: participants, plans: plans}
 }
 
 // CreateAccount creates a new account with the provided details and saves it in the database.
@@ -51,16 +145,22 @@ func (s *accountService) CreateAccount(ctx context.Context, name string, passwor
 		return nil, err // Return an error if the database operation fails
 	}
 
+	// The account is usable without the email; the user can ask for it again.
+	if err := s.SendVerificationEmail(ctx, a.ID.String()); err != nil {
+		log.Printf("Failed to send verification email to account %s: %v", a.ID, err)
+	}
+
 	return a, nil // Return the created account on success
 }
 
 // LoginAccount validates the email and password and returns the account if valid.
-func (s *accountService) LoginAccount(ctx context.Context, email string, password string) (*Account, error) {
-	// Use the repository to fetch the account by email and validate the password
-	account, err := s.repo.GetAccountByEmailAndPassword(ctx, email, password)
+func (s *accountService) LoginAccount(ctx context.Context, email string, password string, device Device) (*Account, error) {
+	// Validate the credentials, subject to the same lockouts as Login
+	account, err := s.checkCredentials(ctx, email, password, device)
 	if err != nil {
 		return nil, err // Return an error if validation fails
 	}
+	s.recordLogin(ctx, account, device)
 
 	return account, nil // Return the account on successful validation
 }
@@ -75,3 +175,85 @@ func (s *accountService) ListAccounts(ctx context.Context, skip uint64, take uin
 	// Delegate the task to the repository's ListAccounts method.
 	return s.repo.ListAccounts(ctx, skip, take)
 }
+
+// GetAccount retrieves an active account by its id.
+func (s *accountService) GetAccount(ctx context.Context, id string) (*Account, error) {
+	return s.repo.GetAccountByID(ctx, id)
+}
+
+// UpdateAccount changes the account's name, email or password. A new email
+// must be verified again; the old address is told of the change. A new
+// password signs out every session but sessionID.
+func (s *accountService) UpdateAccount(ctx context.Context, actorID string, sessionID string, update AccountUpdate) (*Account, error) {
+	account, err := s.repo.GetAccountByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(update.Name)
+	if update.Name != "" && name == "" {
+		return nil, ErrInvalidName
+	}
+	if name == "" {
+		name = account.Name
+	}
+	email := account.Email
+	if update.Email != "" {
+		addr, err := mail.ParseAddress(update.Email)
+		if err != nil || addr.Name != "" {
+			return nil, ErrInvalidEmail
+		}
+		email = addr.Address
+	}
+	if update.Password != "" && len(update.Password) < minPasswordLength {
+		return nil, ErrWeakPassword
+	}
+
+	emailChanged := !strings.EqualFold(email, account.Email)
+	if emailChanged || update.Password != "" {
+		// Checked like a login, so this cannot be used to guess the password.
+		if _, err := s.checkCredentials(ctx, account.Email, update.CurrentPassword, Device{}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateAccount(ctx, actorID, name, email, update.Password, sessionID); err != nil {
+		return nil, err
+	}
+	if emailChanged {
+		err := s.mailer.Send(ctx, Message{
+			To:      account.Email,
+			Subject: "Your Logilo email address was changed",
+			Body:    fmt.Sprintf("Hi %s,\n\nThe email address of your Logilo account was changed to %s. If you did not make this change, contact support.\n", account.Name, email),
+		})
+		if err != nil {
+			log.Printf("Failed to email account %s about its email change: %v", actorID, err)
+		}
+		if err := s.SendVerificationEmail(ctx, actorID); err != nil {
+			log.Printf("Failed to send verification email to account %s: %v", actorID, err)
+		}
+	}
+	return s.repo.GetAccountByID(ctx, actorID)
+}
+
+// DeleteAccount deactivates the account after checking its password. The
+// account can no longer log in, its sessions and API keys are revoked and it
+// leaves the teams it belongs to. An owner must first remove their team.
+func (s *accountService) DeleteAccount(ctx context.Context, actorID string, password string) error {
+	account, err := s.repo.GetAccountByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.checkCredentials(ctx, account.Email, password, Device{}); err != nil {
+		return err
+	}
+
+	members, err := s.repo.ListMembers(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if len(members) > 1 {
+		return ErrTeamHasMembers
+	}
+	return s.repo.DeleteAccount(ctx, actorID)
+}
@@ -0,0 +1,150 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons a session was revoked.
+const (
+	RevokedLogout     = "logout"
+	RevokedByUser     = "revoked"
+	RevokedTokenReuse = "refresh_token_reused" // An already-rotated refresh token was presented.
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionRevoked     = errors.New("session has been revoked or has expired")
+	ErrRefreshTokenReused = errors.New("refresh token has already been used; the session has been revoked")
+)
+
+// Device identifies where a session was started or last used from.
+type Device struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is a login of an account on one device. Each session holds a single
+// valid refresh token, which is rotated every time it is used. Presenting a
+// refresh token that has already been rotated means it was copied, so the
+// whole session is revoked.
+type Session struct {
+	ID            string
+	UserID        string
+	Device        Device // The device of the most recent login or refresh.
+	CreatedAt     time.Time
+	LastUsedAt    time.Time
+	ExpiresAt     time.Time
+	RevokedAt     *time.Time
+	RevokedReason string
+}
+
+// Login validates the credentials, which are locked out after repeated
+// failures. Accounts without two-factor authentication get a new session for
+// the device and its first token pair; the others get a challenge to complete
+// with CompleteLogin.
+func (s *accountService) Login(ctx context.Context, email string, password string, device Device) (*LoginResult, error) {
+	account, err := s.checkCredentials(ctx, email, password, device)
+	if err != nil {
+		return nil, err
+	}
+
+	tf, err := s.repo.GetTwoFactor(ctx, account.ID.String())
+	if err != nil && !errors.Is(err, ErrTwoFactorNotEnabled) {
+		return nil, err
+	}
+	if tf != nil && tf.EnabledAt != nil {
+		challenge, err := s.challengeLogin(ctx, account.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Account: account, Challenge: challenge}, nil
+	}
+
+	pair, err := s.startSession(ctx, account, device)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Account: account, Tokens: pair}, nil
+}
+
+// startSession starts a session for the device, acting for the user's own
+// account, issues its first token pair and records the login.
+func (s *accountService) startSession(ctx context.Context, user *Account, device Device) (*TokenPair, error) {
+	membership, err := s.membershipFor(ctx, user, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	pair, err := s.tokens.Issue(user, membership, uuid.NewString())
+	if err != nil {
+		return nil, err
+	}
+	session := Session{
+		ID:        pair.SessionID,
+		UserID:    user.ID.String(),
+		Device:    device,
+		ExpiresAt: pair.RefreshExpiresAt,
+	}
+	if err := s.repo.CreateSession(ctx, session, pair.refreshID); err != nil {
+		return nil, err
+	}
+	s.recordLogin(ctx, user, device)
+	return pair, nil
+}
+
+// RefreshToken rotates the session's refresh token and issues a new token pair.
+// Reusing a rotated refresh token revokes the session and returns
+// ErrRefreshTokenReused. The new tokens carry the user's current role; users
+// who can no longer act for the team they were acting for are moved to their
+// own account.
+func (s *accountService) RefreshToken(ctx context.Context, refreshToken string, device Device) (*TokenPair, error) {
+	claims, err := s.tokens.Verify(refreshToken, TokenRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetAccountByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	membership, err := s.membershipFor(ctx, user, claims.AccountID)
+	if errors.Is(err, ErrNotTeamMember) || errors.Is(err, ErrTwoFactorRequired) {
+		membership, err = s.membershipFor(ctx, user, user.ID.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := s.tokens.Issue(user, membership, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RotateSession(ctx, claims.SessionID, claims.ID, pair.refreshID, device, pair.RefreshExpiresAt); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+func (s *accountService) ListSessions(ctx context.Context, userID string) ([]Session, error) {
+	return s.repo.ListActiveSessions(ctx, userID)
+}
+
+// RevokeSession ends one of the user's sessions. Its refresh token stops
+// working immediately; access tokens already issued for it lapse within the
+// access token TTL.
+func (s *accountService) RevokeSession(ctx context.Context, userID string, sessionID string, reason string) error {
+	if reason == "" {
+		reason = RevokedByUser
+	}
+	return s.repo.RevokeSession(ctx, userID, sessionID, reason)
+}
+
+// RevokeAllSessions ends every active session of the user except
+// exceptSessionID, which may be empty, and returns how many it ended.
+func (s *accountService) RevokeAllSessions(ctx context.Context, userID string, exceptSessionID string) (int, error) {
+	return s.repo.RevokeAllSessions(ctx, userID, exceptSessionID, RevokedByUser)
+}
@@ -0,0 +1,136 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shridhar2104/logilo/internal/sqltest"
+
+	"github.com/google/uuid"
+)
+
+// sessionRepository keeps sessions in memory, rotating and revoking them the
+// way the sessions table does.
+type sessionRepository struct {
+	Repository
+	user     *Account
+	sessions map[string]*storedSession
+}
+
+type storedSession struct {
+	refreshID string
+	revoked   string
+}
+
+func (r *sessionRepository) GetAccountByID(ctx context.Context, id string) (*Account, error) {
+	return r.user, nil
+}
+
+func (r *sessionRepository) GetMembership(ctx context.Context, accountID, userID string) (*Membership, error) {
+	return &Membership{AccountID: accountID, UserID: userID, Role: RoleOwner}, nil
+}
+
+func (r *sessionRepository) RotateSession(ctx context.Context, sessionID, presentedID, nextID string, device Device, expiresAt time.Time) error {
+	s, ok := r.sessions[sessionID]
+	switch {
+	case !ok || s.revoked != "":
+		return ErrSessionRevoked
+	case s.refreshID != presentedID:
+		s.revoked = RevokedTokenReuse
+		return ErrRefreshTokenReused
+	}
+	s.refreshID = nextID
+	return nil
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	tokens, err := NewTokenManager("user secret", "", time.Minute, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &Account{ID: uuid.New()}
+	repo := &sessionRepository{user: user, sessions: map[string]*storedSession{}}
+	s := &accountService{repo: repo, tokens: tokens}
+
+	first, err := tokens.Issue(user, &Membership{AccountID: user.ID.String(), Role: RoleOwner}, "sess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.sessions["sess"] = &storedSession{refreshID: first.refreshID}
+
+	ctx := context.Background()
+	second, err := s.RefreshToken(ctx, first.RefreshToken, Device{})
+	if err != nil {
+		t.Fatalf("first rotation: %v", err)
+	}
+	third, err := s.RefreshToken(ctx, second.RefreshToken, Device{})
+	if err != nil {
+		t.Fatalf("second rotation: %v", err)
+	}
+	if third.SessionID != "sess" || third.refreshID == second.refreshID {
+		t.Fatalf("second rotation issued session %s with refresh id %s", third.SessionID, third.refreshID)
+	}
+
+	// Replaying a rotated token kills the session, so even the latest token
+	// stops working.
+	if _, err := s.RefreshToken(ctx, first.RefreshToken, Device{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("replayed refresh token: error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if got := repo.sessions["sess"].revoked; got != RevokedTokenReuse {
+		t.Errorf("session revoked for %q, want %q", got, RevokedTokenReuse)
+	}
+	if _, err := s.RefreshToken(ctx, third.RefreshToken, Device{}); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("latest refresh token after replay: error = %v, want %v", err, ErrSessionRevoked)
+	}
+
+	if _, err := s.RefreshToken(ctx, third.AccessToken, Device{}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("access token used to refresh: error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestRotateSession(t *testing.T) {
+	expires := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	device := Device{UserAgent: "curl/8.0", IP: "203.0.113.7"}
+
+	tests := []struct {
+		name    string
+		script  func(m *sqltest.Mock)
+		wantErr error
+	}{
+		{
+			name: "current token",
+			script: func(m *sqltest.Mock) {
+				m.ExpectExec("SET refresh_token_id = $3").WithArgs("sess", "old", "new", device.UserAgent, device.IP, expires).WillReturnResult(1)
+			},
+		},
+		{
+			name: "rotated token",
+			script: func(m *sqltest.Mock) {
+				m.ExpectExec("SET refresh_token_id = $3").WillReturnResult(0)
+				m.ExpectExec("SET revoked_at = NOW(), revoked_reason = $3").WithArgs("sess", "old", RevokedTokenReuse).WillReturnResult(1)
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+		{
+			name: "revoked or expired session",
+			script: func(m *sqltest.Mock) {
+				m.ExpectExec("SET refresh_token_id = $3").WillReturnResult(0)
+				m.ExpectExec("SET revoked_at = NOW(), revoked_reason = $3").WillReturnResult(0)
+			},
+			wantErr: ErrSessionRevoked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m := sqltest.Open(t)
+			tt.script(m)
+
+			r := &postgresRepository{db: db}
+			if err := r.RotateSession(context.Background(), "sess", "old", "new", device, expires); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RotateSession() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
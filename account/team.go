@@ -0,0 +1,263 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrInvitationNotFound = errors.New("invitation is invalid, expired or already used")
+	ErrTwoFactorRequired  = errors.New("this account requires two-factor authentication")
+)
+
+// Membership is a user's role in an account's team. Every account's own user
+// is the owner of its team; other users join by invitation.
+type Membership struct {
+	AccountID   string
+	AccountName string
+	UserID      string
+	Role        string
+	CreatedAt   time.Time
+}
+
+// Member is a team member as listed to the team.
+type Member struct {
+	Membership
+	Name  string
+	Email string
+}
+
+// Invitation invites an email address to join an account's team with a role.
+type Invitation struct {
+	ID        string
+	AccountID string
+	Email     string
+	Role      string
+	InvitedBy string
+	Hash      string // SHA-256 of the token emailed to the invitee.
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// InviteMember emails an invitation to join the account's team. Owners may
+// invite any role but owner; admins may invite ops, finance and viewers.
+func (s *accountService) InviteMember(ctx context.Context, actorID string, accountID string, email string, role string) (*Invitation, error) {
+	actor, err := s.authorize(ctx, accountID, actorID, PermissionManageTeam)
+	if err != nil {
+		return nil, err
+	}
+	if !ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if !canAssign(actor.Role, role) {
+		return nil, ErrPermissionDenied
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	inv := &Invitation{
+		AccountID: accountID,
+		Email:     strings.TrimSpace(email),
+		Role:      role,
+		InvitedBy: actorID,
+		Hash:      hashOneTimeToken(token),
+		ExpiresAt: time.Now().Add(invitationTTL),
+	}
+	if inv, err = s.repo.CreateInvitation(ctx, *inv); err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(ctx, Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("You're invited to join %s on Logilo", actor.AccountName),
+		Body: fmt.Sprintf("You have been invited to join %s on Logilo as %s.\n\nSign in or create an account with this email address, then accept the invitation:\n\n%s\n\nThe invitation expires in 7 days.\n",
+			actor.AccountName, role, s.link("/accept-invitation", token)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// ListInvitations returns the account's pending invitations.
+func (s *accountService) ListInvitations(ctx context.Context, actorID string, accountID string) ([]Invitation, error) {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageTeam); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvitations(ctx, accountID)
+}
+
+// RevokeInvitation withdraws a pending invitation.
+func (s *accountService) RevokeInvitation(ctx context.Context, actorID string, accountID string, invitationID string) error {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageTeam); err != nil {
+		return err
+	}
+	return s.repo.RevokeInvitation(ctx, accountID, invitationID)
+}
+
+// AcceptInvitation adds the signed-in user to the inviting team. The user's
+// verified email address must be the one the invitation was sent to.
+func (s *accountService) AcceptInvitation(ctx context.Context, actorID string, token string) (*Membership, error) {
+	user, err := s.repo.GetAccountByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+	return s.repo.AcceptInvitation(ctx, hashOneTimeToken(token), actorID, user.Email)
+}
+
+// ListMembers returns the account's team. Any member may see it.
+func (s *accountService) ListMembers(ctx context.Context, actorID string, accountID string) ([]Member, error) {
+	if _, err := s.repo.GetMembership(ctx, accountID, actorID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, accountID)
+}
+
+// SetMemberRole changes a member's role. The actor must be allowed to assign
+// both the member's current role and the new one, and cannot change their own.
+func (s *accountService) SetMemberRole(ctx context.Context, actorID string, accountID string, userID string, role string) error {
+	if !ValidRole(role) {
+		return ErrInvalidRole
+	}
+	actor, err := s.authorize(ctx, accountID, actorID, PermissionManageTeam)
+	if err != nil {
+		return err
+	}
+	member, err := s.repo.GetMembership(ctx, accountID, userID)
+	if err != nil {
+		return err
+	}
+	if actorID == userID || !canAssign(actor.Role, member.Role) || !canAssign(actor.Role, role) {
+		return ErrPermissionDenied
+	}
+	return s.repo.SetMemberRole(ctx, accountID, userID, role)
+}
+
+// RemoveMember removes a member from the team. Members may also remove
+// themselves; the owner cannot be removed. Tokens already issued to the member
+// for the account lapse within the access token TTL.
+func (s *accountService) RemoveMember(ctx context.Context, actorID string, accountID string, userID string) error {
+	member, err := s.repo.GetMembership(ctx, accountID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == RoleOwner {
+		return ErrPermissionDenied
+	}
+	if actorID != userID {
+		actor, err := s.authorize(ctx, accountID, actorID, PermissionManageTeam)
+		if err != nil {
+			return err
+		}
+		if !canAssign(actor.Role, member.Role) {
+			return ErrPermissionDenied
+		}
+	}
+	return s.repo.RemoveMember(ctx, accountID, userID)
+}
+
+// ListMemberships returns the teams the user belongs to, their own included.
+func (s *accountService) ListMemberships(ctx context.Context, userID string) ([]Membership, error) {
+	return s.repo.ListMemberships(ctx, userID)
+}
+
+// SwitchAccount reissues the session's tokens to act for another account the
+// user is a member of.
+func (s *accountService) SwitchAccount(ctx context.Context, userID string, sessionID string, accountID string) (*TokenPair, error) {
+	user, err := s.repo.GetAccountByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	membership, err := s.membershipFor(ctx, user, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := s.tokens.Issue(user, membership, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReissueSession(ctx, sessionID, userID, pair.refreshID, pair.RefreshExpiresAt); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// SetTwoFactorRequired requires, or stops requiring, every member of the team
+// to use two-factor authentication. Members without it cannot act for the
+// account. The actor must have it enabled to turn the requirement on.
+func (s *accountService) SetTwoFactorRequired(ctx context.Context, actorID string, accountID string, required bool) error {
+	if _, err := s.authorize(ctx, accountID, actorID, PermissionManageTeam); err != nil {
+		return err
+	}
+	if required {
+		enabled, err := s.twoFactorEnabled(ctx, actorID)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return ErrTwoFactorRequired
+		}
+	}
+	return s.repo.SetTwoFactorRequired(ctx, accountID, required)
+}
+
+// authorize returns the actor's membership in the account if its role grants
+// permission.
+func (s *accountService) authorize(ctx context.Context, accountID string, actorID string, permission Permission) (*Membership, error) {
+	m, err := s.repo.GetMembership(ctx, accountID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !HasPermission(m.Role, permission) {
+		return nil, ErrPermissionDenied
+	}
+	return m, nil
+}
+
+// membershipFor returns the user's membership in the account, checking the
+// team's two-factor requirement.
+func (s *accountService) membershipFor(ctx context.Context, user *Account, accountID string) (*Membership, error) {
+	m, err := s.repo.GetMembership(ctx, accountID, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	team := user
+	if accountID != user.ID.String() {
+		if team, err = s.repo.GetAccountByID(ctx, accountID); err != nil {
+			return nil, err
+		}
+	}
+	if team.TwoFactorRequired {
+		enabled, err := s.twoFactorEnabled(ctx, user.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			return nil, ErrTwoFactorRequired
+		}
+	}
+	return m, nil
+}
+
+func (s *accountService) twoFactorEnabled(ctx context.Context, userID string) (bool, error) {
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if errors.Is(err, ErrTwoFactorNotEnabled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return tf.EnabledAt != nil, nil
+}
@@ -0,0 +1,261 @@
+package account
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Token kinds. Access tokens authenticate requests; refresh tokens are only
+// exchanged for a new token pair.
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrTokenExpired     = errors.New("token has expired")
+	ErrNoStaffSecret    = errors.New("no staff token secret is configured")
+	ErrSharedStaffToken = errors.New("staff token secret must differ from the user token secret")
+)
+
+// tokenHeader is the encoded JOSE header of every token: HMAC-SHA256 signed JWTs.
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims are the verified contents of a token. A token is issued to a user
+// acting for one account, as a member of its team, or to one of the account's
+// API keys.
+type Claims struct {
+	ID        string `json:"jti"`
+	UserID    string `json:"sub,omitempty"`  // The signed-in user's own account.
+	AccountID string `json:"acct"`           // The account the user or key is acting for.
+	Role      string `json:"role,omitempty"` // The user's role in AccountID's team.
+	SessionID string `json:"sid,omitempty"`
+	Kind      string `json:"kind"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+
+	KeyID  string       `json:"kid,omitempty"` // Set instead of UserID for API keys.
+	Scopes []Permission `json:"scp,omitempty"` // What the API key or staff token may do, instead of a role.
+
+	// Staff is set instead of UserID and KeyID for tokens issued to Logilo's
+	// own staff and services. It names who the token was issued to; they act
+	// across accounts, so AccountID is empty.
+	Staff string `json:"stf,omitempty"`
+
+	// EmailVerified is set in access tokens once the user's email address is
+	// verified. Users pick it up on their next refresh after verifying. Role
+	// changes likewise apply from the next refresh.
+	EmailVerified bool `json:"ev,omitempty"`
+}
+
+// TokenPair is issued on login and on each refresh.
+type TokenPair struct {
+	SessionID        string
+	AccessToken      string
+	RefreshToken     string
+	AccessExpiresAt  time.Time
+	RefreshExpiresAt time.Time
+
+	refreshID string // The refresh token's jti, recorded on the session.
+}
+
+// TokenManager issues and verifies signed access and refresh tokens. The
+// account service issues tokens; the gateway only verifies them, with the same
+// secret. Staff and service tokens are signed with a separate secret that only
+// the backend services hold, so that the gateway can neither mint nor accept
+// them.
+type TokenManager struct {
+	secret      []byte
+	staffSecret []byte
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+}
+
+// NewTokenManager creates a TokenManager that signs user and API key tokens
+// with secret, and staff tokens with staffSecret. Either may be empty, and
+// tokens of that kind are then rejected.
+func NewTokenManager(secret string, staffSecret string, accessTTL time.Duration, refreshTTL time.Duration) (*TokenManager, error) {
+	if staffSecret != "" && staffSecret == secret {
+		return nil, ErrSharedStaffToken
+	}
+	return &TokenManager{secret: []byte(secret), staffSecret: []byte(staffSecret), accessTTL: accessTTL, refreshTTL: refreshTTL}, nil
+}
+
+// Issue signs a new access and refresh token for a session of user, acting
+// through membership.
+func (m *TokenManager) Issue(user *Account, membership *Membership, sessionID string) (*TokenPair, error) {
+	now := time.Now()
+	pair := &TokenPair{
+		SessionID:        sessionID,
+		AccessExpiresAt:  now.Add(m.accessTTL),
+		RefreshExpiresAt: now.Add(m.refreshTTL),
+		refreshID:        uuid.NewString(),
+	}
+
+	var err error
+	pair.AccessToken, err = m.sign(m.secret, Claims{
+		ID:        uuid.NewString(),
+		UserID:    user.ID.String(),
+		AccountID: membership.AccountID,
+		Role:      membership.Role,
+		SessionID: sessionID,
+		Kind:      TokenAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: pair.AccessExpiresAt.Unix(),
+
+		EmailVerified: user.EmailVerifiedAt != nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pair.RefreshToken, err = m.sign(m.secret, Claims{
+		ID:        pair.refreshID,
+		UserID:    user.ID.String(),
+		AccountID: membership.AccountID,
+		SessionID: sessionID,
+		Kind:      TokenRefresh,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: pair.RefreshExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Verify checks a token's signature, kind and expiry and returns its claims.
+// Staff tokens must be signed with the staff secret, and all others with the
+// user secret.
+func (m *TokenManager) Verify(token string, kind string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	signedByUser := len(m.secret) > 0 && hmac.Equal(sig, mac(m.secret, parts[0]+"."+parts[1]))
+	signedByStaff := len(m.staffSecret) > 0 && hmac.Equal(sig, mac(m.staffSecret, parts[0]+"."+parts[1]))
+	if !signedByUser && !signedByStaff {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	isUser := claims.UserID != "" && claims.SessionID != "" && claims.AccountID != "" && signedByUser
+	isKey := claims.KeyID != "" && claims.AccountID != "" && kind == TokenAccess && signedByUser
+	isStaff := claims.Staff != "" && claims.UserID == "" && claims.KeyID == "" && kind == TokenAccess && signedByStaff
+	if claims.Kind != kind || countTrue(isUser, isKey, isStaff) != 1 {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+// IssueForAPIKey signs an access token for an authenticated API key, valid
+// for ttl. emailVerified is whether the key's account has verified its address.
+func (m *TokenManager) IssueForAPIKey(key *APIKey, emailVerified bool, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	token, err := m.sign(m.secret, Claims{
+		ID:        uuid.NewString(),
+		AccountID: key.AccountID,
+		KeyID:     key.ID,
+		Scopes:    key.Scopes,
+		Kind:      TokenAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+
+		EmailVerified: emailVerified,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// IssueForStaff signs an access token for a member of staff or a service,
+// named by staff, granting the platform permissions in scopes for ttl.
+func (m *TokenManager) IssueForStaff(staff string, scopes []Permission, ttl time.Duration) (string, time.Time, error) {
+	if len(m.staffSecret) == 0 {
+		return "", time.Time{}, ErrNoStaffSecret
+	}
+	if staff == "" || len(scopes) == 0 {
+		return "", time.Time{}, ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !PlatformPermission(scope) {
+			return "", time.Time{}, ErrInvalidScope
+		}
+	}
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	token, err := m.sign(m.staffSecret, Claims{
+		ID:        uuid.NewString(),
+		Staff:     staff,
+		Scopes:    scopes,
+		Kind:      TokenAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Can reports whether the token grants permission: through the user's role
+// on its account, or the API key's or staff token's scopes.
+func (c *Claims) Can(permission Permission) bool {
+	if c.KeyID == "" && c.Staff == "" {
+		return HasPermission(c.Role, permission)
+	}
+	for _, p := range c.Scopes {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *TokenManager) sign(secret []byte, claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac(secret, unsigned)), nil
+}
+
+func mac(secret []byte, data string) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
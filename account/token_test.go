@@ -0,0 +1,138 @@
+package account
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTokenManagerVerify(t *testing.T) {
+	m, err := NewTokenManager("user secret", "staff secret", 15*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway, err := NewTokenManager("user secret", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &Account{ID: uuid.New()}
+	pair, err := m.Issue(user, &Membership{AccountID: "acct", Role: RoleOps}, "sess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _, err := m.IssueForAPIKey(&APIKey{ID: "key", AccountID: "acct", Scopes: []Permission{PermissionViewBilling}}, true, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staff, _, err := m.IssueForStaff("ops@logilo.in", []Permission{PermissionReviewKYC}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Unix()
+	userClaims := Claims{ID: "jti", UserID: user.ID.String(), AccountID: "acct", SessionID: "sess", Kind: TokenAccess, IssuedAt: now, ExpiresAt: now + 60}
+	forge := func(secret string, edit func(c *Claims)) string {
+		c := userClaims
+		edit(&c)
+		token, err := m.sign([]byte(secret), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	parts := strings.Split(pair.AccessToken, ".")
+
+	tests := []struct {
+		name    string
+		m       *TokenManager
+		token   string
+		kind    string
+		wantErr error
+	}{
+		{"access token", m, pair.AccessToken, TokenAccess, nil},
+		{"refresh token", m, pair.RefreshToken, TokenRefresh, nil},
+		{"api key token", m, key, TokenAccess, nil},
+		{"staff token", m, staff, TokenAccess, nil},
+		{"access token at the gateway", gateway, pair.AccessToken, TokenAccess, nil},
+
+		{"refresh token used for access", m, pair.RefreshToken, TokenAccess, ErrInvalidToken},
+		{"access token used to refresh", m, pair.AccessToken, TokenRefresh, ErrInvalidToken},
+		{"api key token used to refresh", m, forge("user secret", func(c *Claims) { c.UserID, c.SessionID, c.KeyID, c.Kind = "", "", "key", TokenRefresh }), TokenRefresh, ErrInvalidToken},
+		{"bad signature", m, forge("another secret", func(*Claims) {}), TokenAccess, ErrInvalidToken},
+		{"tampered payload", m, parts[0] + "." + strings.Split(staff, ".")[1] + "." + parts[2], TokenAccess, ErrInvalidToken},
+		{"unsigned", m, parts[0] + "." + parts[1] + ".", TokenAccess, ErrInvalidToken},
+		{"other algorithm", m, "eyJhbGciOiJub25lIn0." + parts[1] + "." + parts[2], TokenAccess, ErrInvalidToken},
+		{"not a token", m, "not-a-token", TokenAccess, ErrInvalidToken},
+		{"expired", m, forge("user secret", func(c *Claims) { c.ExpiresAt = now - 1 }), TokenAccess, ErrTokenExpired},
+		{"user and key", m, forge("user secret", func(c *Claims) { c.KeyID = "key" }), TokenAccess, ErrInvalidToken},
+		{"user and staff", m, forge("staff secret", func(c *Claims) { c.Staff = "ops@logilo.in" }), TokenAccess, ErrInvalidToken},
+		{"user without a session", m, forge("user secret", func(c *Claims) { c.SessionID = "" }), TokenAccess, ErrInvalidToken},
+		{"user signed with the staff secret", m, forge("staff secret", func(*Claims) {}), TokenAccess, ErrInvalidToken},
+		{"staff signed with the user secret", m, forge("user secret", func(c *Claims) { c.UserID, c.AccountID, c.SessionID, c.Staff = "", "", "", "ops@logilo.in" }), TokenAccess, ErrInvalidToken},
+		{"staff token at the gateway", gateway, staff, TokenAccess, ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.m.Verify(tt.token, tt.kind)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && claims.Kind != tt.kind {
+				t.Errorf("Verify() kind = %s, want %s", claims.Kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestTokenManagerIssueForStaff(t *testing.T) {
+	m, err := NewTokenManager("user secret", "staff secret", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway, err := NewTokenManager("user secret", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		m       *TokenManager
+		staff   string
+		scopes  []Permission
+		wantErr error
+	}{
+		{"platform scopes", m, "ops@logilo.in", []Permission{PermissionReviewKYC, PermissionAdminWallets}, nil},
+		{"no staff secret", gateway, "ops@logilo.in", []Permission{PermissionReviewKYC}, ErrNoStaffSecret},
+		{"merchant scope", m, "ops@logilo.in", []Permission{PermissionManageBilling}, ErrInvalidScope},
+		{"no scopes", m, "ops@logilo.in", nil, ErrInvalidScope},
+		{"no name", m, "", []Permission{PermissionReviewKYC}, ErrInvalidScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := tt.m.IssueForStaff(tt.staff, tt.scopes, time.Minute)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IssueForStaff() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			claims, err := tt.m.Verify(token, TokenAccess)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Staff != tt.staff || claims.AccountID != "" || !claims.Can(tt.scopes[0]) || claims.Can(PermissionInternal) {
+				t.Errorf("Verify() = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestNewTokenManagerRejectsSharedSecret(t *testing.T) {
+	if _, err := NewTokenManager("secret", "secret", 0, 0); !errors.Is(err, ErrSharedStaffToken) {
+		t.Errorf("NewTokenManager() error = %v, want %v", err, ErrSharedStaffToken)
+	}
+}
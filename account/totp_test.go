@@ -0,0 +1,105 @@
+package account
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors, base32 encoded.
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	key := []byte("12345678901234567890")
+	for _, tt := range tests {
+		if got := totpCode(key, tt.unix/30); got != tt.want {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTwoFactorValidate(t *testing.T) {
+	a, err := NewTwoFactorAuthenticator("Logilo", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / 30
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", rfc6238Secret, "050471", 0, step, true},
+		{"lower case secret", strings.ToLower(rfc6238Secret), "050471", 0, step, true},
+		{"previous step", rfc6238Secret, totpCode(key, step-1), 0, step - 1, true},
+		{"next step", rfc6238Secret, totpCode(key, step+1), 0, step + 1, true},
+		{"outside skew", rfc6238Secret, totpCode(key, step-2), 0, 0, false},
+		{"replayed", rfc6238Secret, "050471", step, 0, false},
+		{"later step after replay", rfc6238Secret, totpCode(key, step+1), step, step + 1, true},
+		{"wrong code", rfc6238Secret, "000000", 0, 0, false},
+		{"short code", rfc6238Secret, "50471", 0, 0, false},
+		{"malformed secret", "not base32!", "050471", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := a.Validate(tt.secret, tt.code, now, tt.lastStep)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTwoFactorSealedSecret(t *testing.T) {
+	a, err := NewTwoFactorAuthenticator("Logilo", "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, sealed, err := a.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 || strings.Contains(string(sealed), secret) {
+		t.Fatalf("NewSecret() = %q sealed as %x", secret, sealed)
+	}
+
+	opened, err := a.Open(sealed)
+	if err != nil || opened != secret {
+		t.Fatalf("Open() = %q, %v, want %q", opened, err, secret)
+	}
+
+	other, err := NewTwoFactorAuthenticator("Logilo", "other key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("Open() with another key succeeded")
+	}
+	if _, err := a.Open(sealed[:4]); err == nil {
+		t.Error("Open() of a truncated secret succeeded")
+	}
+}
+
+func TestNewTwoFactorAuthenticatorRequiresKey(t *testing.T) {
+	if _, err := NewTwoFactorAuthenticator("Logilo", ""); err == nil {
+		t.Error("NewTwoFactorAuthenticator() accepted an empty key")
+	}
+}
@@ -0,0 +1,241 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	loginChallengeTTL         = 5 * time.Minute
+	loginChallengeMaxAttempts = 5
+	recoveryCodeCount         = 10
+)
+
+var (
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotPending  = errors.New("two-factor enrollment has not been started")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("login challenge is invalid, expired or already used")
+)
+
+// TwoFactor is an account's TOTP enrollment. It takes effect at login once
+// EnabledAt is set; until then the secret is pending confirmation.
+type TwoFactor struct {
+	AccountID    string
+	SealedSecret []byte
+	EnabledAt    *time.Time
+	LastStep     int64 // The TOTP step of the last accepted code.
+}
+
+// TwoFactorEnrollment is what the account needs to add the secret to an
+// authenticator app.
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string // otpauth URI, shown as a QR code.
+}
+
+// LoginChallenge is issued instead of tokens when the account has two-factor
+// authentication enabled. The login completes with the challenge token and a
+// TOTP or recovery code.
+type LoginChallenge struct {
+	Token     string
+	AccountID string
+	ExpiresAt time.Time
+}
+
+// LoginResult is the outcome of a login: either tokens for a new session or a
+// challenge for the second factor.
+type LoginResult struct {
+	Account   *Account
+	Tokens    *TokenPair
+	Challenge *LoginChallenge
+}
+
+// BeginTwoFactorEnrollment generates a new pending TOTP secret for the account.
+// It replaces any earlier secret that was not confirmed.
+func (s *accountService) BeginTwoFactorEnrollment(ctx context.Context, accountID string) (*TwoFactorEnrollment, error) {
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetTwoFactor(ctx, accountID)
+	if err != nil && !errors.Is(err, ErrTwoFactorNotEnabled) {
+		return nil, err
+	}
+	if current != nil && current.EnabledAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, sealed, err := s.twoFactor.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.PutPendingTwoFactor(ctx, accountID, sealed); err != nil {
+		return nil, err
+	}
+	return &TwoFactorEnrollment{Secret: secret, URI: s.twoFactor.URI(account.Email, secret)}, nil
+}
+
+// ConfirmTwoFactorEnrollment enables two-factor authentication once the account
+// proves its authenticator app produces valid codes, and returns the account's
+// recovery codes. They are shown once; only their hashes are kept.
+func (s *accountService) ConfirmTwoFactorEnrollment(ctx context.Context, accountID string, code string) ([]string, error) {
+	tf, err := s.repo.GetTwoFactor(ctx, accountID)
+	if errors.Is(err, ErrTwoFactorNotEnabled) {
+		return nil, ErrTwoFactorNotPending
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tf.EnabledAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	step, err := s.checkTOTP(tf, code)
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTwoFactor(ctx, accountID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking a TOTP
+// or recovery code. Members of a team that requires it must keep it on.
+func (s *accountService) DisableTwoFactor(ctx context.Context, accountID string, code string) error {
+	required, err := s.repo.TwoFactorRequiredByTeams(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+	if err := s.checkSecondFactor(ctx, accountID, code); err != nil {
+		return err
+	}
+	return s.repo.DeleteTwoFactor(ctx, accountID)
+}
+
+// RegenerateRecoveryCodes replaces the account's recovery codes after checking
+// a TOTP or recovery code.
+func (s *accountService) RegenerateRecoveryCodes(ctx context.Context, accountID string, code string) ([]string, error) {
+	if err := s.checkSecondFactor(ctx, accountID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, accountID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CompleteLogin finishes a login that was challenged for a second factor and
+// starts the session. Each code tried uses up one of the challenge's attempts.
+func (s *accountService) CompleteLogin(ctx context.Context, challengeToken string, code string, device Device) (*LoginResult, error) {
+	hash := hashOneTimeToken(challengeToken)
+	accountID, err := s.repo.AttemptLoginChallenge(ctx, hash, loginChallengeMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSecondFactor(ctx, accountID, code); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CompleteLoginChallenge(ctx, hash); err != nil {
+		return nil, err
+	}
+
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := s.startSession(ctx, account, device)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Account: account, Tokens: pair}, nil
+}
+
+// challengeLogin issues a second-factor challenge for the account.
+func (s *accountService) challengeLogin(ctx context.Context, accountID string) (*LoginChallenge, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	challenge := &LoginChallenge{
+		Token:     token,
+		AccountID: accountID,
+		ExpiresAt: time.Now().Add(loginChallengeTTL),
+	}
+	if err := s.repo.CreateLoginChallenge(ctx, hashOneTimeToken(challenge.Token), accountID, challenge.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// checkSecondFactor accepts a current TOTP code or an unused recovery code of
+// an account with two-factor authentication enabled.
+func (s *accountService) checkSecondFactor(ctx context.Context, accountID string, code string) error {
+	tf, err := s.repo.GetTwoFactor(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if tf.EnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totpDigits {
+		step, err := s.checkTOTP(tf, code)
+		if err != nil {
+			return err
+		}
+		return s.repo.AdvanceTwoFactorStep(ctx, accountID, step)
+	}
+	return s.repo.UseRecoveryCode(ctx, accountID, hashOneTimeToken(normalizeRecoveryCode(code)))
+}
+
+// checkTOTP validates code against the enrollment's secret and returns the
+// step it matched.
+func (s *accountService) checkTOTP(tf *TwoFactor, code string) (int64, error) {
+	secret, err := s.twoFactor.Open(tf.SealedSecret)
+	if err != nil {
+		return 0, err
+	}
+	step, ok := s.twoFactor.Validate(secret, code, time.Now(), tf.LastStep)
+	if !ok {
+		return 0, ErrInvalidTwoFactorCode
+	}
+	return step, nil
+}
+
+// newRecoveryCodes generates recovery codes, formatted xxxxx-xxxxx, and their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		c := strings.ToLower(totpEncoding.EncodeToString(raw))[:10]
+		codes[i] = c[:5] + "-" + c[5:]
+		hashes[i] = hashOneTimeToken(normalizeRecoveryCode(codes[i]))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -4,6 +4,205 @@ CREATE TABLE IF NOT EXISTS accounts (
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL UNIQUE,
     password VARCHAR(255) NOT NULL,
+    email_verified_at TIMESTAMPTZ,
+    require_two_factor BOOLEAN NOT NULL DEFAULT FALSE, -- Team members must use two-factor authentication.
+    deleted_at TIMESTAMPTZ, -- Deleted accounts are kept but cannot log in.
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
+
+-- One row per login. refresh_token_id is the jti of the only refresh token the
+-- session currently accepts; it changes on every refresh.
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    refresh_token_id VARCHAR(36) NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revoked_reason VARCHAR(32)
+);
+
+CREATE INDEX IF NOT EXISTS sessions_active_idx ON sessions (account_id, last_used_at DESC) WHERE revoked_at IS NULL;
+
+-- Single-use tokens emailed for address verification and password resets.
+-- Only the SHA-256 hash of each token is stored.
+CREATE TABLE IF NOT EXISTS one_time_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    purpose VARCHAR(32) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS one_time_tokens_account_idx ON one_time_tokens (account_id, purpose, created_at);
+
+-- TOTP two-factor enrollments. The secret is sealed with AES-GCM by the
+-- service; enabled_at is NULL until the account confirms a code.
+CREATE TABLE IF NOT EXISTS account_two_factor (
+    account_id VARCHAR(36) PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    sealed_secret BYTEA NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    account_id VARCHAR(36) NOT NULL REFERENCES account_two_factor(account_id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    PRIMARY KEY (account_id, code_hash)
+);
+
+-- Logins waiting for a second factor.
+CREATE TABLE IF NOT EXISTS login_challenges (
+    token_hash CHAR(64) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ
+);
+
+-- Team membership. An account is both a user's login and a merchant team; each
+-- account's own user owns its team, and other users join it by invitation.
+CREATE TABLE IF NOT EXISTS team_members (
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'admin', 'ops', 'finance', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, user_id),
+    -- The owner is the account's own user and nobody else.
+    CHECK ((role = 'owner') = (account_id = user_id))
+);
+
+CREATE INDEX IF NOT EXISTS team_members_user_idx ON team_members (user_id);
+
+INSERT INTO team_members (account_id, user_id, role)
+SELECT id, id, 'owner' FROM accounts
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id UUID PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('admin', 'ops', 'finance', 'viewer')),
+    invited_by VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS invitations_pending_idx ON invitations (account_id) WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+-- API keys let a merchant's own systems act for the account. Only the hash of
+-- the key is stored.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL, -- The start of the key, shown in lists.
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    rate_limit INT NOT NULL CHECK (rate_limit > 0), -- Requests per minute.
+    created_by VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_account_idx ON api_keys (account_id) WHERE revoked_at IS NULL;
+
+-- Merchant business details and KYC review status. A row is created when the
+-- merchant first saves details, a logo or a KYC document.
+CREATE TABLE IF NOT EXISTS merchant_profiles (
+    account_id VARCHAR(36) PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    company_name VARCHAR(255) NOT NULL DEFAULT '',
+    gstin CHAR(15) NOT NULL DEFAULT '',
+    pan CHAR(10) NOT NULL DEFAULT '',
+    address_line1 VARCHAR(255) NOT NULL DEFAULT '',
+    address_line2 VARCHAR(255) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    state VARCHAR(100) NOT NULL DEFAULT '',
+    postal_code VARCHAR(6) NOT NULL DEFAULT '',
+    contact_phone VARCHAR(16) NOT NULL DEFAULT '',
+    brand_name VARCHAR(255) NOT NULL DEFAULT '', -- Printed on shipping labels.
+    logo_key VARCHAR(255) NOT NULL DEFAULT '', -- Blob store key; empty if there is no logo.
+    logo_content_type VARCHAR(64) NOT NULL DEFAULT '',
+    kyc_status VARCHAR(16) NOT NULL DEFAULT 'not_submitted'
+        CHECK (kyc_status IN ('not_submitted', 'pending', 'approved', 'rejected')),
+    kyc_submitted_at TIMESTAMPTZ,
+    kyc_reviewed_at TIMESTAMPTZ,
+    kyc_reviewed_by VARCHAR(255) NOT NULL DEFAULT '',
+    kyc_rejection_reason TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS merchant_profiles_kyc_idx ON merchant_profiles (kyc_status, kyc_submitted_at);
+
+-- Documents uploaded for KYC review. The files are kept in the blob store.
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    id UUID PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(64) NOT NULL,
+    size INT NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    uploaded_by VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS kyc_documents_account_idx ON kyc_documents (account_id, uploaded_at);
+
+-- Failed logins counted per email address ("email:<address>") and per client
+-- IP ("ip:<address>"), for lockouts.
+CREATE TABLE IF NOT EXISTS login_failures (
+    key VARCHAR(300) PRIMARY KEY,
+    failures INT NOT NULL,
+    last_failure_at TIMESTAMPTZ NOT NULL
+);
+
+-- Login attempts on each account. network is the /24 (IPv4) or /48 (IPv6) of
+-- the IP, which stands in for its location.
+CREATE TABLE IF NOT EXISTS login_events (
+    id UUID PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    network VARCHAR(64) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(32) NOT NULL DEFAULT '',
+    new_device BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS login_events_account_idx ON login_events (account_id, created_at DESC);
+
+-- Data export and erasure requests under the DPDP Act and the GDPR, run by a
+-- worker across every service. Rows outlive erasure as evidence it was done.
+CREATE TABLE IF NOT EXISTS data_subject_requests (
+    id UUID PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('export', 'erasure')),
+    status VARCHAR(16) NOT NULL CHECK (status IN ('pending', 'running', 'completed', 'failed', 'expired')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    retained TEXT[] NOT NULL DEFAULT '{}', -- For erasures, the records each service kept and why.
+    archive_key VARCHAR(255) NOT NULL DEFAULT '', -- Blob store key of an export's zip archive.
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ -- When an export's archive is deleted.
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS data_subject_requests_open_idx ON data_subject_requests (account_id, kind) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS data_subject_requests_due_idx ON data_subject_requests (next_attempt_at) WHERE status IN ('pending', 'running');
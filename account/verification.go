@@ -0,0 +1,171 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+)
+
+// Purposes of one-time tokens sent by email.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+// RevokedPasswordReset is the reason sessions are revoked when the password is reset.
+const RevokedPasswordReset = "password_reset"
+
+const (
+	verifyEmailTTL   = 24 * time.Hour
+	resetPasswordTTL = time.Hour
+
+	// At most oneTimeTokenLimit tokens of a purpose are sent to an account per
+	// oneTimeTokenWindow.
+	oneTimeTokenLimit  = 3
+	oneTimeTokenWindow = time.Hour
+
+	minPasswordLength = 8
+)
+
+var (
+	ErrInvalidOneTimeToken  = errors.New("token is invalid, expired or already used")
+	ErrTooManyRequests      = errors.New("too many requests; try again later")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+	ErrEmailNotVerified     = errors.New("email address has not been verified")
+	ErrWeakPassword         = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+)
+
+// OneTimeToken is a single-use, time-limited token emailed to an account. Only
+// the SHA-256 hash of the token is stored.
+type OneTimeToken struct {
+	AccountID string
+	Email     string // The address the token was sent to.
+	Purpose   string
+	Hash      string
+	ExpiresAt time.Time
+}
+
+// SendVerificationEmail emails the account a link that verifies its address.
+func (s *accountService) SendVerificationEmail(ctx context.Context, accountID string) error {
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issueOneTimeToken(ctx, account, PurposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, Message{
+		To:      account.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address to start booking shipments:\n\n%s\n\nThe link expires in 24 hours.\n",
+			account.Name, s.link("/verify-email", token)),
+	})
+}
+
+// VerifyEmail consumes a verification token and marks the address it was sent
+// to as verified. Tokens sent to an address the account no longer uses are
+// rejected.
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	t, err := s.repo.ConsumeOneTimeToken(ctx, PurposeVerifyEmail, hashOneTimeToken(token))
+	if err != nil {
+		return err
+	}
+	return s.repo.MarkEmailVerified(ctx, t.AccountID, t.Email)
+}
+
+// RequestPasswordReset emails a password-reset link to the account with the
+// address, if there is one. It reports success either way, so it cannot be
+// used to discover which addresses have accounts.
+func (s *accountService) RequestPasswordReset(ctx context.Context, email string) error {
+	account, err := s.repo.GetAccountByEmail(ctx, email)
+	if errors.Is(err, ErrAccountNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issueOneTimeToken(ctx, account, PurposeResetPassword, resetPasswordTTL)
+	if errors.Is(err, ErrTooManyRequests) {
+		log.Printf("Password reset rate limit reached for account %s", account.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, Message{
+		To:      account.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse this link to choose a new password:\n\n%s\n\nThe link expires in 1 hour. If you did not ask to reset your password, you can ignore this email.\n",
+			account.Name, s.link("/reset-password", token)),
+	})
+}
+
+// ResetPassword consumes a password-reset token, sets the new password and
+// signs the account out of every session.
+func (s *accountService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	if len(newPassword) < minPasswordLength {
+		return ErrWeakPassword
+	}
+
+	t, err := s.repo.ConsumeOneTimeToken(ctx, PurposeResetPassword, hashOneTimeToken(token))
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, t.AccountID, newPassword); err != nil {
+		return err
+	}
+	_, err = s.repo.RevokeAllSessions(ctx, t.AccountID, "", RevokedPasswordReset)
+	return err
+}
+
+// issueOneTimeToken generates a token, stores its hash and returns the token.
+func (s *accountService) issueOneTimeToken(ctx context.Context, account *Account, purpose string, ttl time.Duration) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.repo.CreateOneTimeToken(ctx, OneTimeToken{
+		AccountID: account.ID.String(),
+		Email:     account.Email,
+		Purpose:   purpose,
+		Hash:      hashOneTimeToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}, oneTimeTokenLimit, oneTimeTokenWindow)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// link returns the app URL at path carrying token.
+func (s *accountService) link(path string, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// randomToken returns 256 random bits, URL-safe encoded.
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashOneTimeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,56 @@
+package datasubject
+
+import (
+	"context"
+
+	"github.com/Shridhar2104/logilo/datasubject/pb"
+	"google.golang.org/grpc"
+)
+
+// maxExportSize bounds the files a service may return for one account.
+const maxExportSize = 256 << 20
+
+// Client calls a service's data-subject RPCs. It implements Provider.
+type Client struct {
+	conn    *grpc.ClientConn
+	service pb.DataSubjectServiceClient
+}
+
+// NewClient connects to the data-subject RPCs of the service at url. The RPCs
+// require a staff token, which opts must supply.
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithInsecure(), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxExportSize))}, opts...)
+	conn, err := grpc.Dial(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, service: pb.NewDataSubjectServiceClient(conn)}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+// ExportSubjectData returns the files the service holds about the account
+func (c *Client) ExportSubjectData(ctx context.Context, accountID string) ([]File, error) {
+	res, err := c.service.ExportSubjectData(ctx, &pb.ExportSubjectDataRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, len(res.Files))
+	for i, f := range res.Files {
+		files[i] = File{Name: f.Name, Content: f.Content}
+	}
+	return files, nil
+}
+
+// EraseSubjectData erases or anonymizes the service's data about the account
+func (c *Client) EraseSubjectData(ctx context.Context, accountID string) (*Erasure, error) {
+	res, err := c.service.EraseSubjectData(ctx, &pb.EraseSubjectDataRequest{AccountId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return &Erasure{Erased: res.Erased, Retained: res.Retained}, nil
+}
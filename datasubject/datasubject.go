@@ -0,0 +1,77 @@
+// Package datasubject is the interface every service implements so that a
+// merchant's data can be exported or erased across the platform, as the DPDP
+// Act and the GDPR require. The account service orchestrates the requests.
+package datasubject
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// File is one file of a data export.
+type File struct {
+	Name    string // Path within the service's folder of the archive, e.g. "orders.json".
+	Content []byte
+}
+
+// Erasure reports what a service did with a data subject's data.
+type Erasure struct {
+	Erased   []string // What was deleted or anonymized.
+	Retained []string // What was kept, and the law requiring it.
+}
+
+// Provider exports and erases the data a service holds about an account.
+// Erasure must be safe to repeat, since failed requests are retried.
+type Provider interface {
+	ExportSubjectData(ctx context.Context, accountID string) ([]File, error)
+	EraseSubjectData(ctx context.Context, accountID string) (*Erasure, error)
+}
+
+// Participant is a service taking part in data-subject requests.
+type Participant struct {
+	Name     string // Folder of the service's files in the archive.
+	Provider Provider
+}
+
+// Combine returns a Provider for a service whose data is split across several
+// providers. They export in order and erase in order, stopping at the first
+// error.
+func Combine(providers ...Provider) Provider {
+	return combined(providers)
+}
+
+type combined []Provider
+
+func (c combined) ExportSubjectData(ctx context.Context, accountID string) ([]File, error) {
+	var files []File
+	for _, p := range c {
+		f, err := p.ExportSubjectData(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f...)
+	}
+	return files, nil
+}
+
+func (c combined) EraseSubjectData(ctx context.Context, accountID string) (*Erasure, error) {
+	var erasure Erasure
+	for _, p := range c {
+		e, err := p.EraseSubjectData(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		erasure.Erased = append(erasure.Erased, e.Erased...)
+		erasure.Retained = append(erasure.Retained, e.Retained...)
+	}
+	return &erasure, nil
+}
+
+// JSONFile returns a file holding v as indented JSON.
+func JSONFile(name string, v any) (File, error) {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return File{}, err
+	}
+	return File{Name: name, Content: content}, nil
+}
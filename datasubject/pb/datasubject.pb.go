@@ -0,0 +1,362 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.2
+// 	protoc        v5.29.0
+// source: datasubject.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ExportSubjectDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId string `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+}
+
+func (x *ExportSubjectDataRequest) Reset() {
+	*x = ExportSubjectDataRequest{}
+	mi := &file_datasubject_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportSubjectDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportSubjectDataRequest) ProtoMessage() {}
+
+func (x *ExportSubjectDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_datasubject_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportSubjectDataRequest.ProtoReflect.Descriptor instead.
+func (*ExportSubjectDataRequest) Descriptor() ([]byte, []int) {
+	return file_datasubject_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExportSubjectDataRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+// SubjectFile is one file of an export, named relative to the service's
+// folder in the archive.
+type SubjectFile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Content []byte `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+}
+
+func (x *SubjectFile) Reset() {
+	*x = SubjectFile{}
+	mi := &file_datasubject_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubjectFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubjectFile) ProtoMessage() {}
+
+func (x *SubjectFile) ProtoReflect() protoreflect.Message {
+	mi := &file_datasubject_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubjectFile.ProtoReflect.Descriptor instead.
+func (*SubjectFile) Descriptor() ([]byte, []int) {
+	return file_datasubject_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubjectFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SubjectFile) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type ExportSubjectDataResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Files []*SubjectFile `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+}
+
+func (x *ExportSubjectDataResponse) Reset() {
+	*x = ExportSubjectDataResponse{}
+	mi := &file_datasubject_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportSubjectDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportSubjectDataResponse) ProtoMessage() {}
+
+func (x *ExportSubjectDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_datasubject_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportSubjectDataResponse.ProtoReflect.Descriptor instead.
+func (*ExportSubjectDataResponse) Descriptor() ([]byte, []int) {
+	return file_datasubject_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExportSubjectDataResponse) GetFiles() []*SubjectFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type EraseSubjectDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId string `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+}
+
+func (x *EraseSubjectDataRequest) Reset() {
+	*x = EraseSubjectDataRequest{}
+	mi := &file_datasubject_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseSubjectDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseSubjectDataRequest) ProtoMessage() {}
+
+func (x *EraseSubjectDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_datasubject_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseSubjectDataRequest.ProtoReflect.Descriptor instead.
+func (*EraseSubjectDataRequest) Descriptor() ([]byte, []int) {
+	return file_datasubject_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EraseSubjectDataRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+type EraseSubjectDataResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Erased   []string `protobuf:"bytes,1,rep,name=erased,proto3" json:"erased,omitempty"`     // What was deleted or anonymized.
+	Retained []string `protobuf:"bytes,2,rep,name=retained,proto3" json:"retained,omitempty"` // What was kept, and the law requiring it.
+}
+
+func (x *EraseSubjectDataResponse) Reset() {
+	*x = EraseSubjectDataResponse{}
+	mi := &file_datasubject_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseSubjectDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseSubjectDataResponse) ProtoMessage() {}
+
+func (x *EraseSubjectDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_datasubject_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseSubjectDataResponse.ProtoReflect.Descriptor instead.
+func (*EraseSubjectDataResponse) Descriptor() ([]byte, []int) {
+	return file_datasubject_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EraseSubjectDataResponse) GetErased() []string {
+	if x != nil {
+		return x.Erased
+	}
+	return nil
+}
+
+func (x *EraseSubjectDataResponse) GetRetained() []string {
+	if x != nil {
+		return x.Retained
+	}
+	return nil
+}
+
+var File_datasubject_proto protoreflect.FileDescriptor
+
+var file_datasubject_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x64, 0x61, 0x74, 0x61, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0x39, 0x0a, 0x18, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x49, 0x64, 0x22, 0x3b, 0x0a, 0x0b, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x46, 0x69, 0x6c,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22,
+	0x42, 0x0a, 0x19, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x25, 0x0a, 0x05,
+	0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x62,
+	0x2e, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x22, 0x38, 0x0a, 0x17, 0x45, 0x72, 0x61, 0x73, 0x65, 0x53, 0x75, 0x62, 0x6a,
+	0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x22, 0x4e, 0x0a,
+	0x18, 0x45, 0x72, 0x61, 0x73, 0x65, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74,
+	0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72, 0x61,
+	0x73, 0x65, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72, 0x61, 0x73, 0x65,
+	0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x64, 0x32, 0xb5, 0x01,
+	0x0a, 0x12, 0x44, 0x61, 0x74, 0x61, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x50, 0x0a, 0x11, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x75,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4d, 0x0a, 0x10, 0x45, 0x72, 0x61, 0x73, 0x65, 0x53,
+	0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1b, 0x2e, 0x70, 0x62, 0x2e,
+	0x45, 0x72, 0x61, 0x73, 0x65, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x72, 0x61,
+	0x73, 0x65, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x05, 0x5a, 0x03, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_datasubject_proto_rawDescOnce sync.Once
+	file_datasubject_proto_rawDescData = file_datasubject_proto_rawDesc
+)
+
+func file_datasubject_proto_rawDescGZIP() []byte {
+	file_datasubject_proto_rawDescOnce.Do(func() {
+		file_datasubject_proto_rawDescData = protoimpl.X.CompressGZIP(file_datasubject_proto_rawDescData)
+	})
+	return file_datasubject_proto_rawDescData
+}
+
+var file_datasubject_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_datasubject_proto_goTypes = []any{
+	(*ExportSubjectDataRequest)(nil),  // 0: pb.ExportSubjectDataRequest
+	(*SubjectFile)(nil),               // 1: pb.SubjectFile
+	(*ExportSubjectDataResponse)(nil), // 2: pb.ExportSubjectDataResponse
+	(*EraseSubjectDataRequest)(nil),   // 3: pb.EraseSubjectDataRequest
+	(*EraseSubjectDataResponse)(nil),  // 4: pb.EraseSubjectDataResponse
+}
+var file_datasubject_proto_depIdxs = []int32{
+	1, // 0: pb.ExportSubjectDataResponse.files:type_name -> pb.SubjectFile
+	0, // 1: pb.DataSubjectService.ExportSubjectData:input_type -> pb.ExportSubjectDataRequest
+	3, // 2: pb.DataSubjectService.EraseSubjectData:input_type -> pb.EraseSubjectDataRequest
+	2, // 3: pb.DataSubjectService.ExportSubjectData:output_type -> pb.ExportSubjectDataResponse
+	4, // 4: pb.DataSubjectService.EraseSubjectData:output_type -> pb.EraseSubjectDataResponse
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_datasubject_proto_init() }
+func file_datasubject_proto_init() {
+	if File_datasubject_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_datasubject_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_datasubject_proto_goTypes,
+		DependencyIndexes: file_datasubject_proto_depIdxs,
+		MessageInfos:      file_datasubject_proto_msgTypes,
+	}.Build()
+	File_datasubject_proto = out.File
+	file_datasubject_proto_rawDesc = nil
+	file_datasubject_proto_goTypes = nil
+	file_datasubject_proto_depIdxs = nil
+}
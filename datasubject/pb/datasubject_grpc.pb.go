@@ -0,0 +1,165 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.0
+// source: datasubject.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DataSubjectService_ExportSubjectData_FullMethodName = "/pb.DataSubjectService/ExportSubjectData"
+	DataSubjectService_EraseSubjectData_FullMethodName  = "/pb.DataSubjectService/EraseSubjectData"
+)
+
+// DataSubjectServiceClient is the client API for DataSubjectService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DataSubjectService is served by every service that holds data about
+// merchants, so that DPDP and GDPR requests can reach all of it.
+type DataSubjectServiceClient interface {
+	ExportSubjectData(ctx context.Context, in *ExportSubjectDataRequest, opts ...grpc.CallOption) (*ExportSubjectDataResponse, error)
+	EraseSubjectData(ctx context.Context, in *EraseSubjectDataRequest, opts ...grpc.CallOption) (*EraseSubjectDataResponse, error)
+}
+
+type dataSubjectServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDataSubjectServiceClient(cc grpc.ClientConnInterface) DataSubjectServiceClient {
+	return &dataSubjectServiceClient{cc}
+}
+
+func (c *dataSubjectServiceClient) ExportSubjectData(ctx context.Context, in *ExportSubjectDataRequest, opts ...grpc.CallOption) (*ExportSubjectDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportSubjectDataResponse)
+	err := c.cc.Invoke(ctx, DataSubjectService_ExportSubjectData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataSubjectServiceClient) EraseSubjectData(ctx context.Context, in *EraseSubjectDataRequest, opts ...grpc.CallOption) (*EraseSubjectDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EraseSubjectDataResponse)
+	err := c.cc.Invoke(ctx, DataSubjectService_EraseSubjectData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DataSubjectServiceServer is the server API for DataSubjectService service.
+// All implementations must embed UnimplementedDataSubjectServiceServer
+// for forward compatibility.
+//
+// DataSubjectService is served by every service that holds data about
+// merchants, so that DPDP and GDPR requests can reach all of it.
+type DataSubjectServiceServer interface {
+	ExportSubjectData(context.Context, *ExportSubjectDataRequest) (*ExportSubjectDataResponse, error)
+	EraseSubjectData(context.Context, *EraseSubjectDataRequest) (*EraseSubjectDataResponse, error)
+	mustEmbedUnimplementedDataSubjectServiceServer()
+}
+
+// UnimplementedDataSubjectServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDataSubjectServiceServer struct{}
+
+func (UnimplementedDataSubjectServiceServer) ExportSubjectData(context.Context, *ExportSubjectDataRequest) (*ExportSubjectDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportSubjectData not implemented")
+}
+func (UnimplementedDataSubjectServiceServer) EraseSubjectData(context.Context, *EraseSubjectDataRequest) (*EraseSubjectDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EraseSubjectData not implemented")
+}
+func (UnimplementedDataSubjectServiceServer) mustEmbedUnimplementedDataSubjectServiceServer() {}
+func (UnimplementedDataSubjectServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeDataSubjectServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DataSubjectServiceServer will
+// result in compilation errors.
+type UnsafeDataSubjectServiceServer interface {
+	mustEmbedUnimplementedDataSubjectServiceServer()
+}
+
+func RegisterDataSubjectServiceServer(s grpc.ServiceRegistrar, srv DataSubjectServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDataSubjectServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DataSubjectService_ServiceDesc, srv)
+}
+
+func _DataSubjectService_ExportSubjectData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportSubjectDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSubjectServiceServer).ExportSubjectData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSubjectService_ExportSubjectData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSubjectServiceServer).ExportSubjectData(ctx, req.(*ExportSubjectDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataSubjectService_EraseSubjectData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EraseSubjectDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataSubjectServiceServer).EraseSubjectData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataSubjectService_EraseSubjectData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataSubjectServiceServer).EraseSubjectData(ctx, req.(*EraseSubjectDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DataSubjectService_ServiceDesc is the grpc.ServiceDesc for DataSubjectService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DataSubjectService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.DataSubjectService",
+	HandlerType: (*DataSubjectServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExportSubjectData",
+			Handler:    _DataSubjectService_ExportSubjectData_Handler,
+		},
+		{
+			MethodName: "EraseSubjectData",
+			Handler:    _DataSubjectService_EraseSubjectData_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "datasubject.proto",
+}
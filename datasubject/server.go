@@ -0,0 +1,44 @@
+package datasubject
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Shridhar2104/logilo/datasubject/pb"
+	"google.golang.org/grpc"
+)
+
+type grpcServer struct {
+	pb.UnimplementedDataSubjectServiceServer
+	provider Provider
+}
+
+// Register serves provider's data-subject RPCs on server. The RPCs are only
+// called by the account service and are not exposed through the gateway.
+func Register(server *grpc.Server, provider Provider) {
+	pb.RegisterDataSubjectServiceServer(server, &grpcServer{provider: provider})
+}
+
+func (s *grpcServer) ExportSubjectData(ctx context.Context, r *pb.ExportSubjectDataRequest) (*pb.ExportSubjectDataResponse, error) {
+	files, err := s.provider.ExportSubjectData(ctx, r.AccountId)
+	if err != nil {
+		log.Printf("Error while exporting data of account %s: %v", r.AccountId, err)
+		return nil, fmt.Errorf("error while exporting subject data: %w", err)
+	}
+
+	res := &pb.ExportSubjectDataResponse{Files: make([]*pb.SubjectFile, len(files))}
+	for i, f := range files {
+		res.Files[i] = &pb.SubjectFile{Name: f.Name, Content: f.Content}
+	}
+	return res, nil
+}
+
+func (s *grpcServer) EraseSubjectData(ctx context.Context, r *pb.EraseSubjectDataRequest) (*pb.EraseSubjectDataResponse, error) {
+	erasure, err := s.provider.EraseSubjectData(ctx, r.AccountId)
+	if err != nil {
+		log.Printf("Error while erasing data of account %s: %v", r.AccountId, err)
+		return nil, fmt.Errorf("error while erasing subject data: %w", err)
+	}
+	return &pb.EraseSubjectDataResponse{Erased: erasure.Erased, Retained: erasure.Retained}, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Shridhar2104/logilo/account"
+)
+
+// apiKeyCacheTTL is how long an authenticated API key is trusted before the
+// account service is asked again. A revoked key stops working within it.
+const apiKeyCacheTTL = time.Minute
+
+type cachedAPIKey struct {
+	claims      *account.Claims
+	accessToken string
+	rateLimit   int
+	expiresAt   time.Time
+}
+
+// apiKeyAuthenticator exchanges API keys for access tokens with the account
+// service, caches them, and limits each key's request rate.
+type apiKeyAuthenticator struct {
+	client *account.Client
+	tokens *account.TokenManager
+
+	mu      sync.Mutex
+	cache   map[string]cachedAPIKey // By key.
+	buckets map[string]*rateBucket  // By key id.
+}
+
+func newAPIKeyAuthenticator(client *account.Client, tokens *account.TokenManager) *apiKeyAuthenticator {
+	return &apiKeyAuthenticator{
+		client:  client,
+		tokens:  tokens,
+		cache:   make(map[string]cachedAPIKey),
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// authenticate returns the claims of the access token the key is exchanged
+// for, the token itself and the key's rate limit.
+func (a *apiKeyAuthenticator) authenticate(ctx context.Context, secret string) (cachedAPIKey, error) {
+	now := time.Now()
+	a.mu.Lock()
+	cached, ok := a.cache[secret]
+	a.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	t, err := a.client.AuthenticateAPIKey(ctx, secret)
+	if err != nil {
+		return cachedAPIKey{}, err
+	}
+	claims, err := a.tokens.Verify(t.AccessToken, account.TokenAccess)
+	if err != nil {
+		return cachedAPIKey{}, err
+	}
+
+	cached = cachedAPIKey{claims: claims, accessToken: t.AccessToken, rateLimit: t.Key.RateLimit, expiresAt: now.Add(apiKeyCacheTTL)}
+	if t.ExpiresAt.Before(cached.expiresAt) {
+		cached.expiresAt = t.ExpiresAt
+	}
+	a.mu.Lock()
+	for k, c := range a.cache {
+		if !now.Before(c.expiresAt) {
+			delete(a.cache, k)
+		}
+	}
+	a.cache[secret] = cached
+	a.mu.Unlock()
+	return cached, nil
+}
+
+// allow reports whether the key may make another request now, and if not, how
+// long until it may. Limits are counted per gateway instance.
+func (a *apiKeyAuthenticator) allow(keyID string, perMinute int) (bool, time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	b, ok := a.buckets[keyID]
+	if !ok || b.perMinute != perMinute {
+		b = newRateBucket(perMinute)
+		a.buckets[keyID] = b
+	}
+	return b.take(time.Now())
+}
+
+// rateBucket is a token bucket holding up to a minute's worth of requests and
+// refilling continuously.
+type rateBucket struct {
+	perMinute int
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newRateBucket(perMinute int) *rateBucket {
+	return &rateBucket{perMinute: perMinute, tokens: float64(perMinute), updatedAt: time.Now()}
+}
+
+func (b *rateBucket) take(now time.Time) (bool, time.Duration) {
+	rate := float64(b.perMinute) / time.Minute.Seconds()
+	b.tokens += now.Sub(b.updatedAt).Seconds() * rate
+	if b.tokens > float64(b.perMinute) {
+		b.tokens = float64(b.perMinute)
+	}
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateBucketTake(t *testing.T) {
+	start := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	type take struct {
+		after     time.Duration // Since start.
+		wantOK    bool
+		wantRetry time.Duration
+	}
+	tests := []struct {
+		name      string
+		perMinute int
+		takes     []take
+	}{
+		{
+			name:      "burst up to the limit",
+			perMinute: 3,
+			takes:     []take{{0, true, 0}, {0, true, 0}, {0, true, 0}, {0, false, 20 * time.Second}},
+		},
+		{
+			name:      "refills continuously",
+			perMinute: 60,
+			takes:     append(repeat(take{0, true, 0}, 60), take{0, false, time.Second}, take{500 * time.Millisecond, false, 500 * time.Millisecond}, take{time.Second, true, 0}),
+		},
+		{
+			name:      "refill is capped at a minute's worth",
+			perMinute: 2,
+			takes:     []take{{0, true, 0}, {time.Hour, true, 0}, {time.Hour, true, 0}, {time.Hour, false, 30 * time.Second}},
+		},
+		{
+			name:      "partial refill shortens the wait",
+			perMinute: 1,
+			takes:     []take{{0, true, 0}, {15 * time.Second, false, 45 * time.Second}, {time.Minute, true, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &rateBucket{perMinute: tt.perMinute, tokens: float64(tt.perMinute), updatedAt: start}
+			for i, tk := range tt.takes {
+				ok, retry := b.take(start.Add(tk.after))
+				if ok != tk.wantOK || (retry-tk.wantRetry).Abs() > time.Millisecond {
+					t.Fatalf("take %d at +%v = (%v, %v), want (%v, %v)", i, tk.after, ok, retry, tk.wantOK, tk.wantRetry)
+				}
+			}
+		})
+	}
+}
+
+func repeat[T any](v T, n int) []T {
+	s := make([]T, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
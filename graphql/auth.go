@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/Shridhar2104/logilo/account"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrUserRequired    = errors.New("this operation requires a signed-in user, not an api key")
+)
+
+type callerKey struct{}
+
+type deviceKey struct{}
+
+// authMiddleware verifies the bearer access token of each request and puts the
+// caller's claims into the request context, along with the device the request
+// came from. The token is also forwarded to the services, whose interceptors
+// check it again. API keys are accepted as bearer tokens too; they are
+// exchanged for access tokens and rate limited per key. Requests without a
+// token pass through so that createAccount, login and refreshToken can be
+// called; the resolvers that need a caller reject them.
+func authMiddleware(tokens *account.TokenManager, keys *apiKeyAuthenticator, proxies trustedProxies, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), deviceKey{}, requestDevice(r, proxies)))
+
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			http.Error(w, "malformed authorization header", http.StatusUnauthorized)
+			return
+		}
+
+		if strings.HasPrefix(token, account.APIKeyPrefix) {
+			key, err := keys.authenticate(r.Context(), token)
+			if err != nil {
+				http.Error(w, account.ErrInvalidAPIKey.Error(), http.StatusUnauthorized)
+				return
+			}
+			if ok, wait := keys.allow(key.claims.KeyID, key.rateLimit); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, "api key rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			ctx := context.WithValue(r.Context(), callerKey{}, key.claims)
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key.accessToken)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		claims, err := tokens.Verify(token, account.TokenAccess)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), callerKey{}, claims)
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", header)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestDevice describes the client of r.
+func requestDevice(r *http.Request, proxies trustedProxies) account.Device {
+	return account.Device{UserAgent: r.UserAgent(), IP: proxies.clientIP(r)}
+}
+
+// trustedProxies are the networks of the proxies in front of the gateway. Only
+// they are believed about the addresses they forward requests for.
+type trustedProxies []netip.Prefix
+
+// parseTrustedProxies parses IP addresses and CIDR ranges.
+func parseTrustedProxies(values []string) (trustedProxies, error) {
+	var proxies trustedProxies
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, err
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+func (p trustedProxies) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address of r's client. X-Forwarded-For is only used
+// when the peer is a trusted proxy, and then read from the right, since each
+// proxy appends the address it received the request from: the client is the
+// rightmost address that is not a trusted proxy. Addresses further left were
+// sent by the client and could be forged.
+func (p trustedProxies) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !p.contains(peer) {
+		return host
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	ip := peer.Unmap().String()
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		ip = addr.Unmap().String()
+		if !p.contains(addr) {
+			break
+		}
+	}
+	return ip
+}
+
+// callerClaims returns the verified access token claims of the caller.
+func callerClaims(ctx context.Context) (*account.Claims, error) {
+	claims, ok := ctx.Value(callerKey{}).(*account.Claims)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return claims, nil
+}
+
+// userClaims returns the claims of a signed-in user. API keys cannot manage
+// sessions, security settings, the team or other keys.
+func userClaims(ctx context.Context) (*account.Claims, error) {
+	claims, err := callerClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if claims.UserID == "" {
+		return nil, ErrUserRequired
+	}
+	return claims, nil
+}
+
+// callerID returns the user id of the authenticated caller, for the caller's
+// own sessions, email and two-factor settings.
+func callerID(ctx context.Context) (string, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
+}
+
+// authorize returns the id of the account the caller is acting for if the
+// caller's role in its team, or their API key's scopes, grant permission.
+func authorize(ctx context.Context, permission account.Permission) (string, error) {
+	claims, err := callerClaims(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !claims.Can(permission) {
+		return "", account.ErrPermissionDenied
+	}
+	return claims.AccountID, nil
+}
+
+// callerDevice returns the device the request came from.
+func callerDevice(ctx context.Context) account.Device {
+	device, _ := ctx.Value(deviceKey{}).(account.Device)
+	return device
+}
@@ -3,15 +3,19 @@ package main
 import (
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/Shridhar2104/logilo/account"
+	"github.com/Shridhar2104/logilo/payment"
+	"github.com/Shridhar2104/logilo/payment/invoice"
 	"github.com/Shridhar2104/logilo/shopify"
 )
 
 type Server struct {
 	accountClient *account.Client
 	shopifyClient *shopify.Client
+	paymentClient *payment.Client
+	invoiceClient *invoice.Client
 }
 
-func NewGraphQLServer(accountUrl, shopifyUrl string) (*Server, error) {
+func NewGraphQLServer(accountUrl, shopifyUrl, paymentUrl string) (*Server, error) {
 
 	accountClient, err := account.NewClient(accountUrl)
 	if err != nil {
@@ -24,7 +28,22 @@ func NewGraphQLServer(accountUrl, shopifyUrl string) (*Server, error) {
 		return nil, err
 	}
 
-	return &Server{accountClient, shopifyClient}, nil
+	paymentClient, err := payment.NewClient(paymentUrl)
+	if err != nil {
+		accountClient.Close()
+		shopifyClient.Close()
+		return nil, err
+	}
+
+	invoiceClient, err := invoice.NewClient(paymentUrl)
+	if err != nil {
+		accountClient.Close()
+		shopifyClient.Close()
+		paymentClient.Close()
+		return nil, err
+	}
+
+	return &Server{accountClient, shopifyClient, paymentClient, invoiceClient}, nil
 }
 func(s *Server) Mutation() MutationResolver {
 	return &mutationResolver{s}
@@ -40,6 +59,10 @@ func(s *Server) Account() AccountResolver {
 	return &accountResolver{s}
 }
 
+func(s *Server) Wallet() WalletResolver {
+	return &walletResolver{s}
+}
+
 func(s *Server) ToNewExecutableSchema() graphql.ExecutableSchema {
 	return NewExecutableSchema(Config{
 		Resolvers: s,
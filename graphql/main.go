@@ -7,12 +7,16 @@ import (
 
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/99designs/gqlgen/handler"
+	"github.com/Shridhar2104/logilo/account"
 	"github.com/kelseyhightower/envconfig"
 )
 
 type AppConfig struct {
 	AccountURL string `envconfig:"ACCOUNT_URL" required:"true"`
 	ShopifyURL string `envconfig:"SHOPIFY_URL" required:"true"`
+	PaymentURL string `envconfig:"PAYMENT_URL" required:"true"`
+	TokenSecret string `envconfig:"AUTH_TOKEN_SECRET" required:"true"`
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"` // Addresses or CIDR ranges of proxies whose X-Forwarded-For is believed
 	Port       string `envconfig:"PORT" default:"8084"` 
 }
 
@@ -30,13 +34,24 @@ func main() {
 	}
 
 	// Create a new GraphQL server
-	server, err := NewGraphQLServer(config.AccountURL, config.ShopifyURL)
+	server, err := NewGraphQLServer(config.AccountURL, config.ShopifyURL, config.PaymentURL)
 	if err != nil {
 		log.Fatalf("Failed to create GraphQL server: %v", err)
 	}
 
 	// Set up HTTP routes
-	http.Handle("/graphql", handler.GraphQL(server.ToNewExecutableSchema()))
+	// The gateway only verifies tokens, so the TTLs are unused. It never holds
+	// the staff token secret, so staff tokens are rejected at the gateway.
+	tokens, err := account.NewTokenManager(config.TokenSecret, "", 0, 0)
+	if err != nil {
+		log.Fatalf("Failed to set up tokens: %v", err)
+	}
+	keys := newAPIKeyAuthenticator(server.accountClient, tokens)
+	proxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	http.Handle("/graphql", authMiddleware(tokens, keys, proxies, handler.GraphQL(server.ToNewExecutableSchema())))
 	http.Handle("/playground", playground.Handler("GraphQL Playground", "/graphql"))
 	http.Handle("/health", http.HandlerFunc(healthHandler))
 
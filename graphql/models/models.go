@@ -5,8 +5,8 @@ import "time"
 type Account struct {
 	ID string `json:"id"`
 	Name string `json:"name"`
-	Password string `json:"password"`
 	Email string `json:"email"`
+	EmailVerified bool `json:"emailVerified"`
 	Orders []Order `json:"orders"`
 	ShopNames []ShopName `json:"shopnames"`
 }
@@ -37,4 +37,16 @@ type OrderInput struct {
 	AccountID string `json:"accountId"`
 	Amount float64 `json:"amount"`
 	Description string `json:"description"`
-}
\ No newline at end of file
+}
+
+type Wallet struct {
+	AccountID string `json:"accountId"`
+	Currency string `json:"currency"`
+	Balance float64 `json:"balance"`
+	Balances []*WalletBalance `json:"balances"`
+}
+
+type WalletBalance struct {
+	Currency string `json:"currency"`
+	Balance float64 `json:"balance"`
+}
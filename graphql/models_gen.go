@@ -2,6 +2,10 @@
 
 package main
 
+import (
+	"github.com/Shridhar2104/logilo/graphql/models"
+)
+
 type AccountInput struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
@@ -12,6 +16,128 @@ type Accounts struct {
 	Orders []*Order `json:"orders"`
 }
 
+type Address struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postalCode"`
+}
+
+type AddressInput struct {
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+	City       string  `json:"city"`
+	State      string  `json:"state"`
+	PostalCode string  `json:"postalCode"`
+}
+
+type APIKey struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	RateLimit  int      `json:"rateLimit"`
+	CreatedBy  string   `json:"createdBy"`
+	CreatedAt  string   `json:"createdAt"`
+	LastUsedAt *string  `json:"lastUsedAt,omitempty"`
+}
+
+type AuthPayload struct {
+	SessionID             string          `json:"sessionId"`
+	AccessToken           string          `json:"accessToken"`
+	RefreshToken          string          `json:"refreshToken"`
+	AccessTokenExpiresAt  string          `json:"accessTokenExpiresAt"`
+	RefreshTokenExpiresAt string          `json:"refreshTokenExpiresAt"`
+	Account               *models.Account `json:"account,omitempty"`
+}
+
+type CreatedAPIKey struct {
+	APIKey *APIKey `json:"apiKey"`
+	Key    string  `json:"key"`
+}
+
+type DataExportDownload struct {
+	Filename      string `json:"filename"`
+	ContentType   string `json:"contentType"`
+	ContentBase64 string `json:"contentBase64"`
+}
+
+type DataRequest struct {
+	ID          string   `json:"id"`
+	Kind        string   `json:"kind"`
+	Status      string   `json:"status"`
+	LastError   *string  `json:"lastError,omitempty"`
+	Retained    []string `json:"retained"`
+	CreatedAt   string   `json:"createdAt"`
+	CompletedAt *string  `json:"completedAt,omitempty"`
+	ExpiresAt   *string  `json:"expiresAt,omitempty"`
+}
+
+type Invitation struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	InvitedBy string `json:"invitedBy"`
+	CreatedAt string `json:"createdAt"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+type Invoice struct {
+	ID            string  `json:"id"`
+	Number        string  `json:"number"`
+	FinancialYear string  `json:"financialYear"`
+	PeriodStart   string  `json:"periodStart"`
+	PeriodEnd     string  `json:"periodEnd"`
+	IssueDate     string  `json:"issueDate"`
+	Gstin         string  `json:"gstin"`
+	PlaceOfSupply string  `json:"placeOfSupply"`
+	Sac           string  `json:"sac"`
+	TaxableValue  float64 `json:"taxableValue"`
+	Cgst          float64 `json:"cgst"`
+	Sgst          float64 `json:"sgst"`
+	Igst          float64 `json:"igst"`
+	Total         float64 `json:"total"`
+}
+
+type InvoiceDownload struct {
+	Filename      string `json:"filename"`
+	ContentType   string `json:"contentType"`
+	ContentBase64 string `json:"contentBase64"`
+}
+
+type KycDocument struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int    `json:"size"`
+	UploadedBy  string `json:"uploadedBy"`
+	UploadedAt  string `json:"uploadedAt"`
+}
+
+type LoginEvent struct {
+	ID            string  `json:"id"`
+	UserAgent     string  `json:"userAgent"`
+	IP            string  `json:"ip"`
+	Success       bool    `json:"success"`
+	FailureReason *string `json:"failureReason,omitempty"`
+	NewDevice     bool    `json:"newDevice"`
+	CreatedAt     string  `json:"createdAt"`
+}
+
+type LoginResult struct {
+	Auth               *AuthPayload        `json:"auth,omitempty"`
+	TwoFactorChallenge *TwoFactorChallenge `json:"twoFactorChallenge,omitempty"`
+}
+
+type Membership struct {
+	AccountID   string `json:"accountId"`
+	AccountName string `json:"accountName"`
+	Role        string `json:"role"`
+	Current     bool   `json:"current"`
+}
+
 type Mutation struct {
 }
 
@@ -46,9 +172,122 @@ type PaginationInput struct {
 	Take int `json:"take"`
 }
 
+type Profile struct {
+	CompanyName        string   `json:"companyName"`
+	Gstin              string   `json:"gstin"`
+	Pan                string   `json:"pan"`
+	BillingAddress     *Address `json:"billingAddress"`
+	ContactPhone       string   `json:"contactPhone"`
+	BrandName          string   `json:"brandName"`
+	HasLogo            bool     `json:"hasLogo"`
+	KycStatus          string   `json:"kycStatus"`
+	KycSubmittedAt     *string  `json:"kycSubmittedAt,omitempty"`
+	KycReviewedAt      *string  `json:"kycReviewedAt,omitempty"`
+	KycRejectionReason string   `json:"kycRejectionReason"`
+	UpdatedAt          string   `json:"updatedAt"`
+}
+
+type ProfileInput struct {
+	CompanyName    string        `json:"companyName"`
+	Gstin          *string       `json:"gstin,omitempty"`
+	Pan            string        `json:"pan"`
+	BillingAddress *AddressInput `json:"billingAddress"`
+	ContactPhone   string        `json:"contactPhone"`
+	BrandName      *string       `json:"brandName,omitempty"`
+}
+
 type Query struct {
 }
 
+type RechargeIntent struct {
+	ID             string  `json:"id"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+	WalletCurrency string  `json:"walletCurrency"`
+	CreditAmount   float64 `json:"creditAmount"`
+	Status         string  `json:"status"`
+	CheckoutRef    string  `json:"checkoutRef"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
+type Remittance struct {
+	ID            string             `json:"id"`
+	Status        string             `json:"status"`
+	TotalAmount   float64            `json:"totalAmount"`
+	Fee           float64            `json:"fee"`
+	NetAmount     float64            `json:"netAmount"`
+	Utr           *string            `json:"utr,omitempty"`
+	FailureReason *string            `json:"failureReason,omitempty"`
+	CreatedAt     string             `json:"createdAt"`
+	Orders        []*RemittanceOrder `json:"orders"`
+}
+
+type RemittanceOrder struct {
+	OrderID string  `json:"orderId"`
+	Amount  float64 `json:"amount"`
+}
+
+type Session struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"userAgent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"createdAt"`
+	LastUsedAt string `json:"lastUsedAt"`
+	ExpiresAt  string `json:"expiresAt"`
+	Current    bool   `json:"current"`
+}
+
 type ShopName struct {
 	Shopname string `json:"shopname"`
 }
+
+type TeamMember struct {
+	UserID   string `json:"userId"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joinedAt"`
+}
+
+type TransactionFilterInput struct {
+	Type    *string `json:"type,omitempty"`
+	OrderID *string `json:"orderId,omitempty"`
+	From    *string `json:"from,omitempty"`
+	To      *string `json:"to,omitempty"`
+}
+
+type TransactionPage struct {
+	Items      []*WalletTransaction `json:"items"`
+	NextCursor *string              `json:"nextCursor,omitempty"`
+}
+
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challengeToken"`
+	ExpiresAt      string `json:"expiresAt"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+type UpdateAccountInput struct {
+	Name            *string `json:"name,omitempty"`
+	Email           *string `json:"email,omitempty"`
+	Password        *string `json:"password,omitempty"`
+	CurrentPassword *string `json:"currentPassword,omitempty"`
+}
+
+type WalletTransaction struct {
+	ID             string   `json:"id"`
+	Type           string   `json:"type"`
+	Currency       string   `json:"currency"`
+	Amount         float64  `json:"amount"`
+	OrderID        *string  `json:"orderId,omitempty"`
+	Reference      *string  `json:"reference,omitempty"`
+	BalanceAfter   float64  `json:"balanceAfter"`
+	SourceAmount   *float64 `json:"sourceAmount,omitempty"`
+	SourceCurrency *string  `json:"sourceCurrency,omitempty"`
+	FxRate         *float64 `json:"fxRate,omitempty"`
+	CreatedAt      string   `json:"createdAt"`
+}
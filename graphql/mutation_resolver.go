@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"time"
 
 	"github.com/Shridhar2104/logilo/account"
 	"github.com/Shridhar2104/logilo/graphql/models"
@@ -25,12 +28,577 @@ func (r *mutationResolver) CreateAccount(ctx context.Context, input AccountInput
 		return nil, err
 	}
 
+	return toGraphQLAccount(res), nil
+}
+
+// UpdateAccount changes the caller's name, email or password. A new password
+// signs out the caller's other sessions.
+func (r *mutationResolver) UpdateAccount(ctx context.Context, input UpdateAccountInput) (*models.Account, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var update account.AccountUpdate
+	if input.Name != nil {
+		update.Name = *input.Name
+	}
+	if input.Email != nil {
+		update.Email = *input.Email
+	}
+	if input.Password != nil {
+		update.Password = *input.Password
+	}
+	if input.CurrentPassword != nil {
+		update.CurrentPassword = *input.CurrentPassword
+	}
+
+	a, err := r.server.accountClient.UpdateAccount(ctx, claims.UserID, claims.SessionID, update)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAccount(a), nil
+}
+
+// DeleteAccount deactivates the caller's account.
+func (r *mutationResolver) DeleteAccount(ctx context.Context, password string) (bool, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.DeleteAccount(ctx, claims.UserID, password); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// toGraphQLAccount maps an account to the GraphQL model. The password is never
+// returned.
+func toGraphQLAccount(a *account.Account) *models.Account {
 	return &models.Account{
-		ID: res.ID.String(),
-		Name: res.Name,
-		Password: res.Password,
-		Email: res.Email,
-		Orders: nil,
-		ShopNames: nil,
-	}, nil
-}
\ No newline at end of file
+		ID:            a.ID.String(),
+		Name:          a.Name,
+		Email:         a.Email,
+		EmailVerified: a.EmailVerifiedAt != nil,
+	}
+}
+
+// Login exchanges email and password for an access and refresh token. The
+// access token is sent as a bearer token on later requests. Accounts with
+// two-factor authentication get a challenge to answer with completeLogin.
+func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*LoginResult, error) {
+	res, err := r.server.accountClient.Login(ctx, email, password, callerDevice(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Challenge != nil {
+		return &LoginResult{TwoFactorChallenge: &TwoFactorChallenge{
+			ChallengeToken: res.Challenge.Token,
+			ExpiresAt:      res.Challenge.ExpiresAt.Format(time.RFC3339),
+		}}, nil
+	}
+	return &LoginResult{Auth: toGraphQLLoginAuth(res)}, nil
+}
+
+// CompleteLogin answers a login's two-factor challenge with a TOTP code or a
+// recovery code.
+func (r *mutationResolver) CompleteLogin(ctx context.Context, challengeToken string, code string) (*AuthPayload, error) {
+	res, err := r.server.accountClient.CompleteLogin(ctx, challengeToken, code, callerDevice(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLLoginAuth(res), nil
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. Each refresh
+// token can be used once; the new pair carries its replacement.
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*AuthPayload, error) {
+	tokens, err := r.server.accountClient.RefreshToken(ctx, refreshToken, callerDevice(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAuthPayload(tokens), nil
+}
+
+// Logout ends the caller's current session.
+func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.RevokeSession(ctx, claims.UserID, claims.SessionID, account.RevokedLogout); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RevokeSession ends one of the caller's sessions, e.g. on a lost device.
+func (r *mutationResolver) RevokeSession(ctx context.Context, sessionID string) (bool, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.RevokeSession(ctx, userID, sessionID, account.RevokedByUser); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RevokeAllSessions ends the caller's other sessions, and the current one too
+// if includeCurrent is set, and returns how many were ended.
+func (r *mutationResolver) RevokeAllSessions(ctx context.Context, includeCurrent *bool) (int, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return 0, err
+	}
+	except := claims.SessionID
+	if includeCurrent != nil && *includeCurrent {
+		except = ""
+	}
+	return r.server.accountClient.RevokeAllSessions(ctx, claims.UserID, except)
+}
+
+// SendVerificationEmail emails the caller a new link to verify their address.
+func (r *mutationResolver) SendVerificationEmail(ctx context.Context) (bool, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.SendVerificationEmail(ctx, userID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyEmail consumes the token from a verification link. Access tokens
+// issued after the next refresh carry the verified status.
+func (r *mutationResolver) VerifyEmail(ctx context.Context, token string) (bool, error) {
+	if err := r.server.accountClient.VerifyEmail(ctx, token); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RequestPasswordReset emails a reset link to the address if it has an
+// account. It succeeds either way.
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, email string) (bool, error) {
+	if err := r.server.accountClient.RequestPasswordReset(ctx, email); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ResetPassword sets a new password using the token from a reset link. All of
+// the account's sessions are signed out.
+func (r *mutationResolver) ResetPassword(ctx context.Context, token string, newPassword string) (bool, error) {
+	if err := r.server.accountClient.ResetPassword(ctx, token, newPassword); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// BeginTwoFactorEnrollment starts two-factor enrollment for the caller. The
+// otpauth URI is shown as a QR code for authenticator apps.
+func (r *mutationResolver) BeginTwoFactorEnrollment(ctx context.Context) (*TwoFactorEnrollment, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := r.server.accountClient.BeginTwoFactorEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorEnrollment{Secret: enrollment.Secret, OtpauthURI: enrollment.URI}, nil
+}
+
+// ConfirmTwoFactorEnrollment enables two-factor authentication with a code
+// from the authenticator app and returns the recovery codes, shown only once.
+func (r *mutationResolver) ConfirmTwoFactorEnrollment(ctx context.Context, code string) ([]string, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.server.accountClient.ConfirmTwoFactorEnrollment(ctx, userID, code)
+}
+
+// DisableTwoFactor turns two-factor authentication off for the caller.
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.DisableTwoFactor(ctx, userID, code); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes.
+func (r *mutationResolver) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.server.accountClient.RegenerateRecoveryCodes(ctx, userID, code)
+}
+
+func toGraphQLLoginAuth(res *account.LoginResult) *AuthPayload {
+	payload := toGraphQLAuthPayload(res.Tokens)
+	a := res.Account
+	payload.Account = &models.Account{ID: a.ID.String(), Name: a.Name, Email: a.Email, EmailVerified: a.EmailVerifiedAt != nil}
+	return payload
+}
+
+func toGraphQLAuthPayload(tokens *account.TokenPair) *AuthPayload {
+	return &AuthPayload{
+		SessionID:             tokens.SessionID,
+		AccessToken:           tokens.AccessToken,
+		RefreshToken:          tokens.RefreshToken,
+		AccessTokenExpiresAt:  tokens.AccessExpiresAt.Format(time.RFC3339),
+		RefreshTokenExpiresAt: tokens.RefreshExpiresAt.Format(time.RFC3339),
+	}
+}
+
+
+// CreateRechargeIntent starts a gateway recharge. The wallet is credited once
+// the gateway confirms the payment.
+func (r *mutationResolver) CreateRechargeIntent(ctx context.Context, amount float64, currency *string, walletCurrency *string) (*RechargeIntent, error) {
+	accountID, err := authorize(ctx, account.PermissionManageBilling)
+	if err != nil {
+		return nil, err
+	}
+	intent, err := r.server.paymentClient.CreateRechargeIntent(ctx, accountID, amount, stringValue(currency), stringValue(walletCurrency))
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLRechargeIntent(intent), nil
+}
+
+// InviteMember emails an invitation to join the caller's team with a role.
+func (r *mutationResolver) InviteMember(ctx context.Context, email string, role string) (*Invitation, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := r.server.accountClient.InviteMember(ctx, claims.UserID, claims.AccountID, email, role)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLInvitation(inv), nil
+}
+
+// RevokeInvitation withdraws one of the team's pending invitations.
+func (r *mutationResolver) RevokeInvitation(ctx context.Context, invitationID string) (bool, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.RevokeInvitation(ctx, claims.UserID, claims.AccountID, invitationID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AcceptInvitation joins the team that sent the invitation. The caller signs
+// in with the invited address first; switchAccount then acts for the team.
+func (r *mutationResolver) AcceptInvitation(ctx context.Context, token string) (*Membership, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, err := r.server.accountClient.AcceptInvitation(ctx, claims.UserID, token)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLMembership(m, claims), nil
+}
+
+// SetMemberRole changes the role of a member of the caller's team. It applies
+// from the member's next token refresh.
+func (r *mutationResolver) SetMemberRole(ctx context.Context, userID string, role string) (bool, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.SetMemberRole(ctx, claims.UserID, claims.AccountID, userID, role); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveMember removes a member from the caller's team. Members leave a team
+// by removing themselves.
+func (r *mutationResolver) RemoveMember(ctx context.Context, userID string) (bool, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.RemoveMember(ctx, claims.UserID, claims.AccountID, userID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SwitchAccount reissues the current session's tokens to act for another team
+// the caller belongs to.
+func (r *mutationResolver) SwitchAccount(ctx context.Context, accountID string) (*AuthPayload, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := r.server.accountClient.SwitchAccount(ctx, claims.UserID, claims.SessionID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAuthPayload(tokens), nil
+}
+
+// RequireTwoFactor requires every member of the caller's team to use
+// two-factor authentication, or stops requiring it.
+func (r *mutationResolver) RequireTwoFactor(ctx context.Context, required bool) (bool, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.SetTwoFactorRequired(ctx, claims.UserID, claims.AccountID, required); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func toGraphQLInvitation(inv *account.Invitation) *Invitation {
+	return &Invitation{
+		ID:        inv.ID,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		InvitedBy: inv.InvitedBy,
+		CreatedAt: inv.CreatedAt.Format(time.RFC3339),
+		ExpiresAt: inv.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+func toGraphQLMembership(m *account.Membership, claims *account.Claims) *Membership {
+	return &Membership{
+		AccountID:   m.AccountID,
+		AccountName: m.AccountName,
+		Role:        m.Role,
+		Current:     m.AccountID == claims.AccountID,
+	}
+}
+
+// CreateAPIKey creates an API key for the caller's account. The key is
+// returned once and cannot be retrieved again.
+func (r *mutationResolver) CreateAPIKey(ctx context.Context, name string, scopes []string, rateLimit *int) (*CreatedAPIKey, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	permissions := make([]account.Permission, len(scopes))
+	for i, scope := range scopes {
+		permissions[i] = account.Permission(scope)
+	}
+	limit := 0
+	if rateLimit != nil {
+		limit = *rateLimit
+	}
+
+	key, secret, err := r.server.accountClient.CreateAPIKey(ctx, claims.UserID, claims.AccountID, name, permissions, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{APIKey: toGraphQLAPIKey(key), Key: secret}, nil
+}
+
+// RevokeAPIKey revokes one of the account's API keys.
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, keyID string) (bool, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := r.server.accountClient.RevokeAPIKey(ctx, claims.UserID, claims.AccountID, keyID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateProfile sets the account's business details.
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input ProfileInput) (*Profile, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p := account.Profile{
+		CompanyName:  input.CompanyName,
+		PAN:          input.Pan,
+		ContactPhone: input.ContactPhone,
+	}
+	if input.Gstin != nil {
+		p.GSTIN = *input.Gstin
+	}
+	if input.BrandName != nil {
+		p.BrandName = *input.BrandName
+	}
+	if a := input.BillingAddress; a != nil {
+		p.BillingAddress = account.Address{Line1: a.Line1, City: a.City, State: a.State, PostalCode: a.PostalCode}
+		if a.Line2 != nil {
+			p.BillingAddress.Line2 = *a.Line2
+		}
+	}
+
+	profile, err := r.server.accountClient.UpdateProfile(ctx, claims.UserID, claims.AccountID, p)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLProfile(profile), nil
+}
+
+// UploadLogo replaces the logo printed on the account's shipping labels.
+func (r *mutationResolver) UploadLogo(ctx context.Context, contentBase64 string) (*Profile, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(contentBase64)
+	if err != nil {
+		return nil, errors.New("contentBase64 is not valid base64")
+	}
+
+	profile, err := r.server.accountClient.UploadLogo(ctx, claims.UserID, claims.AccountID, data)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLProfile(profile), nil
+}
+
+// UploadKycDocument uploads a document and submits the account's KYC for review.
+func (r *mutationResolver) UploadKycDocument(ctx context.Context, docType string, filename string, contentBase64 string) (*KycDocument, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(contentBase64)
+	if err != nil {
+		return nil, errors.New("contentBase64 is not valid base64")
+	}
+
+	doc, err := r.server.accountClient.UploadKYCDocument(ctx, claims.UserID, claims.AccountID, docType, filename, data)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLKYCDocument(doc), nil
+}
+
+// RequestDataExport queues an export of everything held about the user and
+// their merchant account. It is downloaded with downloadDataExport once done.
+func (r *mutationResolver) RequestDataExport(ctx context.Context) (*DataRequest, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := r.server.accountClient.RequestDataExport(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLDataRequest(req), nil
+}
+
+// RequestDataErasure queues the erasure of the user and their merchant
+// account across every service. It cannot be undone.
+func (r *mutationResolver) RequestDataErasure(ctx context.Context, password string) (*DataRequest, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := r.server.accountClient.RequestDataErasure(ctx, claims.UserID, password)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLDataRequest(req), nil
+}
+
+func toGraphQLDataRequest(req *account.DataRequest) *DataRequest {
+	out := &DataRequest{
+		ID:        req.ID,
+		Kind:      req.Kind,
+		Status:    req.Status,
+		Retained:  req.Retained,
+		CreatedAt: req.CreatedAt.Format(time.RFC3339),
+	}
+	if out.Retained == nil {
+		out.Retained = []string{}
+	}
+	if req.LastError != "" {
+		out.LastError = &req.LastError
+	}
+	if req.CompletedAt != nil {
+		completed := req.CompletedAt.Format(time.RFC3339)
+		out.CompletedAt = &completed
+	}
+	if req.ExpiresAt != nil {
+		expires := req.ExpiresAt.Format(time.RFC3339)
+		out.ExpiresAt = &expires
+	}
+	return out
+}
+
+func toGraphQLProfile(p *account.Profile) *Profile {
+	a := p.BillingAddress
+	out := &Profile{
+		CompanyName: p.CompanyName,
+		Gstin:       p.GSTIN,
+		Pan:         p.PAN,
+		BillingAddress: &Address{
+			Line1:      a.Line1,
+			Line2:      a.Line2,
+			City:       a.City,
+			State:      a.State,
+			PostalCode: a.PostalCode,
+		},
+		ContactPhone:       p.ContactPhone,
+		BrandName:          p.BrandName,
+		HasLogo:            p.HasLogo,
+		KycStatus:          p.KYCStatus,
+		KycRejectionReason: p.KYCRejectionReason,
+		UpdatedAt:          p.UpdatedAt.Format(time.RFC3339),
+	}
+	if p.KYCSubmittedAt != nil {
+		submitted := p.KYCSubmittedAt.Format(time.RFC3339)
+		out.KycSubmittedAt = &submitted
+	}
+	if p.KYCReviewedAt != nil {
+		reviewed := p.KYCReviewedAt.Format(time.RFC3339)
+		out.KycReviewedAt = &reviewed
+	}
+	return out
+}
+
+func toGraphQLKYCDocument(doc *account.KYCDocument) *KycDocument {
+	return &KycDocument{
+		ID:          doc.ID,
+		Type:        doc.Type,
+		Filename:    doc.Filename,
+		ContentType: doc.ContentType,
+		Size:        doc.Size,
+		UploadedBy:  doc.UploadedBy,
+		UploadedAt:  doc.UploadedAt.Format(time.RFC3339),
+	}
+}
+
+func toGraphQLAPIKey(key *account.APIKey) *APIKey {
+	k := &APIKey{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		RateLimit: key.RateLimit,
+		CreatedBy: key.CreatedBy,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+	for _, scope := range key.Scopes {
+		k.Scopes = append(k.Scopes, string(scope))
+	}
+	if key.LastUsedAt != nil {
+		lastUsed := key.LastUsedAt.Format(time.RFC3339)
+		k.LastUsedAt = &lastUsed
+	}
+	return k
+}
@@ -2,9 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Shridhar2104/logilo/account"
 	"github.com/Shridhar2104/logilo/graphql/models"
-	"log"
-	
+	"github.com/Shridhar2104/logilo/payment"
+	"github.com/Shridhar2104/logilo/payment/invoice"
 )
 
 
@@ -13,33 +19,327 @@ type queryResolver struct {
 }
 
 
-func (r *queryResolver) GetAccountByID(ctx context.Context, email string, password string) (*models.Account, error) {
-	// Fetch account data from the account service
-	accountResp, err := r.server.accountClient.LoginAndGetAccount(ctx, email, password)
+// GetAccountByID fetches the caller's own account, or a member of the team the
+// caller is acting for.
+func (r *queryResolver) GetAccountByID(ctx context.Context, id string) (*models.Account, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if id != claims.UserID {
+		members, err := r.server.accountClient.ListMembers(ctx, claims.UserID, claims.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(members, func(m account.Member) bool { return m.UserID == id }) {
+			return nil, account.ErrAccountNotFound
+		}
+	}
+
+	a, err := r.server.accountClient.GetAccountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLAccount(a), nil
+}
+
+func (r *queryResolver) Invoices(ctx context.Context) ([]*Invoice, error) {
+	accountID, err := authorize(ctx, account.PermissionViewBilling)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.server.invoiceClient.ListInvoices(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]*Invoice, len(res))
+	for i := range res {
+		invoices[i] = toGraphQLInvoice(&res[i])
+	}
+	return invoices, nil
+}
+
+
+func (r *queryResolver) DownloadInvoice(ctx context.Context, invoiceID string) (*InvoiceDownload, error) {
+	accountID, err := authorize(ctx, account.PermissionViewBilling)
+	if err != nil {
+		return nil, err
+	}
+	inv, pdf, err := r.server.invoiceClient.DownloadInvoice(ctx, accountID, invoiceID)
 	if err != nil {
-		log.Printf("Error fetching account by ID: %v", err)
 		return nil, err
 	}
 
-	// Map account service response to the GraphQL model
-	return &models.Account{
-		ID:        accountResp.ID.String(),
-		Name:      accountResp.Name,
-		Password:  accountResp.Password,
-		Email:     accountResp.Email,
+	return &InvoiceDownload{
+		Filename:      strings.ReplaceAll(inv.Number, "/", "-") + ".pdf",
+		ContentType:   "application/pdf",
+		ContentBase64: base64.StdEncoding.EncodeToString(pdf),
 	}, nil
 }
 
+// Wallet fetches the account's wallet in currency, INR by default, along with
+// the balance of each of its wallets. Transactions are paged by the wallet's
+// own resolver.
+func (r *queryResolver) Wallet(ctx context.Context, currency *string) (*models.Wallet, error) {
+	accountID, err := authorize(ctx, account.PermissionViewBilling)
+	if err != nil {
+		return nil, err
+	}
+	balances, _, _, err := r.server.paymentClient.GetWalletDetails(ctx, accountID, payment.TransactionFilter{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := &models.Wallet{AccountID: accountID, Currency: strings.ToUpper(stringValue(currency))}
+	if wallet.Currency == "" {
+		wallet.Currency = payment.BaseCurrency
+	}
+	for _, b := range balances {
+		if b.Currency == wallet.Currency {
+			wallet.Balance = b.Balance
+		}
+		wallet.Balances = append(wallet.Balances, &models.WalletBalance{Currency: b.Currency, Balance: b.Balance})
+	}
+	return wallet, nil
+}
+
+
+func (r *queryResolver) Remittances(ctx context.Context) ([]*Remittance, error) {
+	accountID, err := authorize(ctx, account.PermissionViewBilling)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := r.server.paymentClient.ListRemittanceBatches(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	remittances := make([]*Remittance, len(batches))
+	for i := range batches {
+		remittances[i] = toGraphQLRemittance(&batches[i])
+	}
+	return remittances, nil
+}
+
+// Sessions lists the caller's active sessions, marking the one making the request.
+func (r *queryResolver) Sessions(ctx context.Context) ([]*Session, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.server.accountClient.ListSessions(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*Session, len(res))
+	for i, s := range res {
+		sessions[i] = &Session{
+			ID:         s.ID,
+			UserAgent:  s.Device.UserAgent,
+			IP:         s.Device.IP,
+			CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: s.LastUsedAt.Format(time.RFC3339),
+			ExpiresAt:  s.ExpiresAt.Format(time.RFC3339),
+			Current:    s.ID == claims.SessionID,
+		}
+	}
+	return sessions, nil
+}
+
+// LoginHistory lists the caller's recent login attempts, newest first.
+func (r *queryResolver) LoginHistory(ctx context.Context, limit *int) ([]*LoginEvent, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	if limit != nil {
+		n = *limit
+	}
+	res, err := r.server.accountClient.ListLoginHistory(ctx, claims.UserID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*LoginEvent, len(res))
+	for i, e := range res {
+		events[i] = &LoginEvent{
+			ID:        e.ID,
+			UserAgent: e.Device.UserAgent,
+			IP:        e.Device.IP,
+			Success:   e.Success,
+			NewDevice: e.NewDevice,
+			CreatedAt: e.CreatedAt.Format(time.RFC3339),
+		}
+		if e.FailureReason != "" {
+			events[i].FailureReason = &res[i].FailureReason
+		}
+	}
+	return events, nil
+}
+
+// Team lists the members of the team the caller is acting for.
+func (r *queryResolver) Team(ctx context.Context) ([]*TeamMember, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.server.accountClient.ListMembers(ctx, claims.UserID, claims.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*TeamMember, len(res))
+	for i, m := range res {
+		members[i] = &TeamMember{
+			UserID:   m.UserID,
+			Name:     m.Name,
+			Email:    m.Email,
+			Role:     m.Role,
+			JoinedAt: m.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return members, nil
+}
+
+// Invitations lists the team's pending invitations.
+func (r *queryResolver) Invitations(ctx context.Context) ([]*Invitation, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.server.accountClient.ListInvitations(ctx, claims.UserID, claims.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]*Invitation, len(res))
+	for i := range res {
+		invitations[i] = toGraphQLInvitation(&res[i])
+	}
+	return invitations, nil
+}
+
+// Memberships lists the teams the caller belongs to, marking the one they are
+// acting for.
+func (r *queryResolver) Memberships(ctx context.Context) ([]*Membership, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.server.accountClient.ListMemberships(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := make([]*Membership, len(res))
+	for i := range res {
+		memberships[i] = toGraphQLMembership(&res[i], claims)
+	}
+	return memberships, nil
+}
 
-func (r *queryResolver) Accounts(ctx context.Context, pagination PaginationInput) ([]*models.Account, error) {
-	res, err := r.server.accountClient.ListAccounts(ctx, uint64(pagination.Skip), uint64(pagination.Take))
+// APIKeys lists the active API keys of the account the caller is acting for.
+func (r *queryResolver) APIKeys(ctx context.Context) ([]*APIKey, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.server.accountClient.ListAPIKeys(ctx, claims.UserID, claims.AccountID)
 	if err != nil {
 		return nil, err
 	}
 
-	accounts := make([]*models.Account, len(res))
-	for i, account := range res {
-		accounts[i] = &models.Account{ID: account.ID.String(), Name: account.Name}
+	keys := make([]*APIKey, len(res))
+	for i := range res {
+		keys[i] = toGraphQLAPIKey(&res[i])
 	}
-	return accounts, nil
-}
\ No newline at end of file
+	return keys, nil
+}
+
+// Profile fetches the account's merchant profile and KYC status.
+func (r *queryResolver) Profile(ctx context.Context) (*Profile, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, err := r.server.accountClient.GetProfile(ctx, claims.UserID, claims.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	return toGraphQLProfile(p), nil
+}
+
+// KycDocuments lists the documents uploaded for the account's KYC.
+func (r *queryResolver) KycDocuments(ctx context.Context) ([]*KycDocument, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.server.accountClient.ListKYCDocuments(ctx, claims.UserID, claims.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]*KycDocument, len(res))
+	for i := range res {
+		docs[i] = toGraphQLKYCDocument(&res[i])
+	}
+	return docs, nil
+}
+
+func (r *queryResolver) DataRequests(ctx context.Context) ([]*DataRequest, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.server.accountClient.ListDataRequests(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]*DataRequest, len(res))
+	for i := range res {
+		requests[i] = toGraphQLDataRequest(&res[i])
+	}
+	return requests, nil
+}
+
+// DownloadDataExport returns a completed data export as a base64 zip archive.
+func (r *queryResolver) DownloadDataExport(ctx context.Context, requestID string) (*DataExportDownload, error) {
+	claims, err := userClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, data, err := r.server.accountClient.GetDataExport(ctx, claims.UserID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataExportDownload{
+		Filename:      "logilo-data-" + req.CreatedAt.Format("2006-01-02") + ".zip",
+		ContentType:   "application/zip",
+		ContentBase64: base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+func toGraphQLInvoice(inv *invoice.Invoice) *Invoice {
+	return &Invoice{
+		ID:            inv.ID,
+		Number:        inv.Number,
+		FinancialYear: inv.FinancialYear,
+		PeriodStart:   inv.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:     inv.PeriodEnd.Format(time.RFC3339),
+		IssueDate:     inv.IssueDate.Format(time.RFC3339),
+		Gstin:         inv.Billing.GSTIN,
+		PlaceOfSupply: inv.Billing.StateCode,
+		Sac:           inv.SAC,
+		TaxableValue:  inv.TaxableValue,
+		Cgst:          inv.CGST,
+		Sgst:          inv.SGST,
+		Igst:          inv.IGST,
+		Total:         inv.Total,
+	}
+}
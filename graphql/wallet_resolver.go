@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shridhar2104/logilo/graphql/models"
+	"github.com/Shridhar2104/logilo/payment"
+)
+
+type walletResolver struct {
+	server *Server
+}
+
+// Transactions pages through the wallet's transactions, newest first.
+func (r *walletResolver) Transactions(ctx context.Context, obj *models.Wallet, first *int, after *string, filter *TransactionFilterInput) (*TransactionPage, error) {
+	f := payment.TransactionFilter{Currency: obj.Currency}
+	if first != nil {
+		f.Limit = *first
+	}
+	if after != nil {
+		f.Cursor = *after
+	}
+	if filter != nil {
+		if filter.Type != nil {
+			f.Type = *filter.Type
+		}
+		if filter.OrderID != nil {
+			f.OrderID = *filter.OrderID
+		}
+		var err error
+		if filter.From != nil {
+			if f.From, err = time.Parse(time.RFC3339, *filter.From); err != nil {
+				return nil, fmt.Errorf("invalid from: %w", err)
+			}
+		}
+		if filter.To != nil {
+			if f.To, err = time.Parse(time.RFC3339, *filter.To); err != nil {
+				return nil, fmt.Errorf("invalid to: %w", err)
+			}
+		}
+	}
+
+	_, transactions, nextCursor, err := r.server.paymentClient.GetWalletDetails(ctx, obj.AccountID, f)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TransactionPage{Items: make([]*WalletTransaction, len(transactions))}
+	for i, t := range transactions {
+		page.Items[i] = &WalletTransaction{
+			ID:           t.TransactionID,
+			Type:         t.TransactionType,
+			Currency:     t.Currency,
+			Amount:       t.Amount,
+			OrderID:      optionalString(t.OrderID.String),
+			Reference:    optionalString(t.Reference.String),
+			BalanceAfter: t.BalanceAfter,
+			CreatedAt:    t.Timestamp.Format(time.RFC3339),
+		}
+		if t.FX != nil {
+			page.Items[i].SourceAmount = &t.FX.SourceAmount
+			page.Items[i].SourceCurrency = &t.FX.SourceCurrency
+			page.Items[i].FxRate = &t.FX.Rate
+		}
+	}
+	page.NextCursor = optionalString(nextCursor)
+	return page, nil
+}
+
+func toGraphQLRechargeIntent(intent *payment.RechargeIntent) *RechargeIntent {
+	return &RechargeIntent{
+		ID:             intent.ID,
+		Amount:         intent.Amount,
+		Currency:       intent.Currency,
+		WalletCurrency: intent.WalletCurrency,
+		CreditAmount:   intent.CreditAmount,
+		Status:         intent.Status,
+		CheckoutRef:    intent.CheckoutRef,
+		CreatedAt:      intent.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toGraphQLRemittance(b *payment.RemittanceBatch) *Remittance {
+	orders := make([]*RemittanceOrder, len(b.Items))
+	for i, item := range b.Items {
+		orders[i] = &RemittanceOrder{OrderID: item.OrderID, Amount: item.Amount}
+	}
+
+	return &Remittance{
+		ID:            b.ID,
+		Status:        b.Status,
+		TotalAmount:   b.TotalAmount,
+		Fee:           b.Fee,
+		NetAmount:     b.NetAmount(),
+		Utr:           optionalString(b.UTR),
+		FailureReason: optionalString(b.FailureReason),
+		CreatedAt:     b.CreatedAt.Format(time.RFC3339),
+		Orders:        orders,
+	}
+}
+
+// optionalString maps an empty string to a null GraphQL field.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// stringValue returns the value of an optional argument, or "" if it is unset.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,235 @@
+// Package sqltest provides a scripted database/sql driver for testing
+// repositories without a database. Tests list the statements they expect, in
+// order, along with the rows or errors each returns.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Mock is the script for a database opened with Open.
+type Mock struct {
+	t testing.TB
+
+	mu       sync.Mutex
+	expected []*Expectation
+	next     int
+}
+
+// Expectation is a statement the code under test is expected to run.
+type Expectation struct {
+	kind     string // "begin", "commit", "rollback", "query" or "exec".
+	fragment string
+	args     []any
+	checkArg bool
+
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+
+	// Query and Args hold the statement as it was run.
+	Query string
+	Args  []any
+}
+
+// Open returns a database that runs the statements scripted on the returned
+// Mock. The test fails if a statement does not match the script, or if the
+// script is not finished by the end of the test.
+func Open(t testing.TB) (*sql.DB, *Mock) {
+	m := &Mock{t: t}
+	db := sql.OpenDB(connector{m})
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		db.Close()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, e := range m.expected[m.next:] {
+			t.Errorf("sqltest: expected %s was not run", e)
+		}
+	})
+	return db, m
+}
+
+// ExpectBegin expects a transaction to start.
+func (m *Mock) ExpectBegin() *Expectation { return m.expect("begin", "") }
+
+// ExpectCommit expects the transaction to commit.
+func (m *Mock) ExpectCommit() *Expectation { return m.expect("commit", "") }
+
+// ExpectRollback expects the transaction to roll back.
+func (m *Mock) ExpectRollback() *Expectation { return m.expect("rollback", "") }
+
+// ExpectQuery expects a query whose text contains fragment, ignoring
+// differences in whitespace.
+func (m *Mock) ExpectQuery(fragment string) *Expectation { return m.expect("query", fragment) }
+
+// ExpectExec expects a statement whose text contains fragment, ignoring
+// differences in whitespace.
+func (m *Mock) ExpectExec(fragment string) *Expectation { return m.expect("exec", fragment) }
+
+func (m *Mock) expect(kind string, fragment string) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &Expectation{kind: kind, fragment: normalize(fragment)}
+	m.expected = append(m.expected, e)
+	return e
+}
+
+// WithArgs requires the statement to be run with args.
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.args, e.checkArg = args, true
+	return e
+}
+
+// WillReturnRows makes a query return rows with the given columns.
+func (e *Expectation) WillReturnRows(columns []string, rows ...[]any) *Expectation {
+	e.columns = columns
+	for _, row := range rows {
+		values := make([]driver.Value, len(row))
+		for i, v := range row {
+			values[i] = v
+		}
+		e.rows = append(e.rows, values)
+	}
+	return e
+}
+
+// WillReturnResult makes a statement report the number of rows it affected.
+func (e *Expectation) WillReturnResult(affected int64) *Expectation {
+	e.affected = affected
+	return e
+}
+
+// WillReturnError makes the statement, or the commit, fail with err.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	if e.fragment == "" {
+		return e.kind
+	}
+	return fmt.Sprintf("%s %q", e.kind, e.fragment)
+}
+
+// match consumes the next expectation, which must be of kind and, for
+// statements, contain query.
+func (m *Mock) match(kind string, query string, args []driver.NamedValue) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	query = normalize(query)
+	if m.next >= len(m.expected) {
+		m.t.Errorf("sqltest: unexpected %s %q", kind, query)
+		return nil, errors.New("sqltest: unexpected statement")
+	}
+	e := m.expected[m.next]
+	if e.kind != kind || !strings.Contains(query, e.fragment) {
+		m.t.Errorf("sqltest: got %s %q, want %s", kind, query, e)
+		return nil, errors.New("sqltest: unexpected statement")
+	}
+	m.next++
+
+	e.Query = query
+	e.Args = make([]any, len(args))
+	for i, a := range args {
+		e.Args[i] = a.Value
+	}
+	if e.checkArg && !reflect.DeepEqual(e.Args, e.args) {
+		m.t.Errorf("sqltest: %s ran with %v, want %v", e, e.Args, e.args)
+	}
+	return e, e.err
+}
+
+func normalize(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+type connector struct{ m *Mock }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{c.m}, nil }
+func (c connector) Driver() driver.Driver                        { return drv{c.m} }
+
+type drv struct{ m *Mock }
+
+func (d drv) Open(string) (driver.Conn, error) { return &conn{d.m}, nil }
+
+type conn struct{ m *Mock }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("sqltest: prepared statements are not supported")
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if _, err := c.m.match("begin", "", nil); err != nil {
+		return nil, err
+	}
+	return tx{c.m}, nil
+}
+
+// CheckNamedValue passes arguments through unconverted, so tests see what
+// the repository passed.
+func (c *conn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	e, err := c.m.match("query", query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: e.columns, values: e.rows}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, err := c.m.match("exec", query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(e.affected), nil
+}
+
+type tx struct{ m *Mock }
+
+func (t tx) Commit() error {
+	_, err := t.m.match("commit", "", nil)
+	return err
+}
+
+func (t tx) Rollback() error {
+	_, err := t.m.match("rollback", "", nil)
+	return err
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
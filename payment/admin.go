@@ -0,0 +1,237 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Adjustment directions.
+const (
+	AdjustmentCredit = "credit"
+	AdjustmentDebit  = "debit"
+)
+
+// Adjustment statuses.
+const (
+	AdjustmentPending  = "pending"
+	AdjustmentApplied  = "applied"
+	AdjustmentRejected = "rejected"
+)
+
+// Audited admin actions.
+const (
+	AuditWalletFreeze      = "wallet.freeze"
+	AuditWalletUnfreeze    = "wallet.unfreeze"
+	AuditAdjustmentRequest = "adjustment.request"
+	AuditAdjustmentApprove = "adjustment.approve"
+	AuditAdjustmentReject  = "adjustment.reject"
+	AuditAccountErase      = "account.erase" // Recorded by data-subject erasure rather than an admin.
+)
+
+var (
+	ErrWalletFrozen         = errors.New("wallet is frozen")
+	ErrMissingActor         = errors.New("the acting admin must be identified")
+	ErrMissingReason        = errors.New("a reason is required")
+	ErrInvalidDirection     = errors.New("adjustment direction must be credit or debit")
+	ErrAdjustmentNotFound   = errors.New("adjustment not found")
+	ErrAdjustmentNotPending = errors.New("adjustment has already been reviewed")
+	ErrSelfApproval         = errors.New("adjustments must be approved by someone other than the requester")
+)
+
+// Adjustment is a manual credit or debit posted to a wallet by ops staff.
+type Adjustment struct {
+	ID          string
+	AccountID   string
+	Currency    string
+	Direction   string
+	Amount      float64
+	Reason      string
+	Status      string
+	RequestedBy string
+	ReviewedBy  string
+	ReviewNote  string
+	CreatedAt   time.Time
+	ReviewedAt  *time.Time
+}
+
+// AuditEntry records an admin action. Entries are never updated or deleted.
+type AuditEntry struct {
+	ID        int64
+	Actor     string
+	Action    string
+	AccountID string
+	TargetID  string
+	Details   string
+	CreatedAt time.Time
+}
+
+// AdminService lets ops staff freeze wallets and post adjustments. Adjustments
+// above the approval threshold wait for a second admin, and every action is
+// written to the audit log.
+type AdminService interface {
+	FreezeWallet(ctx context.Context, actor string, accountID string, reason string) error
+	UnfreezeWallet(ctx context.Context, actor string, accountID string, reason string) error
+	RequestAdjustment(ctx context.Context, actor string, adj Adjustment) (*Adjustment, error)
+	ReviewAdjustment(ctx context.Context, actor string, adjustmentID string, approve bool, note string) (*Adjustment, error)
+	ListAdjustments(ctx context.Context, status string) ([]Adjustment, error)
+	ListAuditLog(ctx context.Context, accountID string, limit int) ([]AuditEntry, error)
+}
+
+// BalanceChecker runs the low-balance alert for an account whose wallet changed.
+// Service implements it.
+type BalanceChecker interface {
+	CheckBalanceAlert(ctx context.Context, accountID string) error
+}
+
+type adminService struct {
+	repo              Repository
+	fx                FXProvider
+	approvalThreshold float64
+	balances          BalanceChecker
+}
+
+// NewAdminService creates an AdminService. Adjustments worth more than
+// approvalThreshold in the base currency need maker-checker approval. balances
+// is told about every adjustment applied to a base currency wallet.
+func NewAdminService(repo Repository, fx FXProvider, approvalThreshold float64, balances BalanceChecker) AdminService {
+	return &adminService{repo, fx, approvalThreshold, balances}
+}
+
+// FreezeWallet stops all debits from a wallet, e.g. during a fraud investigation.
+func (s *adminService) FreezeWallet(ctx context.Context, actor string, accountID string, reason string) error {
+	return s.setFrozen(ctx, actor, accountID, reason, true)
+}
+
+// UnfreezeWallet lifts a wallet freeze.
+func (s *adminService) UnfreezeWallet(ctx context.Context, actor string, accountID string, reason string) error {
+	return s.setFrozen(ctx, actor, accountID, reason, false)
+}
+
+func (s *adminService) setFrozen(ctx context.Context, actor string, accountID string, reason string, frozen bool) error {
+	if actor == "" {
+		return ErrMissingActor
+	}
+	if reason == "" {
+		return ErrMissingReason
+	}
+
+	action := AuditWalletUnfreeze
+	if frozen {
+		action = AuditWalletFreeze
+	}
+	return s.repo.SetWalletFrozen(ctx, accountID, frozen, AuditEntry{
+		Actor:     actor,
+		Action:    action,
+		AccountID: accountID,
+		Details:   reason,
+	})
+}
+
+// RequestAdjustment posts a manual adjustment. Adjustments up to the approval
+// threshold are applied at once; larger ones stay pending until reviewed.
+func (s *adminService) RequestAdjustment(ctx context.Context, actor string, adj Adjustment) (*Adjustment, error) {
+	if actor == "" {
+		return nil, ErrMissingActor
+	}
+	if adj.Direction != AdjustmentCredit && adj.Direction != AdjustmentDebit {
+		return nil, ErrInvalidDirection
+	}
+	if adj.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if adj.Reason == "" {
+		return nil, ErrMissingReason
+	}
+
+	adj.Currency = normalizeCurrency(adj.Currency)
+	value, _, err := convert(ctx, s.fx, adj.Amount, adj.Currency, BaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	adj.ID = uuid.NewString()
+	adj.Status = AdjustmentPending
+	adj.RequestedBy = actor
+	adj.CreatedAt = time.Now()
+	if value <= s.approvalThreshold {
+		adj.Status = AdjustmentApplied
+	}
+
+	err = s.repo.CreateAdjustment(ctx, adj, AuditEntry{
+		Actor:     actor,
+		Action:    AuditAdjustmentRequest,
+		AccountID: adj.AccountID,
+		TargetID:  adj.ID,
+		Details:   fmt.Sprintf("%s %s %.2f (%s): %s", adj.Direction, adj.Currency, adj.Amount, adj.Status, adj.Reason),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.afterAdjustment(ctx, &adj)
+	return &adj, nil
+}
+
+// ReviewAdjustment approves and applies, or rejects, a pending adjustment. The
+// reviewer must not be the admin who requested it.
+func (s *adminService) ReviewAdjustment(ctx context.Context, actor string, adjustmentID string, approve bool, note string) (*Adjustment, error) {
+	if actor == "" {
+		return nil, ErrMissingActor
+	}
+
+	adj, err := s.repo.GetAdjustment(ctx, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+	if adj.Status != AdjustmentPending {
+		return nil, ErrAdjustmentNotPending
+	}
+	if adj.RequestedBy == actor {
+		return nil, ErrSelfApproval
+	}
+
+	status, action := AdjustmentRejected, AuditAdjustmentReject
+	if approve {
+		status, action = AdjustmentApplied, AuditAdjustmentApprove
+	}
+	reviewed, err := s.repo.ReviewAdjustment(ctx, adjustmentID, status, actor, note, AuditEntry{
+		Actor:     actor,
+		Action:    action,
+		AccountID: adj.AccountID,
+		TargetID:  adj.ID,
+		Details:   note,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.afterAdjustment(ctx, reviewed)
+	return reviewed, nil
+}
+
+// afterAdjustment runs the low-balance alert once an adjustment has been posted
+// to the base currency wallet, without failing the adjustment.
+func (s *adminService) afterAdjustment(ctx context.Context, adj *Adjustment) {
+	if adj.Status != AdjustmentApplied || adj.Currency != BaseCurrency {
+		return
+	}
+	if err := s.balances.CheckBalanceAlert(ctx, adj.AccountID); err != nil {
+		log.Printf("Failed to check low balance for account %s: %v", adj.AccountID, err)
+	}
+}
+
+func (s *adminService) ListAdjustments(ctx context.Context, status string) ([]Adjustment, error) {
+	return s.repo.ListAdjustments(ctx, status)
+}
+
+// ListAuditLog returns the most recent audit entries, for one account or all
+// accounts if accountID is empty.
+func (s *adminService) ListAuditLog(ctx context.Context, accountID string, limit int) ([]AuditEntry, error) {
+	if limit <= 0 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+	return s.repo.ListAuditEntries(ctx, accountID, limit)
+}
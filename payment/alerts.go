@@ -0,0 +1,124 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoBalanceAlert      = errors.New("account has no low-balance alert")
+	ErrInvalidBalanceAlert = errors.New("auto-recharge requires a positive amount and a saved gateway mandate")
+)
+
+// BalanceAlert notifies a merchant when their wallet balance falls below the
+// threshold and, if an auto-recharge amount is set, tops the wallet up by
+// charging their saved gateway mandate.
+type BalanceAlert struct {
+	AccountID          string
+	Threshold          float64
+	AutoRechargeAmount float64 // Zero disables auto-recharge.
+	MandateID          string
+	Triggered          bool // Set while the balance is below the threshold, so each dip alerts once.
+	UpdatedAt          time.Time
+}
+
+// AutoRecharge reports whether the alert tops up the wallet.
+func (a *BalanceAlert) AutoRecharge() bool {
+	return a.AutoRechargeAmount > 0
+}
+
+func (s *paymentService) GetBalanceAlert(ctx context.Context, accountID string) (*BalanceAlert, error) {
+	return s.repo.GetBalanceAlert(ctx, accountID)
+}
+
+// UpdateBalanceAlert sets a merchant's low-balance threshold and auto-recharge.
+func (s *paymentService) UpdateBalanceAlert(ctx context.Context, alert BalanceAlert) (*BalanceAlert, error) {
+	if alert.AccountID == "" {
+		return nil, errors.New("account id is required")
+	}
+	if alert.AutoRechargeAmount < 0 || (alert.AutoRecharge() && alert.MandateID == "") {
+		return nil, ErrInvalidBalanceAlert
+	}
+	return s.repo.PutBalanceAlert(ctx, alert)
+}
+
+// checkBalance compares a wallet's new balance against the account's alert. It
+// notifies and auto-recharges once when the balance crosses below the
+// threshold, and re-arms the alert once the balance is back above it.
+func (s *paymentService) checkBalance(ctx context.Context, accountID string, balance float64) error {
+	alert, err := s.repo.GetBalanceAlert(ctx, accountID)
+	if errors.Is(err, ErrNoBalanceAlert) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	below := balance < alert.Threshold
+	if below == alert.Triggered {
+		return nil
+	}
+	// Only the caller that flips the flag acts on the crossing.
+	changed, err := s.repo.SetBalanceAlertTriggered(ctx, accountID, below)
+	if err != nil || !changed || !below {
+		return err
+	}
+
+	s.notify(ctx, accountID, "Low wallet balance",
+		fmt.Sprintf("Your wallet balance of %.2f is below %.2f. Recharge to keep shipping.", balance, alert.Threshold))
+	if !alert.AutoRecharge() {
+		return nil
+	}
+
+	intent := RechargeIntent{
+		ID:             uuid.NewString(),
+		AccountID:      accountID,
+		Amount:         alert.AutoRechargeAmount,
+		Currency:       BaseCurrency,
+		WalletCurrency: BaseCurrency,
+		CreditAmount:   alert.AutoRechargeAmount,
+		FXRate:         1,
+		Status:         RechargeStatusPending,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	checkoutRef, err := s.gateway.ChargeMandate(ctx, alert.MandateID, intent.ID, accountID, intent.Amount, intent.Currency)
+	if err != nil {
+		s.notify(ctx, accountID, "Auto-recharge failed",
+			fmt.Sprintf("We could not charge your saved mandate for %.2f. Please recharge manually.", intent.Amount))
+		return err
+	}
+	intent.CheckoutRef = checkoutRef
+	return s.repo.CreateRechargeIntent(ctx, intent)
+}
+
+// CheckBalanceAlert runs the low-balance check against the account's current
+// base wallet balance. It is for changes that do not return the new balance,
+// such as captures, subscription charges, remittances and adjustments.
+func (s *paymentService) CheckBalanceAlert(ctx context.Context, accountID string) error {
+	balance, err := s.repo.GetBalance(ctx, accountID, BaseCurrency)
+	if err != nil {
+		return err
+	}
+	return s.checkBalance(ctx, accountID, balance)
+}
+
+// afterBalanceChange runs the low-balance check without failing the wallet
+// operation that changed the balance.
+func (s *paymentService) afterBalanceChange(ctx context.Context, accountID string, balance float64) {
+	if err := s.checkBalance(ctx, accountID, balance); err != nil {
+		log.Printf("Failed to check low balance for account %s: %v", accountID, err)
+	}
+}
+
+// refreshBalanceAlert is afterBalanceChange for operations that do not return
+// the new balance.
+func (s *paymentService) refreshBalanceAlert(ctx context.Context, accountID string) {
+	if err := s.CheckBalanceAlert(ctx, accountID); err != nil {
+		log.Printf("Failed to check low balance for account %s: %v", accountID, err)
+	}
+}
@@ -2,24 +2,30 @@ package payment
 
 import (
 	"context"
+	"database/sql"
+	"io"
+	"strings"
+	"time"
 
-	"google.golang.org/grpc"
 	"github.com/Shridhar2104/logilo/payment/pb"
+	"google.golang.org/grpc"
 )
 
-
+// Client calls the payment service over gRPC.
 type Client struct {
-	conn *grpc.ClientConn
+	conn    *grpc.ClientConn
 	service pb.PaymentServiceClient
 }
 
-func NewClient(url string) (*Client, error) {
-	conn, err := grpc.Dial(url, grpc.WithInsecure())
+// NewClient connects to the payment service at url. Other services pass
+// account.ServiceCredentials in opts.
+func NewClient(url string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.Dial(url, append([]grpc.DialOption{grpc.WithInsecure()}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
 
-	c:= pb.NewPaymentServiceClient(conn)
+	c := pb.NewPaymentServiceClient(conn)
 	return &Client{conn: conn, service: c}, nil
 }
 
@@ -27,18 +33,489 @@ func (c *Client) Close() {
 	c.conn.Close()
 }
 
-func (c *Client) RechargeWallet(ctx context.Context, userId string, amount float64) (float64, error) {
-	
+// RechargeWallet adds amount, paid in currency, to a merchant's wallet in
+// walletCurrency and returns the new balance. It is an internal RPC: the
+// payment service only accepts it from staff tokens granting
+// account.PermissionInternal.
+func (c *Client) RechargeWallet(ctx context.Context, accountID string, amount float64, currency string, walletCurrency string) (float64, error) {
+	res, err := c.service.RechargeWallet(ctx, &pb.RechargeRequest{
+		UserId:         accountID,
+		Amount:         amount,
+		Currency:       currency,
+		WalletCurrency: walletCurrency,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.NewBalance, nil
+}
+
+// DeductBalance charges a merchant's wallet in currency for shipping an order,
+// at the rate card price less their plan's discount, and returns the new balance.
+func (c *Client) DeductBalance(ctx context.Context, accountID string, amount float64, currency string, orderID string) (float64, error) {
+	res, err := c.service.DeductBalance(ctx, &pb.DeductionRequest{
+		UserId:   accountID,
+		Amount:   amount,
+		OrderId:  orderID,
+		Currency: currency,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.NewBalance, nil
+}
+
+// ProcessRemittance remits the COD collected for the given orders to a merchant's wallet.
+func (c *Client) ProcessRemittance(ctx context.Context, accountID string, orderIDs []string) ([]RemittanceDetail, error) {
+	res, err := c.service.ProcessRemittance(ctx, &pb.RemittanceRequest{
+		UserId:   accountID,
+		OrderIds: orderIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]RemittanceDetail, len(res.Details))
+	for i, d := range res.Details {
+		details[i] = RemittanceDetail{OrderID: d.OrderId, Amount: d.Amount, Processed: d.Processed}
+	}
+	return details, nil
+}
+
+// GetWalletDetails fetches the balance of each of a merchant's wallets and one
+// page of transactions matching filter, along with the cursor for the next page.
+func (c *Client) GetWalletDetails(ctx context.Context, accountID string, filter TransactionFilter) ([]WalletBalance, []Transaction, string, error) {
+	req := &pb.WalletDetailsRequest{
+		UserId:          accountID,
+		Currency:        filter.Currency,
+		TransactionType: filter.Type,
+		OrderId:         filter.OrderID,
+		Cursor:          filter.Cursor,
+		Limit:           int32(filter.Limit),
+	}
+	if !filter.From.IsZero() {
+		req.From = filter.From.Format(time.RFC3339)
+	}
+	if !filter.To.IsZero() {
+		req.To = filter.To.Format(time.RFC3339)
+	}
+
+	res, err := c.service.GetWalletDetails(ctx, req)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	balances := make([]WalletBalance, len(res.Balances))
+	for i, b := range res.Balances {
+		balances[i] = WalletBalance{Currency: b.Currency, Balance: b.Balance}
+	}
+
+	transactions := make([]Transaction, len(res.TransactionHistory))
+	for i, t := range res.TransactionHistory {
+		timestamp, _ := time.Parse(time.RFC3339, t.Timestamp)
+		transactions[i] = Transaction{
+			TransactionID:   t.TransactionId,
+			TransactionType: t.TransactionType,
+			Currency:        t.Currency,
+			Amount:          t.Amount,
+			OrderID:         sql.NullString{String: t.OrderId, Valid: t.OrderId != ""},
+			Reference:       sql.NullString{String: t.Reference, Valid: t.Reference != ""},
+			BalanceAfter:    t.BalanceAfter,
+			Timestamp:       timestamp,
+		}
+		if t.SourceCurrency != "" {
+			transactions[i].FX = &FXConversion{SourceAmount: t.SourceAmount, SourceCurrency: t.SourceCurrency, Rate: t.FxRate}
+		}
+	}
+	return balances, transactions, res.NextCursor, nil
+}
+
+// ExportStatement writes a merchant's CSV statement of the wallet in currency
+// for the month to w.
+func (c *Client) ExportStatement(ctx context.Context, accountID string, currency string, month time.Time, w io.Writer) error {
+	stream, err := c.service.ExportStatement(ctx, &pb.ExportStatementRequest{
+		UserId:   accountID,
+		Month:    month.Format("2006-01"),
+		Currency: currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return err
+		}
+	}
+}
+
+// StreamWalletEvents calls handle for each wallet event after afterSeq, for one
+// account or all accounts if accountID is empty, until ctx is cancelled or
+// handle fails. A negative afterSeq starts from the next new event. After a
+// disconnect, call it again with the Seq of the last event handled.
+func (c *Client) StreamWalletEvents(ctx context.Context, accountID string, afterSeq int64, handle func(WalletEvent) error) error {
+	req := &pb.StreamWalletEventsRequest{UserId: accountID, AfterSeq: afterSeq}
+	if afterSeq < 0 {
+		req.AfterSeq, req.Tail = 0, true
+	}
+	stream, err := c.service.StreamWalletEvents(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		createdAt, _ := time.Parse(time.RFC3339, e.CreatedAt)
+		err = handle(WalletEvent{
+			Seq:          e.Seq,
+			AccountID:    e.UserId,
+			Currency:     e.Currency,
+			Type:         e.Type,
+			Amount:       e.Amount,
+			BalanceAfter: e.BalanceAfter,
+			OrderID:      e.OrderId,
+			Reference:    e.Reference,
+			CreatedAt:    createdAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// CreateRechargeIntent starts a gateway-backed recharge of amount in currency
+// into the wallet in walletCurrency.
+func (c *Client) CreateRechargeIntent(ctx context.Context, accountID string, amount float64, currency string, walletCurrency string) (*RechargeIntent, error) {
+	res, err := c.service.CreateRechargeIntent(ctx, &pb.CreateRechargeIntentRequest{
+		UserId:         accountID,
+		Amount:         amount,
+		Currency:       currency,
+		WalletCurrency: walletCurrency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRechargeIntent(res.Intent), nil
+}
+
+// ListRemittanceBatches fetches a merchant's remittance batches.
+func (c *Client) ListRemittanceBatches(ctx context.Context, accountID string) ([]RemittanceBatch, error) {
+	res, err := c.service.ListRemittanceBatches(ctx, &pb.ListRemittanceBatchesRequest{UserId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]RemittanceBatch, len(res.Batches))
+	for i, b := range res.Batches {
+		batches[i] = *fromProtoRemittanceBatch(b)
+	}
+	return batches, nil
+}
+
+// UpdateRemittanceBatch approves, pays or fails a remittance batch.
+func (c *Client) UpdateRemittanceBatch(ctx context.Context, batchID string, status string, utr string, reason string) (*RemittanceBatch, error) {
+	res, err := c.service.UpdateRemittanceBatch(ctx, &pb.UpdateRemittanceBatchRequest{
+		BatchId:       batchID,
+		Status:        status,
+		Utr:           utr,
+		FailureReason: reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRemittanceBatch(res.Batch), nil
+}
+
+// RequestEarlyRemittance settles a merchant's delivered COD ahead of the cycle.
+// It returns nil if nothing is eligible.
+func (c *Client) RequestEarlyRemittance(ctx context.Context, accountID string) (*RemittanceBatch, error) {
+	res, err := c.service.RequestEarlyRemittance(ctx, &pb.EarlyRemittanceRequest{UserId: accountID})
+	if err != nil || res.Batch == nil {
+		return nil, err
+	}
+	return fromProtoRemittanceBatch(res.Batch), nil
+}
+
+// GetRemittanceSettings fetches where a merchant's remittances are sent.
+func (c *Client) GetRemittanceSettings(ctx context.Context, accountID string) (*RemittanceSettings, error) {
+	res, err := c.service.GetRemittanceSettings(ctx, &pb.GetRemittanceSettingsRequest{UserId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRemittanceSettings(accountID, res), nil
+}
+
+// UpdateRemittanceSettings sets where a merchant's remittances are sent.
+func (c *Client) UpdateRemittanceSettings(ctx context.Context, accountID string, destination string, bank *BankAccount) (*RemittanceSettings, error) {
+	req := &pb.UpdateRemittanceSettingsRequest{
+		UserId:      accountID,
+		Destination: destination,
+	}
+	if bank != nil {
+		req.Bank = &pb.BankAccount{
+			AccountNumber:   bank.AccountNumber,
+			Ifsc:            bank.IFSC,
+			BeneficiaryName: bank.BeneficiaryName,
+		}
+	}
+
+	res, err := c.service.UpdateRemittanceSettings(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoRemittanceSettings(accountID, res), nil
+}
+
+// ListPayouts fetches a merchant's bank payouts.
+func (c *Client) ListPayouts(ctx context.Context, accountID string) ([]Payout, error) {
+	res, err := c.service.ListPayouts(ctx, &pb.ListPayoutsRequest{UserId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	payouts := make([]Payout, len(res.Payouts))
+	for i, p := range res.Payouts {
+		createdAt, _ := time.Parse(time.RFC3339, p.CreatedAt)
+		payouts[i] = Payout{
+			ID:        p.PayoutId,
+			BatchID:   p.BatchId,
+			AccountID: accountID,
+			Amount:    p.Amount,
+			Status:    p.Status,
+			UTR:       p.Utr,
+			Attempts:  int(p.Attempts),
+			LastError: p.LastError,
+			CreatedAt: createdAt,
+		}
+	}
+	return payouts, nil
 }
 
-func (c *Client) DeductBalance(ctx context.Context, userId string, amount float64, orderID string) (float64, error) {
+// GetCreditLine fetches a merchant's postpaid credit line.
+func (c *Client) GetCreditLine(ctx context.Context, accountID string) (*CreditLine, error) {
+	res, err := c.service.GetCreditLine(ctx, &pb.GetCreditLineRequest{UserId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoCreditLine(res.CreditLine), nil
+}
 
+// SetCreditLine grants or updates a merchant's postpaid credit line.
+func (c *Client) SetCreditLine(ctx context.Context, line CreditLine) (*CreditLine, error) {
+	res, err := c.service.SetCreditLine(ctx, &pb.SetCreditLineRequest{
+		UserId:    line.AccountID,
+		Limit:     line.Limit,
+		CycleDays: int32(line.CycleDays),
+		TermsDays: int32(line.TermsDays),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoCreditLine(res.CreditLine), nil
 }
-func (c *Client) ProcessRemittance(ctx context.Context, userId string, amount float64, orderID string) (float64, error) {
 
+// ListCreditBills fetches the bills issued against a merchant's credit line.
+func (c *Client) ListCreditBills(ctx context.Context, accountID string) ([]CreditBill, error) {
+	res, err := c.service.ListCreditBills(ctx, &pb.ListCreditBillsRequest{UserId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	bills := make([]CreditBill, len(res.Bills))
+	for i, b := range res.Bills {
+		periodStart, _ := time.Parse(time.RFC3339, b.PeriodStart)
+		periodEnd, _ := time.Parse(time.RFC3339, b.PeriodEnd)
+		dueDate, _ := time.Parse(time.RFC3339, b.DueDate)
+		bills[i] = CreditBill{
+			ID:          b.BillId,
+			AccountID:   b.UserId,
+			Amount:      b.Amount,
+			PaidAmount:  b.PaidAmount,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			DueDate:     dueDate,
+			Status:      b.Status,
+		}
+	}
+	return bills, nil
+}
+
+// GetBalanceAlert fetches a merchant's low-balance alert.
+func (c *Client) GetBalanceAlert(ctx context.Context, accountID string) (*BalanceAlert, error) {
+	res, err := c.service.GetBalanceAlert(ctx, &pb.GetBalanceAlertRequest{UserId: accountID})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoBalanceAlert(res.Alert), nil
+}
+
+// UpdateBalanceAlert sets a merchant's low-balance threshold and auto-recharge.
+func (c *Client) UpdateBalanceAlert(ctx context.Context, alert BalanceAlert) (*BalanceAlert, error) {
+	res, err := c.service.UpdateBalanceAlert(ctx, &pb.UpdateBalanceAlertRequest{
+		Alert: toProtoBalanceAlert(&alert),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoBalanceAlert(res.Alert), nil
 }
 
+// ListPlans fetches the pricing plans merchants can subscribe to.
+func (c *Client) ListPlans(ctx context.Context) ([]Plan, error) {
+	res, err := c.service.ListPlans(ctx, &pb.ListPlansRequest{})
+	if err != nil {
+		return nil, err
+	}
 
-func (c *Client) GetWalletDetails(ctx context.Context, userId string) (*pb.WalletDetailsResponse, error) {
+	plans := make([]Plan, len(res.Plans))
+	for i, p := range res.Plans {
+		plans[i] = *fromProtoPlan(p)
+	}
+	return plans, nil
+}
 
-}
\ No newline at end of file
+// GetAccountPlan fetches a merchant's subscription and the plan in effect, whose
+// parameters drive rate card discounts and remittance. The subscription is nil
+// for merchants who never subscribed.
+func (c *Client) GetAccountPlan(ctx context.Context, accountID string) (*Subscription, *Plan, error) {
+	res, err := c.service.GetAccountPlan(ctx, &pb.GetAccountPlanRequest{UserId: accountID})
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromProtoSubscription(accountID, res.Subscription), fromProtoPlan(res.Plan), nil
+}
+
+// APIAccessAllowed reports whether the merchant's plan includes API access
+func (c *Client) APIAccessAllowed(ctx context.Context, accountID string) (bool, error) {
+	_, plan, err := c.GetAccountPlan(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+	return plan.HasFeature(FeatureAPIAccess), nil
+}
+
+// ChangePlan moves a merchant to another plan, prorating the monthly fee.
+func (c *Client) ChangePlan(ctx context.Context, accountID string, planCode string) (*Subscription, *Plan, error) {
+	res, err := c.service.ChangePlan(ctx, &pb.ChangePlanRequest{UserId: accountID, PlanCode: planCode})
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromProtoSubscription(accountID, res.Subscription), fromProtoPlan(res.Plan), nil
+}
+
+func fromProtoPlan(p *pb.Plan) *Plan {
+	cycle, _ := ParseRemittanceCycle(int(p.RemittanceDelayDays), strings.Join(p.RemittanceWeekdays, ","))
+	cycle.EarlyFeePercent = p.EarlyRemittanceFeePercent
+
+	return &Plan{
+		Code:                p.Code,
+		Name:                p.Name,
+		MonthlyFee:          p.MonthlyFee,
+		RateDiscountPercent: p.RateDiscountPercent,
+		Cycle:               cycle,
+		Features:            p.Features,
+	}
+}
+
+func fromProtoSubscription(accountID string, sub *pb.Subscription) *Subscription {
+	if sub == nil {
+		return nil
+	}
+	periodStart, _ := time.Parse(time.RFC3339, sub.PeriodStart)
+	periodEnd, _ := time.Parse(time.RFC3339, sub.PeriodEnd)
+	return &Subscription{
+		AccountID:   accountID,
+		PlanCode:    sub.PlanCode,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Status:      sub.Status,
+	}
+}
+
+func fromProtoRechargeIntent(intent *pb.RechargeIntent) *RechargeIntent {
+	createdAt, _ := time.Parse(time.RFC3339, intent.CreatedAt)
+	return &RechargeIntent{
+		ID:             intent.IntentId,
+		AccountID:      intent.UserId,
+		Amount:         intent.Amount,
+		Currency:       intent.Currency,
+		WalletCurrency: intent.WalletCurrency,
+		CreditAmount:   intent.CreditAmount,
+		FXRate:         intent.FxRate,
+		Status:         intent.Status,
+		CheckoutRef:    intent.CheckoutRef,
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}
+}
+
+func fromProtoRemittanceBatch(b *pb.RemittanceBatch) *RemittanceBatch {
+	createdAt, _ := time.Parse(time.RFC3339, b.CreatedAt)
+	items := make([]RemittanceDetail, len(b.Items))
+	for i, item := range b.Items {
+		items[i] = RemittanceDetail{OrderID: item.OrderId, Amount: item.Amount, Processed: item.Processed}
+	}
+
+	return &RemittanceBatch{
+		ID:            b.BatchId,
+		AccountID:     b.UserId,
+		Status:        b.Status,
+		TotalAmount:   b.TotalAmount,
+		Fee:           b.Fee,
+		Items:         items,
+		UTR:           b.Utr,
+		FailureReason: b.FailureReason,
+		CreatedAt:     createdAt,
+	}
+}
+
+func fromProtoRemittanceSettings(accountID string, res *pb.RemittanceSettingsResponse) *RemittanceSettings {
+	settings := &RemittanceSettings{
+		AccountID:    accountID,
+		Destination:  res.Destination,
+		BankVerified: res.BankVerified,
+	}
+	if res.Bank != nil {
+		settings.Bank = BankAccount{
+			AccountNumber:   res.Bank.AccountNumber,
+			IFSC:            res.Bank.Ifsc,
+			BeneficiaryName: res.Bank.BeneficiaryName,
+		}
+	}
+	return settings
+}
+
+func fromProtoCreditLine(line *pb.CreditLine) *CreditLine {
+	cycleStart, _ := time.Parse(time.RFC3339, line.CycleStart)
+	return &CreditLine{
+		AccountID:  line.UserId,
+		Limit:      line.Limit,
+		CycleDays:  int(line.CycleDays),
+		TermsDays:  int(line.TermsDays),
+		CycleStart: cycleStart,
+		Frozen:     line.Frozen,
+	}
+}
+
+func fromProtoBalanceAlert(alert *pb.BalanceAlert) *BalanceAlert {
+	return &BalanceAlert{
+		AccountID:          alert.UserId,
+		Threshold:          alert.Threshold,
+		AutoRechargeAmount: alert.AutoRechargeAmount,
+		MandateID:          alert.MandateId,
+		Triggered:          alert.Triggered,
+	}
+}
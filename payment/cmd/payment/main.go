@@ -1,3 +1,181 @@
 package main
 
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
 
+	"github.com/Shridhar2104/logilo/account"
+	dspb "github.com/Shridhar2104/logilo/datasubject/pb"
+	"github.com/Shridhar2104/logilo/payment"
+	"github.com/Shridhar2104/logilo/payment/invoice"
+	"github.com/Shridhar2104/logilo/payment/pb"
+
+	"github.com/kelseyhightower/envconfig"
+	_ "github.com/lib/pq"
+	"github.com/tinrab/retry"
+	"google.golang.org/grpc"
+)
+
+type Config struct {
+	DatabaseURL        string             `envconfig:"DATABASE_PAYMENT_URL"`
+	Port               int                `envconfig:"PAYMENT_SERVICE_PORT" default:"8082"`
+	WebhookPort        int                `envconfig:"PAYMENT_WEBHOOK_PORT" default:"8083"`
+	GatewaySecret      string             `envconfig:"GATEWAY_WEBHOOK_SECRET" required:"true"`
+	ShipmentSecret     string             `envconfig:"SHIPMENT_EVENTS_SECRET" required:"true"`
+	RemittanceDelay    int                `envconfig:"REMITTANCE_DELAY_DAYS" default:"7"`
+	RemittanceDays     string             `envconfig:"REMITTANCE_WEEKDAYS" default:"tue,fri"`
+	EarlyRemittanceFee float64            `envconfig:"EARLY_REMITTANCE_FEE_PERCENT" default:"1.5"`
+	ApprovalThreshold  float64            `envconfig:"ADJUSTMENT_APPROVAL_THRESHOLD" default:"10000"`
+	FXRates            map[string]float64 `envconfig:"FX_RATES"` // INR per unit, e.g. "USD:83.25,AED:22.66".
+	SupplierName       string             `envconfig:"INVOICE_SUPPLIER_NAME" required:"true"`
+	SupplierGSTIN      string             `envconfig:"INVOICE_SUPPLIER_GSTIN" required:"true"`
+	SupplierAddress    string             `envconfig:"INVOICE_SUPPLIER_ADDRESS"`
+	TokenSecret        string             `envconfig:"AUTH_TOKEN_SECRET" required:"true"` // Verifies access tokens forwarded by the gateway
+	StaffSecret        string             `envconfig:"STAFF_TOKEN_SECRET" required:"true"` // Signs and verifies staff and service tokens
+	AccountURL         string             `envconfig:"ACCOUNT_URL" required:"true"`       // Checks merchants' KYC before remittances and payouts
+}
+
+// billingPolicies authenticates every RPC but the gateway webhook, which is
+// signed. Payment requests name the merchant's account as user_id. Staff and
+// other services call the rest with staff tokens. Merchants fund their wallets
+// through recharge intents, so RechargeWallet is internal, and shipping charges
+// are priced by the rate engine, so DeductBalance is too.
+var billingPolicies = map[string]account.Policy{
+	pb.PaymentService_GetWalletDetails_FullMethodName:         {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_ExportStatement_FullMethodName:          {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_ListRemittanceBatches_FullMethodName:    {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_GetRemittanceSettings_FullMethodName:    {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_ListPayouts_FullMethodName:              {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_ListInvoices_FullMethodName:             {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_GetInvoicePDF_FullMethodName:            {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_GetBillingProfile_FullMethodName:        {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_GetCreditLine_FullMethodName:            {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_ListCreditBills_FullMethodName:          {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_GetBalanceAlert_FullMethodName:          {Permission: account.PermissionViewBilling, Account: requestUserID},
+	pb.PaymentService_GetAccountPlan_FullMethodName:           {Permission: account.PermissionViewBilling, Account: requestUserID, Internal: true},
+	pb.PaymentService_CreateRechargeIntent_FullMethodName:     {Permission: account.PermissionManageBilling, Account: requestUserID},
+	pb.PaymentService_RequestEarlyRemittance_FullMethodName:   {Permission: account.PermissionManageBilling, Account: requestUserID},
+	pb.PaymentService_UpdateRemittanceSettings_FullMethodName: {Permission: account.PermissionManageBilling, Account: requestUserID},
+	pb.PaymentService_ChangePlan_FullMethodName:               {Permission: account.PermissionManageBilling, Account: requestUserID},
+	pb.PaymentService_PutBillingProfile_FullMethodName: {Permission: account.PermissionManageBilling, Account: func(req any) string {
+		return req.(*pb.PutBillingProfileRequest).GetProfile().GetUserId()
+	}},
+	pb.PaymentService_UpdateBalanceAlert_FullMethodName: {Permission: account.PermissionManageBilling, Account: func(req any) string {
+		return req.(*pb.UpdateBalanceAlertRequest).GetAlert().GetUserId()
+	}},
+	pb.PaymentService_ListPlans_FullMethodName: {},
+
+	pb.PaymentService_RechargeWallet_FullMethodName:          {Permission: account.PermissionInternal},
+	pb.PaymentService_DeductBalance_FullMethodName:           {Permission: account.PermissionInternal},
+	pb.PaymentService_ProcessRemittance_FullMethodName:       {Permission: account.PermissionInternal},
+	pb.PaymentService_StreamWalletEvents_FullMethodName:      {Permission: account.PermissionInternal},
+	dspb.DataSubjectService_ExportSubjectData_FullMethodName: {Permission: account.PermissionInternal},
+	dspb.DataSubjectService_EraseSubjectData_FullMethodName:  {Permission: account.PermissionInternal},
+
+	pb.PaymentService_CreateRemittanceBatches_FullMethodName: {Permission: account.PermissionOperateBilling},
+	pb.PaymentService_UpdateRemittanceBatch_FullMethodName:   {Permission: account.PermissionOperateBilling},
+	pb.PaymentService_ImportCourierStatement_FullMethodName:  {Permission: account.PermissionOperateBilling},
+	pb.PaymentService_GenerateInvoices_FullMethodName:        {Permission: account.PermissionOperateBilling},
+	pb.PaymentService_SetCreditLine_FullMethodName:           {Permission: account.PermissionOperateBilling},
+
+	pb.PaymentService_FreezeWallet_FullMethodName:      {Permission: account.PermissionAdminWallets},
+	pb.PaymentService_UnfreezeWallet_FullMethodName:    {Permission: account.PermissionAdminWallets},
+	pb.PaymentService_RequestAdjustment_FullMethodName: {Permission: account.PermissionAdminWallets},
+	pb.PaymentService_ReviewAdjustment_FullMethodName:  {Permission: account.PermissionAdminWallets},
+	pb.PaymentService_ListAdjustments_FullMethodName:   {Permission: account.PermissionAdminWallets},
+	pb.PaymentService_ListAuditLog_FullMethodName:      {Permission: account.PermissionAdminWallets},
+}
+
+func requestUserID(req any) string {
+	if r, ok := req.(interface{ GetUserId() string }); ok {
+		return r.GetUserId()
+	}
+	return ""
+}
+
+func main() {
+
+	var cfg Config
+	if err := envconfig.Process("", &cfg); err != nil {
+		log.Fatalf("Failed to process environment variables: %v", err)
+	}
+
+	cycle, err := payment.ParseRemittanceCycle(cfg.RemittanceDelay, cfg.RemittanceDays)
+	if err != nil {
+		log.Fatalf("Invalid remittance cycle: %v", err)
+	}
+	cycle.EarlyFeePercent = cfg.EarlyRemittanceFee
+
+	if err := invoice.ValidateGSTIN(cfg.SupplierGSTIN); err != nil {
+		log.Fatalf("Invalid supplier GSTIN: %v", err)
+	}
+	supplier := invoice.Supplier{
+		Name:      cfg.SupplierName,
+		GSTIN:     cfg.SupplierGSTIN,
+		Address:   cfg.SupplierAddress,
+		StateCode: cfg.SupplierGSTIN[:2],
+	}
+
+	var db *sql.DB
+	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+
+		db, err = sql.Open("postgres", cfg.DatabaseURL)
+		if err == nil {
+			err = db.Ping()
+		}
+		if err != nil {
+			log.Printf("Failed to connect to database: %v", err)
+			return err
+		}
+		return nil
+	})
+	r := payment.NewPostgresRepository(db)
+	defer r.Close()
+
+	rates := cfg.FXRates
+	if len(rates) == 0 {
+		rates = payment.DefaultFXRates
+	}
+	fx := payment.NewStaticFXProvider(rates)
+
+	tokens, err := account.NewTokenManager(cfg.TokenSecret, cfg.StaffSecret, 0, 0)
+	if err != nil {
+		log.Fatalf("Failed to set up tokens: %v", err)
+	}
+	accounts, err := account.NewClient(cfg.AccountURL, grpc.WithPerRPCCredentials(account.ServiceCredentials(tokens, "payment")))
+	if err != nil {
+		log.Fatalf("Failed to connect to account service: %v", err)
+	}
+	defer accounts.Close()
+
+	plans := payment.DefaultPlanCatalog(cycle)
+	s := payment.NewPaymentService(r, payment.NewLocalGateway(cfg.GatewaySecret), payment.NewLocalPayoutProvider(), fx, plans, payment.NewLogNotifier(), accounts)
+	admin := payment.NewAdminService(r, fx, cfg.ApprovalThreshold, s)
+	invoices := invoice.NewInvoiceService(invoice.NewPostgresRepository(db), supplier, accounts)
+
+	ctx := context.Background()
+	go payment.NewRemittanceScheduler(s).Run(ctx, time.Hour)
+	go payment.NewSubscriptionWorker(s).Run(ctx, time.Hour)
+	go payment.NewPayoutWorker(s).Run(ctx, time.Minute)
+	go payment.NewCreditBillingWorker(s).Run(ctx, time.Hour)
+	go payment.NewWalletEventSequencer(s).Run(ctx, time.Second)
+	go invoice.NewScheduler(invoices).Run(ctx, time.Hour)
+
+	mux := http.NewServeMux()
+	mux.Handle("/shipment-events", payment.NewShipmentEventHandler(s, cfg.ShipmentSecret))
+	mux.Handle("/", payment.NewWebhookHandler(s))
+	go func() {
+		log.Printf("webhook server starting on port %d ...", cfg.WebhookPort)
+		log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", cfg.WebhookPort), mux))
+	}()
+
+	log.Printf("server starting on port %d ...", cfg.Port)
+	log.Fatal(payment.NewGRPCServer(s, admin, invoices, cfg.Port,
+		grpc.UnaryInterceptor(account.UnaryServerInterceptor(tokens, billingPolicies)),
+		grpc.StreamInterceptor(account.StreamServerInterceptor(tokens, billingPolicies)),
+	))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	dspb "github.com/Shridhar2104/logilo/datasubject/pb"
+	"github.com/Shridhar2104/logilo/payment/pb"
+
+	"google.golang.org/grpc"
+)
+
+func TestEveryRPCHasAPolicy(t *testing.T) {
+	methods := map[string]bool{}
+	for _, desc := range []grpc.ServiceDesc{pb.PaymentService_ServiceDesc, dspb.DataSubjectService_ServiceDesc} {
+		for _, m := range desc.Methods {
+			methods["/"+desc.ServiceName+"/"+m.MethodName] = true
+		}
+		for _, s := range desc.Streams {
+			methods["/"+desc.ServiceName+"/"+s.StreamName] = true
+		}
+	}
+
+	for method := range methods {
+		// Gateway webhooks are authenticated by their signature instead.
+		if method == pb.PaymentService_HandleGatewayWebhook_FullMethodName {
+			if _, ok := billingPolicies[method]; ok {
+				t.Errorf("%s is signed but has a policy", method)
+			}
+			continue
+		}
+		if _, ok := billingPolicies[method]; !ok {
+			t.Errorf("%s has no policy", method)
+		}
+	}
+	for method := range billingPolicies {
+		if !methods[method] {
+			t.Errorf("policy for unknown rpc %s", method)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Credit bill statuses.
+const (
+	BillStatusOpen    = "open"
+	BillStatusPaid    = "paid"
+	BillStatusOverdue = "overdue"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient wallet balance")
+	ErrShippingFrozen    = errors.New("shipping is frozen until the overdue credit bill is paid")
+	ErrNoCreditLine      = errors.New("account has no credit line")
+	ErrInvalidCreditLine = errors.New("credit limit must not be negative and cycle and terms must be at least one day")
+)
+
+// CreditLine lets a trusted merchant ship on credit, with the wallet balance
+// allowed to go negative down to the limit and billed at the end of each cycle.
+type CreditLine struct {
+	AccountID  string
+	Limit      float64 // How far the wallet balance may go negative.
+	CycleDays  int     // Length of a billing cycle.
+	TermsDays  int     // Days after a bill is issued before it is due.
+	CycleStart time.Time
+	Frozen     bool // Set while a bill is overdue.
+	UpdatedAt  time.Time
+}
+
+// CycleEnd is when the current billing cycle closes.
+func (c *CreditLine) CycleEnd() time.Time {
+	return c.CycleStart.AddDate(0, 0, c.CycleDays)
+}
+
+// CreditBill is the amount a merchant owes for a closed billing cycle.
+type CreditBill struct {
+	ID            string
+	AccountID     string
+	Amount        float64
+	PaidAmount    float64
+	PeriodStart   time.Time
+	PeriodEnd     time.Time
+	DueDate       time.Time
+	Status        string
+	RemindersSent int
+	CreatedAt     time.Time
+}
+
+// Outstanding is the unpaid part of the bill.
+func (b *CreditBill) Outstanding() float64 {
+	return b.Amount - b.PaidAmount
+}
+
+// checkDebit applies the credit policy to a deduction of amount from a wallet
+// holding balance. Accounts without a credit line must stay in funds, and a
+// deduction must be positive so that it can never credit the wallet.
+func checkDebit(balance float64, amount float64, line *CreditLine) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	var limit float64
+	if line != nil {
+		if line.Frozen {
+			return ErrShippingFrozen
+		}
+		limit = line.Limit
+	}
+	if balance-amount < -limit {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+func (s *paymentService) GetCreditLine(ctx context.Context, accountID string) (*CreditLine, error) {
+	return s.repo.GetCreditLine(ctx, accountID)
+}
+
+// SetCreditLine grants or updates a merchant's credit line. A new line starts
+// its first billing cycle today.
+func (s *paymentService) SetCreditLine(ctx context.Context, line CreditLine) (*CreditLine, error) {
+	if line.AccountID == "" {
+		return nil, errors.New("account id is required")
+	}
+	if line.Limit < 0 || line.CycleDays < 1 || line.TermsDays < 1 {
+		return nil, ErrInvalidCreditLine
+	}
+	plan, err := s.accountPlan(ctx, line.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.HasFeature(FeatureCreditLine) {
+		return nil, ErrFeatureNotInPlan
+	}
+	now := time.Now().UTC()
+	line.CycleStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return s.repo.PutCreditLine(ctx, line)
+}
+
+func (s *paymentService) ListCreditBills(ctx context.Context, accountID string) ([]CreditBill, error) {
+	return s.repo.ListCreditBills(ctx, accountID)
+}
+
+// billReminders are sent this long before a bill is due, in order.
+var billReminders = []time.Duration{3 * 24 * time.Hour, 24 * time.Hour}
+
+// RunCreditBilling closes due billing cycles, sends due-date reminders and
+// freezes shipping for accounts with overdue bills.
+func (s *paymentService) RunCreditBilling(ctx context.Context, now time.Time) error {
+	lines, err := s.repo.ListCreditLinesDue(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		bill, err := s.repo.CloseCreditCycle(ctx, line.AccountID, now)
+		if err != nil {
+			return err
+		}
+		if bill != nil {
+			s.notify(ctx, bill.AccountID, "Credit bill issued",
+				fmt.Sprintf("Your bill of %.2f is due on %s.", bill.Amount, bill.DueDate.Format("02 Jan 2006")))
+		}
+	}
+
+	bills, err := s.repo.ListUnpaidBills(ctx)
+	if err != nil {
+		return err
+	}
+	for _, bill := range bills {
+		if bill.Status == BillStatusOpen && now.After(bill.DueDate) {
+			if err := s.repo.MarkBillOverdue(ctx, bill.ID); err != nil {
+				return err
+			}
+			s.notify(ctx, bill.AccountID, "Credit bill overdue",
+				fmt.Sprintf("Your bill of %.2f is overdue. Shipping is paused until it is paid.", bill.Outstanding()))
+			continue
+		}
+
+		if bill.RemindersSent < len(billReminders) && !now.Before(bill.DueDate.Add(-billReminders[bill.RemindersSent])) {
+			if err := s.repo.MarkBillReminded(ctx, bill.ID, bill.RemindersSent+1); err != nil {
+				return err
+			}
+			s.notify(ctx, bill.AccountID, "Credit bill due soon",
+				fmt.Sprintf("%.2f of your bill is due on %s.", bill.Outstanding(), bill.DueDate.Format("02 Jan 2006")))
+		}
+	}
+	return nil
+}
+
+func (s *paymentService) notify(ctx context.Context, accountID string, subject string, message string) {
+	if err := s.notifier.Notify(ctx, accountID, subject, message); err != nil {
+		log.Printf("Failed to notify account %s: %v", accountID, err)
+	}
+}
+
+// CreditBillingWorker periodically runs credit billing.
+type CreditBillingWorker struct {
+	service Service
+}
+
+// NewCreditBillingWorker creates a worker that runs billing through the service.
+func NewCreditBillingWorker(service Service) *CreditBillingWorker {
+	return &CreditBillingWorker{service: service}
+}
+
+// Run runs credit billing every interval until ctx is cancelled.
+func (w *CreditBillingWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.service.RunCreditBilling(ctx, time.Now()); err != nil {
+			log.Printf("Failed to run credit billing: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckDebit(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance float64
+		amount  float64
+		line    *CreditLine
+		want    error
+	}{
+		{"in funds", 100, 60, nil, nil},
+		{"exact balance", 100, 100, nil, nil},
+		{"overdrawn without credit", 100, 100.01, nil, ErrInsufficientFunds},
+		{"already negative without credit", -5, 1, nil, ErrInsufficientFunds},
+		{"within credit limit", 100, 400, &CreditLine{Limit: 500}, nil},
+		{"down to credit limit", -200, 300, &CreditLine{Limit: 500}, nil},
+		{"beyond credit limit", -200, 300.01, &CreditLine{Limit: 500}, ErrInsufficientFunds},
+		{"zero limit", 10, 20, &CreditLine{}, ErrInsufficientFunds},
+		{"frozen line", 1000, 1, &CreditLine{Limit: 500, Frozen: true}, ErrShippingFrozen},
+		{"zero amount", 100, 0, nil, ErrInvalidAmount},
+		{"negative amount", 100, -50, nil, ErrInvalidAmount},
+		{"negative amount on a frozen line", -600, -50, &CreditLine{Limit: 500, Frozen: true}, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkDebit(tt.balance, tt.amount, tt.line); !errors.Is(err, tt.want) {
+				t.Errorf("checkDebit(%v, %v) = %v, want %v", tt.balance, tt.amount, err, tt.want)
+			}
+		})
+	}
+}
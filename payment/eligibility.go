@@ -0,0 +1,131 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Shipment event types published by the shipment service.
+const (
+	ShipmentBooked    = "shipment.booked"
+	ShipmentDelivered = "shipment.delivered"
+	ShipmentReturned  = "shipment.returned" // Returned to origin; no COD was collected.
+	ShipmentCancelled = "shipment.cancelled"
+)
+
+// Shipment statuses held in the remittance-eligibility projection.
+const (
+	EligibilityBooked    = "booked"
+	EligibilityDelivered = "delivered"
+	EligibilityReturned  = "returned"
+	EligibilityCancelled = "cancelled"
+)
+
+// EventSignatureHeader carries the publisher's signature of a shipment event body.
+const EventSignatureHeader = "X-Event-Signature"
+
+var ErrInvalidShipmentEvent = errors.New("invalid shipment event")
+
+// eventStatuses maps each shipment event type to the status it records.
+var eventStatuses = map[string]string{
+	ShipmentBooked:    EligibilityBooked,
+	ShipmentDelivered: EligibilityDelivered,
+	ShipmentReturned:  EligibilityReturned,
+	ShipmentCancelled: EligibilityCancelled,
+}
+
+// ShipmentEvent is a shipment lifecycle event. Each event carries the order's
+// COD details so the payment service can keep its own remittance-eligibility
+// projection instead of reading the orders table of another service.
+type ShipmentEvent struct {
+	EventID    string    `json:"event_id"`
+	Type       string    `json:"type"`
+	OrderID    string    `json:"order_id"`
+	AccountID  string    `json:"account_id"`
+	AWB        string    `json:"awb"`
+	IsCOD      bool      `json:"is_cod"`
+	CODAmount  float64   `json:"cod_amount"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (e *ShipmentEvent) validate() error {
+	if e.EventID == "" || e.OrderID == "" || e.AccountID == "" {
+		return fmt.Errorf("%w: event, order and account ids are required", ErrInvalidShipmentEvent)
+	}
+	if _, ok := eventStatuses[e.Type]; !ok {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidShipmentEvent, e.Type)
+	}
+	if e.CODAmount < 0 || (e.IsCOD && e.CODAmount == 0) {
+		return fmt.Errorf("%w: COD orders need a positive COD amount", ErrInvalidShipmentEvent)
+	}
+	if e.OccurredAt.IsZero() {
+		return fmt.Errorf("%w: occurred_at is required", ErrInvalidShipmentEvent)
+	}
+	return nil
+}
+
+// ApplyShipmentEvent records a shipment event in the remittance-eligibility
+// projection. Redelivered and out-of-order events leave it unchanged.
+func (s *paymentService) ApplyShipmentEvent(ctx context.Context, event ShipmentEvent) error {
+	if err := event.validate(); err != nil {
+		return err
+	}
+	return s.repo.ApplyShipmentEvent(ctx, event)
+}
+
+// SignShipmentEvent returns the hex-encoded HMAC-SHA256 signature of payload,
+// as the shipment service signs the events it publishes.
+func SignShipmentEvent(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewShipmentEventHandler returns an HTTP handler that consumes signed shipment
+// events pushed by the shipment service and applies them to the
+// remittance-eligibility projection.
+func NewShipmentEventHandler(service Service, secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		payload, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !hmac.Equal([]byte(SignShipmentEvent(secret, payload)), []byte(r.Header.Get(EventSignatureHeader))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var event ShipmentEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = service.ApplyShipmentEvent(r.Context(), event)
+		switch {
+		case errors.Is(err, ErrInvalidShipmentEvent):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case err != nil:
+			// Let the publisher redeliver on unexpected failures.
+			log.Printf("Failed to apply shipment event %s: %v", event.EventID, err)
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+}
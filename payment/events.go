@@ -0,0 +1,101 @@
+package payment
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// walletEventPollInterval is how often an open wallet event stream checks for
+// new events once it has caught up.
+const walletEventPollInterval = time.Second
+
+// walletEventPageSize bounds the events read per poll.
+const walletEventPageSize = 500
+
+// WalletEvent is a change to a wallet balance, numbered in commit order.
+type WalletEvent struct {
+	Seq          int64 // Increases by one per event, across all accounts.
+	AccountID    string
+	Currency     string
+	Type         string // The transaction type, e.g. "recharge" or "adjustment_debit".
+	Amount       float64
+	BalanceAfter float64
+	OrderID      string
+	Reference    string
+	CreatedAt    time.Time
+}
+
+// StreamWalletEvents sends the wallet events after afterSeq, for one account or
+// all accounts if accountID is empty, then keeps sending new events as they
+// are sequenced until ctx is cancelled or send fails. Consumers resume by
+// passing the Seq of the last event they processed. A negative afterSeq starts
+// from the next new event.
+func (s *paymentService) StreamWalletEvents(ctx context.Context, accountID string, afterSeq int64, send func(WalletEvent) error) error {
+	if afterSeq < 0 {
+		latest, err := s.repo.LatestWalletEventSeq(ctx)
+		if err != nil {
+			return err
+		}
+		afterSeq = latest
+	}
+
+	ticker := time.NewTicker(walletEventPollInterval)
+	defer ticker.Stop()
+
+	for {
+		events, err := s.repo.ListWalletEvents(ctx, accountID, afterSeq, walletEventPageSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := send(event); err != nil {
+				return err
+			}
+			afterSeq = event.Seq
+		}
+		if len(events) == walletEventPageSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SequenceWalletEvents numbers the wallet transactions committed since the last
+// run and returns how many it numbered.
+func (s *paymentService) SequenceWalletEvents(ctx context.Context) (int, error) {
+	return s.repo.SequenceWalletEvents(ctx)
+}
+
+// WalletEventSequencer periodically numbers committed wallet transactions so
+// they can be streamed as wallet events.
+type WalletEventSequencer struct {
+	service Service
+}
+
+// NewWalletEventSequencer creates a sequencer that numbers events through the service.
+func NewWalletEventSequencer(service Service) *WalletEventSequencer {
+	return &WalletEventSequencer{service: service}
+}
+
+// Run sequences wallet events every interval until ctx is cancelled.
+func (w *WalletEventSequencer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.service.SequenceWalletEvents(ctx); err != nil {
+			log.Printf("Failed to sequence wallet events: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Wallet currencies.
+const (
+	CurrencyINR = "INR"
+	CurrencyUSD = "USD"
+	CurrencyAED = "AED"
+)
+
+// BaseCurrency is the currency of the wallet that COD remittances, credit lines,
+// plan fees and low-balance alerts use.
+const BaseCurrency = CurrencyINR
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// FXProvider supplies exchange rates for wallet conversions.
+type FXProvider interface {
+	// Rate returns how many units of to one unit of from buys. It returns
+	// ErrUnsupportedCurrency for currencies it does not quote.
+	Rate(ctx context.Context, from string, to string) (float64, error)
+}
+
+// FXConversion records the exchange that funded a wallet transaction.
+type FXConversion struct {
+	SourceAmount   float64
+	SourceCurrency string
+	Rate           float64
+}
+
+// WalletBalance is the balance of one of an account's wallets.
+type WalletBalance struct {
+	Currency string
+	Balance  float64
+}
+
+// StaticFXProvider quotes rates from a fixed table. It is used for local
+// development and tests.
+type StaticFXProvider struct {
+	rates map[string]float64 // Value of one unit of each currency in the base currency.
+}
+
+// NewStaticFXProvider creates a provider from the value of one unit of each
+// currency in the base currency.
+func NewStaticFXProvider(rates map[string]float64) *StaticFXProvider {
+	table := map[string]float64{BaseCurrency: 1}
+	for currency, rate := range rates {
+		table[strings.ToUpper(currency)] = rate
+	}
+	return &StaticFXProvider{rates: table}
+}
+
+// DefaultFXRates is an indicative rate table for local development.
+var DefaultFXRates = map[string]float64{
+	CurrencyUSD: 83.25,
+	CurrencyAED: 22.66,
+}
+
+func (p *StaticFXProvider) Rate(ctx context.Context, from string, to string) (float64, error) {
+	fromRate, ok := p.rates[from]
+	if !ok || fromRate <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, from)
+	}
+	toRate, ok := p.rates[to]
+	if !ok || toRate <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, to)
+	}
+	return fromRate / toRate, nil
+}
+
+// normalizeCurrency upper-cases a currency code. Callers that leave it empty get
+// the base currency.
+func normalizeCurrency(currency string) string {
+	if currency == "" {
+		return BaseCurrency
+	}
+	return strings.ToUpper(currency)
+}
+
+// convert quotes amount in from as an amount in to, rounded to the paisa or cent.
+func convert(ctx context.Context, fx FXProvider, amount float64, from string, to string) (float64, *FXConversion, error) {
+	rate, err := fx.Rate(ctx, from, to)
+	if err != nil {
+		return 0, nil, err
+	}
+	if from == to {
+		return amount, nil, nil
+	}
+	converted := math.Round(amount*rate*100) / 100
+	return converted, &FXConversion{SourceAmount: amount, SourceCurrency: from, Rate: rate}, nil
+}
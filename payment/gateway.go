@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Recharge intent statuses as reported by the payment gateway.
+const (
+	RechargeStatusCreated  = "created"
+	RechargeStatusPending  = "pending"
+	RechargeStatusCaptured = "captured"
+	RechargeStatusFailed   = "failed"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrIntentNotFound   = errors.New("recharge intent not found")
+	ErrAmountMismatch   = errors.New("captured amount does not match recharge intent")
+	ErrCheckoutMismatch = errors.New("checkout reference does not match recharge intent")
+	ErrInvalidAmount    = errors.New("amount must be greater than zero")
+	ErrInvalidMandate   = errors.New("invalid gateway mandate")
+	ErrMissingEventID   = errors.New("gateway event has no event id")
+)
+
+// Gateway abstracts the external payment gateway used to collect wallet recharges.
+type Gateway interface {
+	// CreateIntent registers a payment intent with the gateway and returns the
+	// checkout reference the merchant uses to complete the payment.
+	CreateIntent(ctx context.Context, intentID string, accountID string, amount float64, currency string) (string, error)
+	// ChargeMandate charges a mandate the merchant saved with the gateway, without
+	// the merchant present. Like an intent, the charge is confirmed by webhook.
+	ChargeMandate(ctx context.Context, mandateID string, intentID string, accountID string, amount float64, currency string) (string, error)
+	// ParseWebhook verifies the signature of a webhook payload and decodes it.
+	ParseWebhook(payload []byte, signature string) (*GatewayEvent, error)
+}
+
+// GatewayEvent is a verified status update for a recharge intent. Events are
+// deduplicated on EventID, so it must be set.
+type GatewayEvent struct {
+	EventID     string  `json:"event_id"`
+	IntentID    string  `json:"intent_id"`
+	CheckoutRef string  `json:"checkout_ref"`
+	Status      string  `json:"status"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+}
+
+// gatewayTransition decides what a gateway event does to its recharge intent:
+// whether the intent takes the event's status and whether the wallet is
+// credited. Redelivered events and events for intents that are already captured
+// or failed change nothing. Events must carry the intent's checkout reference,
+// and amounts are compared in paise or cents, since floats do not hold them
+// exactly.
+func gatewayTransition(intent *RechargeIntent, event GatewayEvent, redelivered bool) (apply bool, credit bool, err error) {
+	if redelivered || intent.Status == RechargeStatusCaptured || intent.Status == RechargeStatusFailed {
+		return false, false, nil
+	}
+	if event.CheckoutRef != intent.CheckoutRef {
+		return false, false, ErrCheckoutMismatch
+	}
+
+	switch event.Status {
+	case RechargeStatusCaptured:
+		if minorUnits(event.Amount) != minorUnits(intent.Amount) || (event.Currency != "" && event.Currency != intent.Currency) {
+			return false, false, ErrAmountMismatch
+		}
+		return true, true, nil
+	case RechargeStatusPending, RechargeStatusFailed:
+		return true, false, nil
+	default:
+		return false, false, fmt.Errorf("unknown gateway status %q", event.Status)
+	}
+}
+
+// minorUnits converts an amount to whole paise, or cents, rounding away
+// float error.
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,68 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// LocalGateway is an in-process Gateway used for local development and tests.
+// It hands out fake checkout references and signs webhooks with HMAC-SHA256.
+type LocalGateway struct {
+	secret []byte
+}
+
+// NewLocalGateway creates a LocalGateway that signs webhooks with the given secret.
+func NewLocalGateway(secret string) *LocalGateway {
+	return &LocalGateway{secret: []byte(secret)}
+}
+
+// CreateIntent returns a fake checkout reference for the intent.
+func (g *LocalGateway) CreateIntent(ctx context.Context, intentID string, accountID string, amount float64, currency string) (string, error) {
+	return "local_chk_" + uuid.NewString(), nil
+}
+
+// ChargeMandate returns a fake checkout reference for the mandate charge.
+func (g *LocalGateway) ChargeMandate(ctx context.Context, mandateID string, intentID string, accountID string, amount float64, currency string) (string, error) {
+	if mandateID == "" {
+		return "", ErrInvalidMandate
+	}
+	return "local_mdt_" + uuid.NewString(), nil
+}
+
+// ParseWebhook verifies the HMAC signature and decodes the event.
+func (g *LocalGateway) ParseWebhook(payload []byte, signature string) (*GatewayEvent, error) {
+	if !hmac.Equal([]byte(g.Sign(payload)), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event GatewayEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook payload: %w", err)
+	}
+	return &event, nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of payload.
+func (g *LocalGateway) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Webhook builds a signed webhook payload for the event, as the real gateway would send it.
+func (g *LocalGateway) Webhook(event GatewayEvent) ([]byte, string, error) {
+	if event.EventID == "" {
+		event.EventID = uuid.NewString()
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, g.Sign(payload), nil
+}
@@ -0,0 +1,111 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLocalGatewayParseWebhook(t *testing.T) {
+	gateway := NewLocalGateway("secret")
+	payload, signature, err := gateway.Webhook(GatewayEvent{IntentID: "intent-1", Status: RechargeStatusCaptured, Amount: 500, Currency: "INR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		gateway   *LocalGateway
+		payload   []byte
+		signature string
+		wantErr   error
+	}{
+		{"valid", gateway, payload, signature, nil},
+		{"wrong secret", NewLocalGateway("other"), payload, signature, ErrInvalidSignature},
+		{"tampered payload", gateway, append([]byte(" "), payload...), signature, ErrInvalidSignature},
+		{"missing signature", gateway, payload, "", ErrInvalidSignature},
+		{"truncated signature", gateway, payload, signature[:len(signature)-2], ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := tt.gateway.ParseWebhook(tt.payload, tt.signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if event.EventID == "" || event.IntentID != "intent-1" || event.Status != RechargeStatusCaptured || event.Amount != 500 {
+				t.Errorf("ParseWebhook() = %+v", event)
+			}
+		})
+	}
+}
+
+func TestLocalGatewayParseWebhookMalformed(t *testing.T) {
+	gateway := NewLocalGateway("secret")
+	payload := []byte("{not json")
+	if _, err := gateway.ParseWebhook(payload, gateway.Sign(payload)); err == nil || errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("ParseWebhook() error = %v, want a decoding error", err)
+	}
+}
+
+func TestGatewayTransition(t *testing.T) {
+	intent := func(status string) *RechargeIntent {
+		return &RechargeIntent{ID: "intent-1", Amount: 500, Currency: "INR", WalletCurrency: "INR", CreditAmount: 500, Status: status, CheckoutRef: "chk-1"}
+	}
+	event := func(status string, amount float64, currency string) GatewayEvent {
+		return GatewayEvent{EventID: "evt-1", IntentID: "intent-1", CheckoutRef: "chk-1", Status: status, Amount: amount, Currency: currency}
+	}
+
+	tests := []struct {
+		name        string
+		intent      *RechargeIntent
+		event       GatewayEvent
+		redelivered bool
+		wantApply   bool
+		wantCredit  bool
+		wantErr     error
+	}{
+		{"created to captured", intent(RechargeStatusCreated), event(RechargeStatusCaptured, 500, "INR"), false, true, true, nil},
+		{"pending to captured", intent(RechargeStatusPending), event(RechargeStatusCaptured, 500, "INR"), false, true, true, nil},
+		{"captured without currency", intent(RechargeStatusPending), event(RechargeStatusCaptured, 500, ""), false, true, true, nil},
+		{"created to pending", intent(RechargeStatusCreated), event(RechargeStatusPending, 0, ""), false, true, false, nil},
+		{"created to failed", intent(RechargeStatusCreated), event(RechargeStatusFailed, 0, ""), false, true, false, nil},
+		{"redelivered capture", intent(RechargeStatusCreated), event(RechargeStatusCaptured, 500, "INR"), true, false, false, nil},
+		{"already captured", intent(RechargeStatusCaptured), event(RechargeStatusCaptured, 500, "INR"), false, false, false, nil},
+		{"failed after capture", intent(RechargeStatusCaptured), event(RechargeStatusFailed, 0, ""), false, false, false, nil},
+		{"captured after failure", intent(RechargeStatusFailed), event(RechargeStatusCaptured, 500, "INR"), false, false, false, nil},
+		{"amount mismatch", intent(RechargeStatusCreated), event(RechargeStatusCaptured, 499.99, "INR"), false, false, false, ErrAmountMismatch},
+		{"currency mismatch", intent(RechargeStatusCreated), event(RechargeStatusCaptured, 500, "USD"), false, false, false, ErrAmountMismatch},
+		{"amount off by float error", &RechargeIntent{Amount: 0.3, Currency: "INR", Status: RechargeStatusCreated, CheckoutRef: "chk-1"}, event(RechargeStatusCaptured, 0.1+0.2, "INR"), false, true, true, nil},
+		{"amount off by a paisa", &RechargeIntent{Amount: 0.3, Currency: "INR", Status: RechargeStatusCreated, CheckoutRef: "chk-1"}, event(RechargeStatusCaptured, 0.31, "INR"), false, false, false, ErrAmountMismatch},
+		{"capture for another checkout", intent(RechargeStatusCreated), GatewayEvent{EventID: "evt-1", IntentID: "intent-1", CheckoutRef: "chk-2", Status: RechargeStatusCaptured, Amount: 500, Currency: "INR"}, false, false, false, ErrCheckoutMismatch},
+		{"failure for another checkout", intent(RechargeStatusPending), GatewayEvent{EventID: "evt-1", IntentID: "intent-1", CheckoutRef: "chk-2", Status: RechargeStatusFailed}, false, false, false, ErrCheckoutMismatch},
+		{"no checkout reference", intent(RechargeStatusCreated), GatewayEvent{EventID: "evt-1", IntentID: "intent-1", Status: RechargeStatusCaptured, Amount: 500, Currency: "INR"}, false, false, false, ErrCheckoutMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apply, credit, err := gatewayTransition(tt.intent, tt.event, tt.redelivered)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("gatewayTransition() error = %v, want %v", err, tt.wantErr)
+			}
+			if apply != tt.wantApply || credit != tt.wantCredit {
+				t.Errorf("gatewayTransition() = (%v, %v), want (%v, %v)", apply, credit, tt.wantApply, tt.wantCredit)
+			}
+		})
+	}
+
+	if _, _, err := gatewayTransition(intent(RechargeStatusCreated), event("refunded", 500, "INR"), false); err == nil {
+		t.Error("gatewayTransition() accepted an unknown status")
+	}
+}
+
+func TestHandleGatewayWebhookRequiresEventID(t *testing.T) {
+	gateway := NewLocalGateway("secret")
+	s := &paymentService{gateway: gateway}
+	payload := []byte(`{"event_id":"","intent_id":"intent-1","status":"captured","amount":500}`)
+	if _, err := s.HandleGatewayWebhook(context.Background(), payload, gateway.Sign(payload)); !errors.Is(err, ErrMissingEventID) {
+		t.Fatalf("HandleGatewayWebhook() error = %v, want %v", err, ErrMissingEventID)
+	}
+}
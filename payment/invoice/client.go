@@ -0,0 +1,124 @@
+package invoice
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shridhar2104/logilo/payment/pb"
+	"google.golang.org/grpc"
+)
+
+// Client calls the invoicing RPCs of the payment service.
+type Client struct {
+	conn    *grpc.ClientConn
+	service pb.PaymentServiceClient
+}
+
+// NewClient connects to the payment service at url.
+func NewClient(url string) (*Client, error) {
+	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	c := pb.NewPaymentServiceClient(conn)
+	return &Client{conn: conn, service: c}, nil
+}
+
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+// ListInvoices fetches a merchant's invoices, newest first.
+func (c *Client) ListInvoices(ctx context.Context, accountID string) ([]Invoice, error) {
+	res, err := c.service.ListInvoices(ctx, &pb.ListInvoicesRequest{UserId: accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := make([]Invoice, len(res.Invoices))
+	for i, inv := range res.Invoices {
+		invoices[i] = *FromProto(inv)
+	}
+	return invoices, nil
+}
+
+// DownloadInvoice fetches one of a merchant's invoices and its PDF.
+func (c *Client) DownloadInvoice(ctx context.Context, accountID string, invoiceID string) (*Invoice, []byte, error) {
+	res, err := c.service.GetInvoicePDF(ctx, &pb.GetInvoicePDFRequest{
+		UserId:    accountID,
+		InvoiceId: invoiceID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return FromProto(res.Invoice), res.Pdf, nil
+}
+
+// ToProto converts an invoice to its gRPC representation.
+func ToProto(inv *Invoice) *pb.Invoice {
+	return &pb.Invoice{
+		InvoiceId:     inv.ID,
+		Number:        inv.Number,
+		FinancialYear: inv.FinancialYear,
+		Billing:       ProfileToProto(&inv.Billing),
+		PeriodStart:   inv.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:     inv.PeriodEnd.Format(time.RFC3339),
+		IssueDate:     inv.IssueDate.Format(time.RFC3339),
+		Sac:           inv.SAC,
+		TaxableValue:  inv.TaxableValue,
+		Cgst:          inv.CGST,
+		Sgst:          inv.SGST,
+		Igst:          inv.IGST,
+		Total:         inv.Total,
+	}
+}
+
+// FromProto converts an invoice from its gRPC representation.
+func FromProto(inv *pb.Invoice) *Invoice {
+	periodStart, _ := time.Parse(time.RFC3339, inv.PeriodStart)
+	periodEnd, _ := time.Parse(time.RFC3339, inv.PeriodEnd)
+	issueDate, _ := time.Parse(time.RFC3339, inv.IssueDate)
+
+	res := &Invoice{
+		ID:            inv.InvoiceId,
+		Number:        inv.Number,
+		FinancialYear: inv.FinancialYear,
+		PeriodStart:   periodStart,
+		PeriodEnd:     periodEnd,
+		IssueDate:     issueDate,
+		SAC:           inv.Sac,
+		TaxableValue:  inv.TaxableValue,
+		CGST:          inv.Cgst,
+		SGST:          inv.Sgst,
+		IGST:          inv.Igst,
+		Total:         inv.Total,
+	}
+	if inv.Billing != nil {
+		res.Billing = *ProfileFromProto(inv.Billing)
+		res.AccountID = res.Billing.AccountID
+	}
+	return res
+}
+
+// ProfileToProto converts a billing profile to its gRPC representation.
+func ProfileToProto(p *BillingProfile) *pb.BillingProfile {
+	return &pb.BillingProfile{
+		UserId:    p.AccountID,
+		LegalName: p.LegalName,
+		Gstin:     p.GSTIN,
+		Address:   p.Address,
+		StateCode: p.StateCode,
+	}
+}
+
+// ProfileFromProto converts a billing profile from its gRPC representation.
+func ProfileFromProto(p *pb.BillingProfile) *BillingProfile {
+	return &BillingProfile{
+		AccountID: p.UserId,
+		LegalName: p.LegalName,
+		GSTIN:     p.Gstin,
+		Address:   p.Address,
+		StateCode: p.StateCode,
+	}
+}
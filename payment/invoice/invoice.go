@@ -0,0 +1,153 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SACCourierServices is the GST services accounting code for courier services.
+const SACCourierServices = "996812"
+
+// gstRate is the GST rate applied to freight charges.
+const gstRate = 0.18
+
+var (
+	ErrInvoiceNotFound        = errors.New("invoice not found")
+	ErrBillingProfileNotFound = errors.New("billing profile not found")
+	ErrInvalidGSTIN           = errors.New("invalid GSTIN")
+)
+
+var gstinPattern = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+
+// Supplier identifies us as the issuer of freight invoices.
+type Supplier struct {
+	Name      string
+	GSTIN     string
+	Address   string
+	StateCode string // Two digit GST state code, e.g. "29" for Karnataka.
+}
+
+// BillingProfile holds the merchant details printed on their tax invoices.
+type BillingProfile struct {
+	AccountID string
+	LegalName string
+	GSTIN     string // Empty for unregistered merchants.
+	Address   string
+	StateCode string // Two digit GST state code of the place of supply.
+}
+
+// Invoice is a monthly GST tax invoice for a merchant's freight charges.
+type Invoice struct {
+	ID            string
+	Number        string // Sequential per financial year, e.g. "FRT/2024-25/000042".
+	FinancialYear string
+	AccountID     string
+	Billing       BillingProfile
+	PeriodStart   time.Time
+	PeriodEnd     time.Time // Exclusive.
+	IssueDate     time.Time
+	SAC           string
+	TaxableValue  float64
+	CGST          float64
+	SGST          float64
+	IGST          float64
+	Total         float64
+	CreatedAt     time.Time
+}
+
+// ValidateGSTIN reports whether gstin is a well-formed GST identification number.
+func ValidateGSTIN(gstin string) error {
+	if !gstinPattern.MatchString(gstin) {
+		return ErrInvalidGSTIN
+	}
+	return nil
+}
+
+// stateCodes maps the states and union territories of India to their two digit
+// GST state codes.
+var stateCodes = map[string]string{
+	"jammu and kashmir": "01",
+	"himachal pradesh":  "02",
+	"punjab":            "03",
+	"chandigarh":        "04",
+	"uttarakhand":       "05",
+	"haryana":           "06",
+	"delhi":             "07",
+	"rajasthan":         "08",
+	"uttar pradesh":     "09",
+	"bihar":             "10",
+	"sikkim":            "11",
+	"arunachal pradesh": "12",
+	"nagaland":          "13",
+	"manipur":           "14",
+	"mizoram":           "15",
+	"tripura":           "16",
+	"meghalaya":         "17",
+	"assam":             "18",
+	"west bengal":       "19",
+	"jharkhand":         "20",
+	"odisha":            "21",
+	"chhattisgarh":      "22",
+	"madhya pradesh":    "23",
+	"gujarat":           "24",
+	"dadra and nagar haveli and daman and diu": "26",
+	"maharashtra":                 "27",
+	"karnataka":                   "29",
+	"goa":                         "30",
+	"lakshadweep":                 "31",
+	"kerala":                      "32",
+	"tamil nadu":                  "33",
+	"puducherry":                  "34",
+	"andaman and nicobar islands": "35",
+	"telangana":                   "36",
+	"andhra pradesh":              "37",
+	"ladakh":                      "38",
+}
+
+// StateCode returns the GST state code of the named state or union territory,
+// or "" if the name is not recognised.
+func StateCode(state string) string {
+	state = strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(state, "&", " and "))), " ")
+	return stateCodes[state]
+}
+
+// FinancialYear returns the Indian financial year containing t, e.g. "2024-25"
+// for any date from April 2024 to March 2025.
+func FinancialYear(t time.Time) string {
+	start := t.Year()
+	if t.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+// FormatNumber renders the invoice number for a sequence within a financial year.
+func FormatNumber(financialYear string, seq int) string {
+	return fmt.Sprintf("FRT/%s/%06d", financialYear, seq)
+}
+
+// applyTax splits GST-inclusive freight charges into taxable value and tax.
+// Supplies within the supplier's state attract CGST and SGST; supplies to
+// other states attract IGST.
+func applyTax(inv *Invoice, gross float64, supplierState string) {
+	inv.Total = round2(gross)
+	inv.TaxableValue = round2(gross / (1 + gstRate))
+	tax := round2(inv.Total - inv.TaxableValue)
+
+	if inv.Billing.StateCode == supplierState {
+		inv.CGST = round2(tax / 2)
+		inv.SGST = round2(tax - inv.CGST)
+		inv.IGST = 0
+	} else {
+		inv.CGST, inv.SGST = 0, 0
+		inv.IGST = tax
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,106 @@
+package invoice
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFinancialYear(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2024-04-01", "2024-25"},
+		{"2024-12-31", "2024-25"},
+		{"2025-01-01", "2024-25"},
+		{"2025-03-31", "2024-25"},
+		{"2025-04-01", "2025-26"},
+		{"1999-06-15", "1999-00"},
+		{"2000-02-29", "1999-00"},
+	}
+	for _, tt := range tests {
+		date, err := time.Parse("2006-01-02", tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FinancialYear(date); got != tt.want {
+			t.Errorf("FinancialYear(%s) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	if got, want := FormatNumber("2024-25", 42), "FRT/2024-25/000042"; got != want {
+		t.Errorf("FormatNumber() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyTax(t *testing.T) {
+	tests := []struct {
+		name                      string
+		gross                     float64
+		state                     string
+		taxable, cgst, sgst, igst float64
+	}{
+		{"intra-state", 118, "29", 100, 9, 9, 0},
+		{"inter-state", 118, "27", 100, 0, 0, 18},
+		{"odd paise split", 100, "29", 84.75, 7.63, 7.62, 0},
+		{"inter-state rounding", 100, "07", 84.75, 0, 0, 15.25},
+		{"zero", 0, "29", 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := Invoice{Billing: BillingProfile{StateCode: tt.state}}
+			applyTax(&inv, tt.gross, "29")
+
+			if inv.Total != tt.gross || inv.TaxableValue != tt.taxable || inv.CGST != tt.cgst || inv.SGST != tt.sgst || inv.IGST != tt.igst {
+				t.Errorf("applyTax(%v) = total %v taxable %v cgst %v sgst %v igst %v, want total %v taxable %v cgst %v sgst %v igst %v",
+					tt.gross, inv.Total, inv.TaxableValue, inv.CGST, inv.SGST, inv.IGST, tt.gross, tt.taxable, tt.cgst, tt.sgst, tt.igst)
+			}
+			if sum := round2(inv.TaxableValue + inv.CGST + inv.SGST + inv.IGST); sum != inv.Total {
+				t.Errorf("taxable value and tax add up to %v, want %v", sum, inv.Total)
+			}
+		})
+	}
+}
+
+func TestValidateGSTIN(t *testing.T) {
+	tests := []struct {
+		gstin string
+		valid bool
+	}{
+		{"29ABCDE1234F1Z5", true},
+		{"07AAACB1234C1ZV", true},
+		{"29abcde1234f1z5", false},
+		{"29ABCDE1234F1Y5", false},
+		{"29ABCDE1234F0Z5", false},
+		{"2ABCDE1234F1Z5", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		err := ValidateGSTIN(tt.gstin)
+		if tt.valid && err != nil || !tt.valid && !errors.Is(err, ErrInvalidGSTIN) {
+			t.Errorf("ValidateGSTIN(%q) = %v, want valid %v", tt.gstin, err, tt.valid)
+		}
+	}
+}
+
+func TestStateCode(t *testing.T) {
+	tests := []struct {
+		state string
+		want  string
+	}{
+		{"Karnataka", "29"},
+		{"  tamil   nadu ", "33"},
+		{"Jammu & Kashmir", "01"},
+		{"Andaman and Nicobar Islands", "35"},
+		{"Bangalore", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := StateCode(tt.state); got != tt.want {
+			t.Errorf("StateCode(%q) = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
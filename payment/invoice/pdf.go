@@ -0,0 +1,99 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// RenderPDF renders the invoice as a single page PDF document.
+func RenderPDF(inv *Invoice, supplier Supplier) []byte {
+	money := func(v float64) string { return fmt.Sprintf("INR %.2f", v) }
+
+	lines := []pdfLine{
+		{16, "TAX INVOICE"},
+		{10, ""},
+		{10, supplier.Name},
+		{10, supplier.Address},
+		{10, "GSTIN: " + supplier.GSTIN},
+		{10, ""},
+		{10, "Invoice No: " + inv.Number},
+		{10, "Invoice Date: " + inv.IssueDate.Format("02 Jan 2006")},
+		{10, fmt.Sprintf("Period: %s to %s", inv.PeriodStart.Format("02 Jan 2006"), inv.PeriodEnd.AddDate(0, 0, -1).Format("02 Jan 2006"))},
+		{10, ""},
+		{10, "Bill To: " + inv.Billing.LegalName},
+		{10, inv.Billing.Address},
+		{10, "GSTIN: " + gstinOrUnregistered(inv.Billing.GSTIN)},
+		{10, "Place of Supply (State Code): " + inv.Billing.StateCode},
+		{10, ""},
+		{10, fmt.Sprintf("Freight charges (SAC %s)", inv.SAC)},
+		{10, "Taxable Value: " + money(inv.TaxableValue)},
+	}
+	if inv.IGST > 0 {
+		lines = append(lines, pdfLine{10, "IGST @ 18%: " + money(inv.IGST)})
+	} else {
+		lines = append(lines,
+			pdfLine{10, "CGST @ 9%: " + money(inv.CGST)},
+			pdfLine{10, "SGST @ 9%: " + money(inv.SGST)},
+		)
+	}
+	lines = append(lines,
+		pdfLine{12, "Total: " + money(inv.Total)},
+		pdfLine{10, ""},
+		pdfLine{8, "This is a computer generated invoice and does not require a signature."},
+	)
+
+	return buildPDF(lines)
+}
+
+type pdfLine struct {
+	size int
+	text string
+}
+
+func gstinOrUnregistered(gstin string) string {
+	if gstin == "" {
+		return "Unregistered"
+	}
+	return gstin
+}
+
+// buildPDF writes a minimal PDF 1.4 file with one A4 page of Helvetica text.
+func buildPDF(lines []pdfLine) []byte {
+	var content bytes.Buffer
+	y := 800
+	for _, l := range lines {
+		if l.text != "" {
+			fmt.Fprintf(&content, "BT /F1 %d Tf 50 %d Td (%s) Tj ET\n", l.size, y, escapePDF(l.text))
+		}
+		y -= l.size + 6
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func escapePDF(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
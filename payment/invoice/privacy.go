@@ -0,0 +1,58 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Shridhar2104/logilo/datasubject"
+)
+
+// ExportSubjectData returns the merchant's billing profile and invoices, with
+// each invoice as a PDF.
+func (s *invoiceService) ExportSubjectData(ctx context.Context, accountID string) ([]datasubject.File, error) {
+	var files []datasubject.File
+	profile, err := s.repo.GetBillingProfile(ctx, accountID)
+	if err == nil {
+		f, err := datasubject.JSONFile("billing_profile.json", profile)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	} else if !errors.Is(err, ErrBillingProfileNotFound) {
+		return nil, err
+	}
+
+	invoices, err := s.repo.ListInvoices(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := datasubject.JSONFile("invoices.json", invoices)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, f)
+	for i := range invoices {
+		files = append(files, datasubject.File{
+			Name:    "invoices/" + strings.ReplaceAll(invoices[i].Number, "/", "-") + ".pdf",
+			Content: RenderPDF(&invoices[i], s.supplier),
+		})
+	}
+	return files, nil
+}
+
+// EraseSubjectData deletes the merchant's billing profile, so no further
+// invoices are issued. Issued invoices are kept as the CGST Act requires.
+func (s *invoiceService) EraseSubjectData(ctx context.Context, accountID string) (*datasubject.Erasure, error) {
+	deleted, err := s.repo.DeleteBillingProfile(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var erasure datasubject.Erasure
+	if deleted {
+		erasure.Erased = append(erasure.Erased, "billing profile")
+	}
+	erasure.Retained = []string{"GST tax invoices, with the billing details printed on them, for 72 months after the annual return (CGST Act, section 36)"}
+	return &erasure, nil
+}
@@ -0,0 +1,191 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Repository persists invoices and the billing profiles they are issued to.
+type Repository interface {
+	GetBillingProfile(ctx context.Context, accountID string) (*BillingProfile, error)
+	PutBillingProfile(ctx context.Context, profile BillingProfile) error
+	DeleteBillingProfile(ctx context.Context, accountID string) (bool, error)
+	FreightCharges(ctx context.Context, from, to time.Time) (map[string]float64, error)
+	CreateInvoice(ctx context.Context, inv *Invoice) (bool, error)
+	ListInvoices(ctx context.Context, accountID string) ([]Invoice, error)
+	GetInvoice(ctx context.Context, accountID string, invoiceID string) (*Invoice, error)
+}
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a Repository on the payment database.
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+// GetBillingProfile retrieves the billing details of a merchant.
+func (r *postgresRepository) GetBillingProfile(ctx context.Context, accountID string) (*BillingProfile, error) {
+	p := BillingProfile{AccountID: accountID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT legal_name, gstin, address, state_code
+		FROM billing_profiles WHERE account_id = $1`, accountID).Scan(&p.LegalName, &p.GSTIN, &p.Address, &p.StateCode)
+	if err == sql.ErrNoRows {
+		return nil, ErrBillingProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// PutBillingProfile creates or replaces the billing details of a merchant.
+func (r *postgresRepository) PutBillingProfile(ctx context.Context, profile BillingProfile) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO billing_profiles (account_id, legal_name, gstin, address, state_code, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (account_id)
+		DO UPDATE SET legal_name = $2, gstin = $3, address = $4, state_code = $5, updated_at = NOW()`,
+		profile.AccountID, profile.LegalName, profile.GSTIN, profile.Address, profile.StateCode,
+	)
+	return err
+}
+
+// DeleteBillingProfile deletes the billing details of a merchant, reporting
+// whether there were any. Issued invoices keep their own copy.
+func (r *postgresRepository) DeleteBillingProfile(ctx context.Context, accountID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM billing_profiles WHERE account_id = $1`, accountID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// FreightCharges sums the shipping deductions of every merchant in [from, to).
+// Only freight paid from INR wallets is invoiced with GST.
+func (r *postgresRepository) FreightCharges(ctx context.Context, from, to time.Time) (map[string]float64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT account_id, SUM(amount) FROM transactions
+		WHERE transaction_type = 'deduction' AND currency = 'INR' AND created_at >= $1 AND created_at < $2
+		GROUP BY account_id`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	charges := make(map[string]float64)
+	for rows.Next() {
+		var accountID string
+		var amount float64
+		if err := rows.Scan(&accountID, &amount); err != nil {
+			return nil, err
+		}
+		charges[accountID] = amount
+	}
+	return charges, rows.Err()
+}
+
+// CreateInvoice assigns the next invoice number of the financial year and stores
+// the invoice. It returns false, without consuming a number, when the merchant
+// already has an invoice for the period.
+func (r *postgresRepository) CreateInvoice(ctx context.Context, inv *Invoice) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM invoices WHERE account_id = $1 AND period_start = $2)`,
+		inv.AccountID, inv.PeriodStart).Scan(&exists)
+	if err != nil || exists {
+		return false, err
+	}
+
+	// The sequence row is locked until commit, keeping numbers gapless.
+	var seq int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO invoice_sequences (financial_year, last_number)
+		VALUES ($1, 1)
+		ON CONFLICT (financial_year)
+		DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number`, inv.FinancialYear).Scan(&seq)
+	if err != nil {
+		return false, err
+	}
+	inv.Number = FormatNumber(inv.FinancialYear, seq)
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO invoices (id, number, financial_year, account_id, legal_name, gstin, address, state_code,
+			period_start, period_end, issue_date, sac, taxable_value, cgst, sgst, igst, total, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		inv.ID, inv.Number, inv.FinancialYear, inv.AccountID, inv.Billing.LegalName, inv.Billing.GSTIN,
+		inv.Billing.Address, inv.Billing.StateCode, inv.PeriodStart, inv.PeriodEnd, inv.IssueDate, inv.SAC,
+		inv.TaxableValue, inv.CGST, inv.SGST, inv.IGST, inv.Total, inv.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+const invoiceColumns = `id, number, financial_year, account_id, legal_name, gstin, address, state_code,
+	period_start, period_end, issue_date, sac, taxable_value, cgst, sgst, igst, total, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvoice(row rowScanner) (*Invoice, error) {
+	var inv Invoice
+	err := row.Scan(&inv.ID, &inv.Number, &inv.FinancialYear, &inv.AccountID, &inv.Billing.LegalName,
+		&inv.Billing.GSTIN, &inv.Billing.Address, &inv.Billing.StateCode, &inv.PeriodStart, &inv.PeriodEnd,
+		&inv.IssueDate, &inv.SAC, &inv.TaxableValue, &inv.CGST, &inv.SGST, &inv.IGST, &inv.Total, &inv.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	inv.Billing.AccountID = inv.AccountID
+	return &inv, nil
+}
+
+// ListInvoices retrieves a merchant's invoices, newest first.
+func (r *postgresRepository) ListInvoices(ctx context.Context, accountID string) ([]Invoice, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+invoiceColumns+` FROM invoices
+		WHERE account_id = $1 ORDER BY period_start DESC`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []Invoice
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, *inv)
+	}
+	return invoices, rows.Err()
+}
+
+// GetInvoice retrieves one of a merchant's invoices.
+func (r *postgresRepository) GetInvoice(ctx context.Context, accountID string, invoiceID string) (*Invoice, error) {
+	inv, err := scanInvoice(r.db.QueryRowContext(ctx, `
+		SELECT `+invoiceColumns+` FROM invoices
+		WHERE id = $1 AND account_id = $2`, invoiceID, accountID))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvoiceNotFound
+	}
+	return inv, err
+}
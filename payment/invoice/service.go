@@ -0,0 +1,180 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Shridhar2104/logilo/datasubject"
+	"github.com/google/uuid"
+)
+
+// Service issues GST tax invoices for the freight charged to merchants' wallets.
+type Service interface {
+	GenerateInvoices(ctx context.Context, month time.Time) ([]Invoice, error)
+	ListInvoices(ctx context.Context, accountID string) ([]Invoice, error)
+	GetInvoicePDF(ctx context.Context, accountID string, invoiceID string) (*Invoice, []byte, error)
+	GetBillingProfile(ctx context.Context, accountID string) (*BillingProfile, error)
+	PutBillingProfile(ctx context.Context, profile BillingProfile) (*BillingProfile, error)
+	ExportSubjectData(ctx context.Context, accountID string) ([]datasubject.File, error)
+	EraseSubjectData(ctx context.Context, accountID string) (*datasubject.Erasure, error)
+}
+
+// ProfileSource fills a merchant's billing details from the business profile
+// kept with their account, returning ErrBillingProfileNotFound if it is
+// incomplete.
+type ProfileSource interface {
+	BillingProfile(ctx context.Context, accountID string) (*BillingProfile, error)
+}
+
+type invoiceService struct {
+	repo     Repository
+	supplier Supplier
+	profiles ProfileSource
+}
+
+// NewInvoiceService creates a Service issuing invoices on behalf of supplier.
+// Merchants who have not saved billing details are billed to their account
+// profile from profiles.
+func NewInvoiceService(repo Repository, supplier Supplier, profiles ProfileSource) Service {
+	return &invoiceService{repo, supplier, profiles}
+}
+
+// GenerateInvoices issues invoices for the calendar month starting at month to
+// every merchant charged freight in it. Merchants already invoiced for the month
+// are skipped, as are merchants with neither billing details nor a complete
+// account profile.
+func (s *invoiceService) GenerateInvoices(ctx context.Context, month time.Time) ([]Invoice, error) {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	to := from.AddDate(0, 1, 0)
+
+	charges, err := s.repo.FreightCharges(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoices []Invoice
+	for accountID, gross := range charges {
+		if gross <= 0 {
+			continue
+		}
+
+		profile, err := s.GetBillingProfile(ctx, accountID)
+		if errors.Is(err, ErrBillingProfileNotFound) {
+			log.Printf("Skipping invoice for account %s: no billing profile", accountID)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		issueDate := to.AddDate(0, 0, -1)
+		inv := Invoice{
+			ID:            uuid.NewString(),
+			FinancialYear: FinancialYear(issueDate),
+			AccountID:     accountID,
+			Billing:       *profile,
+			PeriodStart:   from,
+			PeriodEnd:     to,
+			IssueDate:     issueDate,
+			SAC:           SACCourierServices,
+			CreatedAt:     time.Now(),
+		}
+		applyTax(&inv, gross, s.supplier.StateCode)
+
+		created, err := s.repo.CreateInvoice(ctx, &inv)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			invoices = append(invoices, inv)
+		}
+	}
+	return invoices, nil
+}
+
+func (s *invoiceService) ListInvoices(ctx context.Context, accountID string) ([]Invoice, error) {
+	return s.repo.ListInvoices(ctx, accountID)
+}
+
+// GetInvoicePDF retrieves one of a merchant's invoices rendered as PDF.
+func (s *invoiceService) GetInvoicePDF(ctx context.Context, accountID string, invoiceID string) (*Invoice, []byte, error) {
+	inv, err := s.repo.GetInvoice(ctx, accountID, invoiceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inv, RenderPDF(inv, s.supplier), nil
+}
+
+// GetBillingProfile returns the billing details the merchant saved, or else
+// those of their account profile.
+func (s *invoiceService) GetBillingProfile(ctx context.Context, accountID string) (*BillingProfile, error) {
+	profile, err := s.repo.GetBillingProfile(ctx, accountID)
+	if errors.Is(err, ErrBillingProfileNotFound) {
+		return s.profiles.BillingProfile(ctx, accountID)
+	}
+	return profile, err
+}
+
+// PutBillingProfile saves a merchant's billing details. Registered merchants'
+// state code is taken from their GSTIN.
+func (s *invoiceService) PutBillingProfile(ctx context.Context, profile BillingProfile) (*BillingProfile, error) {
+	if profile.LegalName == "" {
+		return nil, errors.New("legal name is required")
+	}
+	if profile.GSTIN != "" {
+		if err := ValidateGSTIN(profile.GSTIN); err != nil {
+			return nil, err
+		}
+		profile.StateCode = profile.GSTIN[:2]
+	}
+	if len(profile.StateCode) != 2 {
+		return nil, errors.New("a two digit state code is required")
+	}
+
+	if err := s.repo.PutBillingProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Scheduler generates the previous month's invoices on the first day of each month.
+type Scheduler struct {
+	service Service
+	lastRun string
+}
+
+// NewScheduler creates a Scheduler for the service.
+func NewScheduler(service Service) *Scheduler {
+	return &Scheduler{service: service}
+}
+
+// Run checks every interval whether invoices are due, until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	month := now.Format("2006-01")
+	if now.Day() != 1 || s.lastRun == month {
+		return
+	}
+
+	invoices, err := s.service.GenerateInvoices(ctx, now.AddDate(0, -1, 0))
+	if err != nil {
+		log.Printf("Failed to generate invoices: %v", err)
+		return
+	}
+	s.lastRun = month
+	log.Printf("Generated %d invoices", len(invoices))
+}
@@ -0,0 +1,48 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// kycHoldInterval is how often a payout held for KYC is checked again.
+const kycHoldInterval = time.Hour
+
+var (
+	ErrKYCNotApproved   = errors.New("merchant kyc is not approved")
+	ErrEmailNotVerified = errors.New("merchant email address has not been verified")
+)
+
+// KYCChecker reports whether a merchant's KYC has been approved, and whether
+// their email address has been verified. COD remittances and bank payouts are
+// held until KYC is approved, and shipments until the email is verified.
+type KYCChecker interface {
+	KYCApproved(ctx context.Context, accountID string) (bool, error)
+	EmailVerified(ctx context.Context, accountID string) (bool, error)
+}
+
+// requireKYC returns ErrKYCNotApproved unless the merchant's KYC is approved.
+func (s *paymentService) requireKYC(ctx context.Context, accountID string) error {
+	approved, err := s.kyc.KYCApproved(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if !approved {
+		return ErrKYCNotApproved
+	}
+	return nil
+}
+
+// requireVerifiedEmail returns ErrEmailNotVerified unless the merchant's email
+// address is verified.
+func (s *paymentService) requireVerifiedEmail(ctx context.Context, accountID string) error {
+	verified, err := s.kyc.EmailVerified(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package payment
+
+import (
+	"context"
+	"log"
+)
+
+// Notifier delivers account notifications such as billing reminders.
+type Notifier interface {
+	Notify(ctx context.Context, accountID string, subject string, message string) error
+}
+
+// LogNotifier writes notifications to the service log. It is used until a
+// delivery channel is configured, and in tests.
+type LogNotifier struct{}
+
+// NewLogNotifier creates a LogNotifier.
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, accountID string, subject string, message string) error {
+	log.Printf("Notification for account %s: %s: %s", accountID, subject, message)
+	return nil
+}
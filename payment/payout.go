@@ -0,0 +1,152 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Remittance destinations.
+const (
+	DestinationWallet = "wallet"
+	DestinationBank   = "bank"
+)
+
+// Payout statuses.
+const (
+	PayoutStatusQueued     = "queued"
+	PayoutStatusProcessing = "processing"
+	PayoutStatusProcessed  = "processed"
+	PayoutStatusFailed     = "failed"
+)
+
+// maxPayoutAttempts is how many times a payout is submitted before its batch is failed.
+const maxPayoutAttempts = 5
+
+var (
+	ErrInvalidDestination     = errors.New("remittance destination must be wallet or bank")
+	ErrInvalidBankAccount     = errors.New("bank account number, IFSC and beneficiary name are required")
+	ErrBankAccountNotVerified = errors.New("bank account could not be verified")
+)
+
+var ifscPattern = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+
+// BankAccount is a merchant's bank account for COD payouts.
+type BankAccount struct {
+	AccountNumber   string
+	IFSC            string
+	BeneficiaryName string
+}
+
+// RemittanceSettings records where a merchant's COD remittances are sent.
+type RemittanceSettings struct {
+	AccountID    string
+	Destination  string
+	Bank         BankAccount
+	BankVerified bool
+	UpdatedAt    time.Time
+}
+
+// Payout is a bank transfer settling a remittance batch.
+type Payout struct {
+	ID            string
+	BatchID       string
+	AccountID     string
+	Amount        float64
+	Bank          BankAccount
+	Status        string
+	ProviderRef   string
+	UTR           string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// PayoutProvider abstracts the banking partner used to pay merchants.
+type PayoutProvider interface {
+	// VerifyAccount checks that the bank account exists and matches the beneficiary.
+	VerifyAccount(ctx context.Context, bank BankAccount) (bool, error)
+	// CreatePayout submits a transfer and returns the provider's reference for it.
+	CreatePayout(ctx context.Context, payout Payout) (string, error)
+	// PayoutStatus reports the status of a submitted transfer and its UTR once processed.
+	PayoutStatus(ctx context.Context, providerRef string) (string, string, error)
+}
+
+// LocalPayoutProvider is an in-memory PayoutProvider for local development and tests.
+// Transfers complete immediately with a generated UTR.
+type LocalPayoutProvider struct {
+	mu      sync.Mutex
+	payouts map[string]string
+}
+
+// NewLocalPayoutProvider creates an empty LocalPayoutProvider.
+func NewLocalPayoutProvider() *LocalPayoutProvider {
+	return &LocalPayoutProvider{payouts: make(map[string]string)}
+}
+
+// VerifyAccount accepts any account with a well-formed IFSC.
+func (p *LocalPayoutProvider) VerifyAccount(ctx context.Context, bank BankAccount) (bool, error) {
+	return ifscPattern.MatchString(bank.IFSC), nil
+}
+
+// CreatePayout records the transfer and assigns it a UTR.
+func (p *LocalPayoutProvider) CreatePayout(ctx context.Context, payout Payout) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ref := "local_po_" + uuid.NewString()
+	p.payouts[ref] = fmt.Sprintf("LOCL%012d", len(p.payouts)+1)
+	return ref, nil
+}
+
+// PayoutStatus reports every known transfer as processed.
+func (p *LocalPayoutProvider) PayoutStatus(ctx context.Context, providerRef string) (string, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	utr, ok := p.payouts[providerRef]
+	if !ok {
+		return "", "", fmt.Errorf("unknown payout %q", providerRef)
+	}
+	return PayoutStatusProcessed, utr, nil
+}
+
+// payoutBackoff returns the wait before the next attempt of a payout.
+func payoutBackoff(attempts int) time.Duration {
+	return time.Minute << attempts
+}
+
+// PayoutWorker periodically submits queued payouts and polls their status.
+type PayoutWorker struct {
+	service Service
+}
+
+// NewPayoutWorker creates a worker that drives payouts through the service.
+func NewPayoutWorker(service Service) *PayoutWorker {
+	return &PayoutWorker{service: service}
+}
+
+// Run processes due payouts every interval until ctx is cancelled.
+func (w *PayoutWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.service.ProcessPayouts(ctx); err != nil {
+			log.Printf("Failed to process payouts: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -19,10 +19,41 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PaymentService_RechargeWallet_FullMethodName    = "/payment.PaymentService/RechargeWallet"
-	PaymentService_DeductBalance_FullMethodName     = "/payment.PaymentService/DeductBalance"
-	PaymentService_ProcessRemittance_FullMethodName = "/payment.PaymentService/ProcessRemittance"
-	PaymentService_GetWalletDetails_FullMethodName  = "/payment.PaymentService/GetWalletDetails"
+	PaymentService_RechargeWallet_FullMethodName           = "/payment.PaymentService/RechargeWallet"
+	PaymentService_DeductBalance_FullMethodName            = "/payment.PaymentService/DeductBalance"
+	PaymentService_ProcessRemittance_FullMethodName        = "/payment.PaymentService/ProcessRemittance"
+	PaymentService_GetWalletDetails_FullMethodName         = "/payment.PaymentService/GetWalletDetails"
+	PaymentService_ExportStatement_FullMethodName          = "/payment.PaymentService/ExportStatement"
+	PaymentService_StreamWalletEvents_FullMethodName       = "/payment.PaymentService/StreamWalletEvents"
+	PaymentService_CreateRechargeIntent_FullMethodName     = "/payment.PaymentService/CreateRechargeIntent"
+	PaymentService_HandleGatewayWebhook_FullMethodName     = "/payment.PaymentService/HandleGatewayWebhook"
+	PaymentService_CreateRemittanceBatches_FullMethodName  = "/payment.PaymentService/CreateRemittanceBatches"
+	PaymentService_ListRemittanceBatches_FullMethodName    = "/payment.PaymentService/ListRemittanceBatches"
+	PaymentService_UpdateRemittanceBatch_FullMethodName    = "/payment.PaymentService/UpdateRemittanceBatch"
+	PaymentService_RequestEarlyRemittance_FullMethodName   = "/payment.PaymentService/RequestEarlyRemittance"
+	PaymentService_GetRemittanceSettings_FullMethodName    = "/payment.PaymentService/GetRemittanceSettings"
+	PaymentService_UpdateRemittanceSettings_FullMethodName = "/payment.PaymentService/UpdateRemittanceSettings"
+	PaymentService_ListPayouts_FullMethodName              = "/payment.PaymentService/ListPayouts"
+	PaymentService_ImportCourierStatement_FullMethodName   = "/payment.PaymentService/ImportCourierStatement"
+	PaymentService_GenerateInvoices_FullMethodName         = "/payment.PaymentService/GenerateInvoices"
+	PaymentService_ListInvoices_FullMethodName             = "/payment.PaymentService/ListInvoices"
+	PaymentService_GetInvoicePDF_FullMethodName            = "/payment.PaymentService/GetInvoicePDF"
+	PaymentService_GetBillingProfile_FullMethodName        = "/payment.PaymentService/GetBillingProfile"
+	PaymentService_PutBillingProfile_FullMethodName        = "/payment.PaymentService/PutBillingProfile"
+	PaymentService_GetCreditLine_FullMethodName            = "/payment.PaymentService/GetCreditLine"
+	PaymentService_SetCreditLine_FullMethodName            = "/payment.PaymentService/SetCreditLine"
+	PaymentService_ListCreditBills_FullMethodName          = "/payment.PaymentService/ListCreditBills"
+	PaymentService_GetBalanceAlert_FullMethodName          = "/payment.PaymentService/GetBalanceAlert"
+	PaymentService_UpdateBalanceAlert_FullMethodName       = "/payment.PaymentService/UpdateBalanceAlert"
+	PaymentService_ListPlans_FullMethodName                = "/payment.PaymentService/ListPlans"
+	PaymentService_GetAccountPlan_FullMethodName           = "/payment.PaymentService/GetAccountPlan"
+	PaymentService_ChangePlan_FullMethodName               = "/payment.PaymentService/ChangePlan"
+	PaymentService_FreezeWallet_FullMethodName             = "/payment.PaymentService/FreezeWallet"
+	PaymentService_UnfreezeWallet_FullMethodName           = "/payment.PaymentService/UnfreezeWallet"
+	PaymentService_RequestAdjustment_FullMethodName        = "/payment.PaymentService/RequestAdjustment"
+	PaymentService_ReviewAdjustment_FullMethodName         = "/payment.PaymentService/ReviewAdjustment"
+	PaymentService_ListAdjustments_FullMethodName          = "/payment.PaymentService/ListAdjustments"
+	PaymentService_ListAuditLog_FullMethodName             = "/payment.PaymentService/ListAuditLog"
 )
 
 // PaymentServiceClient is the client API for PaymentService service.
@@ -31,14 +62,78 @@ const (
 //
 // The Payment service definition.
 type PaymentServiceClient interface {
-	// Recharges the wallet for a user.
+	// Recharges the wallet for a user. Internal only: merchants pay through recharge intents.
 	RechargeWallet(ctx context.Context, in *RechargeRequest, opts ...grpc.CallOption) (*RechargeResponse, error)
-	// Deducts an amount from the wallet for shipping an order.
+	// Deducts the shipping charge of an order from the wallet, after the plan's rate discount.
 	DeductBalance(ctx context.Context, in *DeductionRequest, opts ...grpc.CallOption) (*DeductionResponse, error)
-	// Processes COD remittance for orders marked as delivered after 15 days.
+	// Processes COD remittance for the given orders once they are past the remittance cycle delay.
 	ProcessRemittance(ctx context.Context, in *RemittanceRequest, opts ...grpc.CallOption) (*RemittanceResponse, error)
-	// Retrieves the wallet balance and transaction history.
+	// Retrieves the wallet balance and a page of transaction history.
 	GetWalletDetails(ctx context.Context, in *WalletDetailsRequest, opts ...grpc.CallOption) (*WalletDetailsResponse, error)
+	// Streams a monthly account statement as CSV.
+	ExportStatement(ctx context.Context, in *ExportStatementRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatementChunk], error)
+	// Streams wallet balance changes as they happen, resuming after a sequence number.
+	StreamWalletEvents(ctx context.Context, in *StreamWalletEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WalletEvent], error)
+	// Creates a payment intent with the gateway for a wallet recharge.
+	CreateRechargeIntent(ctx context.Context, in *CreateRechargeIntentRequest, opts ...grpc.CallOption) (*CreateRechargeIntentResponse, error)
+	// Applies a signed gateway webhook to its recharge intent.
+	HandleGatewayWebhook(ctx context.Context, in *GatewayWebhookRequest, opts ...grpc.CallOption) (*GatewayWebhookResponse, error)
+	// Groups payable COD orders into one remittance batch per merchant.
+	CreateRemittanceBatches(ctx context.Context, in *CreateRemittanceBatchesRequest, opts ...grpc.CallOption) (*CreateRemittanceBatchesResponse, error)
+	// Lists a merchant's remittance batches.
+	ListRemittanceBatches(ctx context.Context, in *ListRemittanceBatchesRequest, opts ...grpc.CallOption) (*ListRemittanceBatchesResponse, error)
+	// Approves, pays or fails a remittance batch.
+	UpdateRemittanceBatch(ctx context.Context, in *UpdateRemittanceBatchRequest, opts ...grpc.CallOption) (*UpdateRemittanceBatchResponse, error)
+	// Settles a merchant's delivered COD orders ahead of the cycle for a fee.
+	RequestEarlyRemittance(ctx context.Context, in *EarlyRemittanceRequest, opts ...grpc.CallOption) (*EarlyRemittanceResponse, error)
+	// Retrieves where a merchant's remittances are sent.
+	GetRemittanceSettings(ctx context.Context, in *GetRemittanceSettingsRequest, opts ...grpc.CallOption) (*RemittanceSettingsResponse, error)
+	// Sets where a merchant's remittances are sent.
+	UpdateRemittanceSettings(ctx context.Context, in *UpdateRemittanceSettingsRequest, opts ...grpc.CallOption) (*RemittanceSettingsResponse, error)
+	// Lists a merchant's bank payouts.
+	ListPayouts(ctx context.Context, in *ListPayoutsRequest, opts ...grpc.CallOption) (*ListPayoutsResponse, error)
+	// Imports a courier's CSV COD remittance statement and reconciles it by AWB.
+	ImportCourierStatement(ctx context.Context, in *ImportCourierStatementRequest, opts ...grpc.CallOption) (*ImportCourierStatementResponse, error)
+	// Issues GST invoices for a month's freight charges.
+	GenerateInvoices(ctx context.Context, in *GenerateInvoicesRequest, opts ...grpc.CallOption) (*GenerateInvoicesResponse, error)
+	// Lists a merchant's GST invoices.
+	ListInvoices(ctx context.Context, in *ListInvoicesRequest, opts ...grpc.CallOption) (*ListInvoicesResponse, error)
+	// Retrieves one of a merchant's GST invoices as PDF.
+	GetInvoicePDF(ctx context.Context, in *GetInvoicePDFRequest, opts ...grpc.CallOption) (*GetInvoicePDFResponse, error)
+	// Retrieves the details printed on a merchant's invoices.
+	GetBillingProfile(ctx context.Context, in *GetBillingProfileRequest, opts ...grpc.CallOption) (*BillingProfileResponse, error)
+	// Sets the details printed on a merchant's invoices.
+	PutBillingProfile(ctx context.Context, in *PutBillingProfileRequest, opts ...grpc.CallOption) (*BillingProfileResponse, error)
+	// Retrieves a merchant's postpaid credit line.
+	GetCreditLine(ctx context.Context, in *GetCreditLineRequest, opts ...grpc.CallOption) (*CreditLineResponse, error)
+	// Grants or updates a merchant's postpaid credit line.
+	SetCreditLine(ctx context.Context, in *SetCreditLineRequest, opts ...grpc.CallOption) (*CreditLineResponse, error)
+	// Lists the bills issued against a merchant's credit line.
+	ListCreditBills(ctx context.Context, in *ListCreditBillsRequest, opts ...grpc.CallOption) (*ListCreditBillsResponse, error)
+	// Retrieves a merchant's low-balance alert.
+	GetBalanceAlert(ctx context.Context, in *GetBalanceAlertRequest, opts ...grpc.CallOption) (*BalanceAlertResponse, error)
+	// Sets a merchant's low-balance threshold and optional auto-recharge.
+	UpdateBalanceAlert(ctx context.Context, in *UpdateBalanceAlertRequest, opts ...grpc.CallOption) (*BalanceAlertResponse, error)
+	// Lists the pricing plans merchants can subscribe to.
+	ListPlans(ctx context.Context, in *ListPlansRequest, opts ...grpc.CallOption) (*ListPlansResponse, error)
+	// Retrieves a merchant's subscription and the plan parameters in effect,
+	// for the rate engine and other services.
+	GetAccountPlan(ctx context.Context, in *GetAccountPlanRequest, opts ...grpc.CallOption) (*AccountPlanResponse, error)
+	// Moves a merchant to another plan, prorating the monthly fee.
+	ChangePlan(ctx context.Context, in *ChangePlanRequest, opts ...grpc.CallOption) (*AccountPlanResponse, error)
+	// Admin: stops all debits from a wallet, e.g. during a fraud investigation.
+	FreezeWallet(ctx context.Context, in *FreezeWalletRequest, opts ...grpc.CallOption) (*FreezeWalletResponse, error)
+	// Admin: lifts a wallet freeze.
+	UnfreezeWallet(ctx context.Context, in *FreezeWalletRequest, opts ...grpc.CallOption) (*FreezeWalletResponse, error)
+	// Admin: posts a manual credit or debit. Adjustments above the approval
+	// threshold stay pending until another admin reviews them.
+	RequestAdjustment(ctx context.Context, in *RequestAdjustmentRequest, opts ...grpc.CallOption) (*AdjustmentResponse, error)
+	// Admin: approves or rejects a pending adjustment.
+	ReviewAdjustment(ctx context.Context, in *ReviewAdjustmentRequest, opts ...grpc.CallOption) (*AdjustmentResponse, error)
+	// Admin: lists wallet adjustments.
+	ListAdjustments(ctx context.Context, in *ListAdjustmentsRequest, opts ...grpc.CallOption) (*ListAdjustmentsResponse, error)
+	// Admin: lists the audit log of admin actions.
+	ListAuditLog(ctx context.Context, in *ListAuditLogRequest, opts ...grpc.CallOption) (*ListAuditLogResponse, error)
 }
 
 type paymentServiceClient struct {
@@ -89,20 +184,412 @@ func (c *paymentServiceClient) GetWalletDetails(ctx context.Context, in *WalletD
 	return out, nil
 }
 
+func (c *paymentServiceClient) ExportStatement(ctx context.Context, in *ExportStatementRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatementChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PaymentService_ServiceDesc.Streams[0], PaymentService_ExportStatement_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportStatementRequest, StatementChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_ExportStatementClient = grpc.ServerStreamingClient[StatementChunk]
+
+func (c *paymentServiceClient) StreamWalletEvents(ctx context.Context, in *StreamWalletEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WalletEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PaymentService_ServiceDesc.Streams[1], PaymentService_StreamWalletEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamWalletEventsRequest, WalletEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_StreamWalletEventsClient = grpc.ServerStreamingClient[WalletEvent]
+
+func (c *paymentServiceClient) CreateRechargeIntent(ctx context.Context, in *CreateRechargeIntentRequest, opts ...grpc.CallOption) (*CreateRechargeIntentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateRechargeIntentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_CreateRechargeIntent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) HandleGatewayWebhook(ctx context.Context, in *GatewayWebhookRequest, opts ...grpc.CallOption) (*GatewayWebhookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GatewayWebhookResponse)
+	err := c.cc.Invoke(ctx, PaymentService_HandleGatewayWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) CreateRemittanceBatches(ctx context.Context, in *CreateRemittanceBatchesRequest, opts ...grpc.CallOption) (*CreateRemittanceBatchesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateRemittanceBatchesResponse)
+	err := c.cc.Invoke(ctx, PaymentService_CreateRemittanceBatches_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListRemittanceBatches(ctx context.Context, in *ListRemittanceBatchesRequest, opts ...grpc.CallOption) (*ListRemittanceBatchesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRemittanceBatchesResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListRemittanceBatches_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) UpdateRemittanceBatch(ctx context.Context, in *UpdateRemittanceBatchRequest, opts ...grpc.CallOption) (*UpdateRemittanceBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateRemittanceBatchResponse)
+	err := c.cc.Invoke(ctx, PaymentService_UpdateRemittanceBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) RequestEarlyRemittance(ctx context.Context, in *EarlyRemittanceRequest, opts ...grpc.CallOption) (*EarlyRemittanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EarlyRemittanceResponse)
+	err := c.cc.Invoke(ctx, PaymentService_RequestEarlyRemittance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetRemittanceSettings(ctx context.Context, in *GetRemittanceSettingsRequest, opts ...grpc.CallOption) (*RemittanceSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemittanceSettingsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetRemittanceSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) UpdateRemittanceSettings(ctx context.Context, in *UpdateRemittanceSettingsRequest, opts ...grpc.CallOption) (*RemittanceSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemittanceSettingsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_UpdateRemittanceSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListPayouts(ctx context.Context, in *ListPayoutsRequest, opts ...grpc.CallOption) (*ListPayoutsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPayoutsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListPayouts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ImportCourierStatement(ctx context.Context, in *ImportCourierStatementRequest, opts ...grpc.CallOption) (*ImportCourierStatementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportCourierStatementResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ImportCourierStatement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GenerateInvoices(ctx context.Context, in *GenerateInvoicesRequest, opts ...grpc.CallOption) (*GenerateInvoicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateInvoicesResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GenerateInvoices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListInvoices(ctx context.Context, in *ListInvoicesRequest, opts ...grpc.CallOption) (*ListInvoicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInvoicesResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListInvoices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetInvoicePDF(ctx context.Context, in *GetInvoicePDFRequest, opts ...grpc.CallOption) (*GetInvoicePDFResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInvoicePDFResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetInvoicePDF_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetBillingProfile(ctx context.Context, in *GetBillingProfileRequest, opts ...grpc.CallOption) (*BillingProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillingProfileResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetBillingProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) PutBillingProfile(ctx context.Context, in *PutBillingProfileRequest, opts ...grpc.CallOption) (*BillingProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillingProfileResponse)
+	err := c.cc.Invoke(ctx, PaymentService_PutBillingProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetCreditLine(ctx context.Context, in *GetCreditLineRequest, opts ...grpc.CallOption) (*CreditLineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreditLineResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetCreditLine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) SetCreditLine(ctx context.Context, in *SetCreditLineRequest, opts ...grpc.CallOption) (*CreditLineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreditLineResponse)
+	err := c.cc.Invoke(ctx, PaymentService_SetCreditLine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListCreditBills(ctx context.Context, in *ListCreditBillsRequest, opts ...grpc.CallOption) (*ListCreditBillsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCreditBillsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListCreditBills_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetBalanceAlert(ctx context.Context, in *GetBalanceAlertRequest, opts ...grpc.CallOption) (*BalanceAlertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceAlertResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetBalanceAlert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) UpdateBalanceAlert(ctx context.Context, in *UpdateBalanceAlertRequest, opts ...grpc.CallOption) (*BalanceAlertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceAlertResponse)
+	err := c.cc.Invoke(ctx, PaymentService_UpdateBalanceAlert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListPlans(ctx context.Context, in *ListPlansRequest, opts ...grpc.CallOption) (*ListPlansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlansResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListPlans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetAccountPlan(ctx context.Context, in *GetAccountPlanRequest, opts ...grpc.CallOption) (*AccountPlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AccountPlanResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetAccountPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ChangePlan(ctx context.Context, in *ChangePlanRequest, opts ...grpc.CallOption) (*AccountPlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AccountPlanResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ChangePlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) FreezeWallet(ctx context.Context, in *FreezeWalletRequest, opts ...grpc.CallOption) (*FreezeWalletResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FreezeWalletResponse)
+	err := c.cc.Invoke(ctx, PaymentService_FreezeWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) UnfreezeWallet(ctx context.Context, in *FreezeWalletRequest, opts ...grpc.CallOption) (*FreezeWalletResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FreezeWalletResponse)
+	err := c.cc.Invoke(ctx, PaymentService_UnfreezeWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) RequestAdjustment(ctx context.Context, in *RequestAdjustmentRequest, opts ...grpc.CallOption) (*AdjustmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdjustmentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_RequestAdjustment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ReviewAdjustment(ctx context.Context, in *ReviewAdjustmentRequest, opts ...grpc.CallOption) (*AdjustmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdjustmentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ReviewAdjustment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListAdjustments(ctx context.Context, in *ListAdjustmentsRequest, opts ...grpc.CallOption) (*ListAdjustmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAdjustmentsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListAdjustments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ListAuditLog(ctx context.Context, in *ListAuditLogRequest, opts ...grpc.CallOption) (*ListAuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAuditLogResponse)
+	err := c.cc.Invoke(ctx, PaymentService_ListAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentServiceServer is the server API for PaymentService service.
 // All implementations must embed UnimplementedPaymentServiceServer
 // for forward compatibility.
 //
 // The Payment service definition.
 type PaymentServiceServer interface {
-	// Recharges the wallet for a user.
+	// Recharges the wallet for a user. Internal only: merchants pay through recharge intents.
 	RechargeWallet(context.Context, *RechargeRequest) (*RechargeResponse, error)
-	// Deducts an amount from the wallet for shipping an order.
+	// Deducts the shipping charge of an order from the wallet, after the plan's rate discount.
 	DeductBalance(context.Context, *DeductionRequest) (*DeductionResponse, error)
-	// Processes COD remittance for orders marked as delivered after 15 days.
+	// Processes COD remittance for the given orders once they are past the remittance cycle delay.
 	ProcessRemittance(context.Context, *RemittanceRequest) (*RemittanceResponse, error)
-	// Retrieves the wallet balance and transaction history.
+	// Retrieves the wallet balance and a page of transaction history.
 	GetWalletDetails(context.Context, *WalletDetailsRequest) (*WalletDetailsResponse, error)
+	// Streams a monthly account statement as CSV.
+	ExportStatement(*ExportStatementRequest, grpc.ServerStreamingServer[StatementChunk]) error
+	// Streams wallet balance changes as they happen, resuming after a sequence number.
+	StreamWalletEvents(*StreamWalletEventsRequest, grpc.ServerStreamingServer[WalletEvent]) error
+	// Creates a payment intent with the gateway for a wallet recharge.
+	CreateRechargeIntent(context.Context, *CreateRechargeIntentRequest) (*CreateRechargeIntentResponse, error)
+	// Applies a signed gateway webhook to its recharge intent.
+	HandleGatewayWebhook(context.Context, *GatewayWebhookRequest) (*GatewayWebhookResponse, error)
+	// Groups payable COD orders into one remittance batch per merchant.
+	CreateRemittanceBatches(context.Context, *CreateRemittanceBatchesRequest) (*CreateRemittanceBatchesResponse, error)
+	// Lists a merchant's remittance batches.
+	ListRemittanceBatches(context.Context, *ListRemittanceBatchesRequest) (*ListRemittanceBatchesResponse, error)
+	// Approves, pays or fails a remittance batch.
+	UpdateRemittanceBatch(context.Context, *UpdateRemittanceBatchRequest) (*UpdateRemittanceBatchResponse, error)
+	// Settles a merchant's delivered COD orders ahead of the cycle for a fee.
+	RequestEarlyRemittance(context.Context, *EarlyRemittanceRequest) (*EarlyRemittanceResponse, error)
+	// Retrieves where a merchant's remittances are sent.
+	GetRemittanceSettings(context.Context, *GetRemittanceSettingsRequest) (*RemittanceSettingsResponse, error)
+	// Sets where a merchant's remittances are sent.
+	UpdateRemittanceSettings(context.Context, *UpdateRemittanceSettingsRequest) (*RemittanceSettingsResponse, error)
+	// Lists a merchant's bank payouts.
+	ListPayouts(context.Context, *ListPayoutsRequest) (*ListPayoutsResponse, error)
+	// Imports a courier's CSV COD remittance statement and reconciles it by AWB.
+	ImportCourierStatement(context.Context, *ImportCourierStatementRequest) (*ImportCourierStatementResponse, error)
+	// Issues GST invoices for a month's freight charges.
+	GenerateInvoices(context.Context, *GenerateInvoicesRequest) (*GenerateInvoicesResponse, error)
+	// Lists a merchant's GST invoices.
+	ListInvoices(context.Context, *ListInvoicesRequest) (*ListInvoicesResponse, error)
+	// Retrieves one of a merchant's GST invoices as PDF.
+	GetInvoicePDF(context.Context, *GetInvoicePDFRequest) (*GetInvoicePDFResponse, error)
+	// Retrieves the details printed on a merchant's invoices.
+	GetBillingProfile(context.Context, *GetBillingProfileRequest) (*BillingProfileResponse, error)
+	// Sets the details printed on a merchant's invoices.
+	PutBillingProfile(context.Context, *PutBillingProfileRequest) (*BillingProfileResponse, error)
+	// Retrieves a merchant's postpaid credit line.
+	GetCreditLine(context.Context, *GetCreditLineRequest) (*CreditLineResponse, error)
+	// Grants or updates a merchant's postpaid credit line.
+	SetCreditLine(context.Context, *SetCreditLineRequest) (*CreditLineResponse, error)
+	// Lists the bills issued against a merchant's credit line.
+	ListCreditBills(context.Context, *ListCreditBillsRequest) (*ListCreditBillsResponse, error)
+	// Retrieves a merchant's low-balance alert.
+	GetBalanceAlert(context.Context, *GetBalanceAlertRequest) (*BalanceAlertResponse, error)
+	// Sets a merchant's low-balance threshold and optional auto-recharge.
+	UpdateBalanceAlert(context.Context, *UpdateBalanceAlertRequest) (*BalanceAlertResponse, error)
+	// Lists the pricing plans merchants can subscribe to.
+	ListPlans(context.Context, *ListPlansRequest) (*ListPlansResponse, error)
+	// Retrieves a merchant's subscription and the plan parameters in effect,
+	// for the rate engine and other services.
+	GetAccountPlan(context.Context, *GetAccountPlanRequest) (*AccountPlanResponse, error)
+	// Moves a merchant to another plan, prorating the monthly fee.
+	ChangePlan(context.Context, *ChangePlanRequest) (*AccountPlanResponse, error)
+	// Admin: stops all debits from a wallet, e.g. during a fraud investigation.
+	FreezeWallet(context.Context, *FreezeWalletRequest) (*FreezeWalletResponse, error)
+	// Admin: lifts a wallet freeze.
+	UnfreezeWallet(context.Context, *FreezeWalletRequest) (*FreezeWalletResponse, error)
+	// Admin: posts a manual credit or debit. Adjustments above the approval
+	// threshold stay pending until another admin reviews them.
+	RequestAdjustment(context.Context, *RequestAdjustmentRequest) (*AdjustmentResponse, error)
+	// Admin: approves or rejects a pending adjustment.
+	ReviewAdjustment(context.Context, *ReviewAdjustmentRequest) (*AdjustmentResponse, error)
+	// Admin: lists wallet adjustments.
+	ListAdjustments(context.Context, *ListAdjustmentsRequest) (*ListAdjustmentsResponse, error)
+	// Admin: lists the audit log of admin actions.
+	ListAuditLog(context.Context, *ListAuditLogRequest) (*ListAuditLogResponse, error)
 	mustEmbedUnimplementedPaymentServiceServer()
 }
 
@@ -125,6 +612,99 @@ func (UnimplementedPaymentServiceServer) ProcessRemittance(context.Context, *Rem
 func (UnimplementedPaymentServiceServer) GetWalletDetails(context.Context, *WalletDetailsRequest) (*WalletDetailsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetWalletDetails not implemented")
 }
+func (UnimplementedPaymentServiceServer) ExportStatement(*ExportStatementRequest, grpc.ServerStreamingServer[StatementChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportStatement not implemented")
+}
+func (UnimplementedPaymentServiceServer) StreamWalletEvents(*StreamWalletEventsRequest, grpc.ServerStreamingServer[WalletEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamWalletEvents not implemented")
+}
+func (UnimplementedPaymentServiceServer) CreateRechargeIntent(context.Context, *CreateRechargeIntentRequest) (*CreateRechargeIntentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRechargeIntent not implemented")
+}
+func (UnimplementedPaymentServiceServer) HandleGatewayWebhook(context.Context, *GatewayWebhookRequest) (*GatewayWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleGatewayWebhook not implemented")
+}
+func (UnimplementedPaymentServiceServer) CreateRemittanceBatches(context.Context, *CreateRemittanceBatchesRequest) (*CreateRemittanceBatchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRemittanceBatches not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListRemittanceBatches(context.Context, *ListRemittanceBatchesRequest) (*ListRemittanceBatchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRemittanceBatches not implemented")
+}
+func (UnimplementedPaymentServiceServer) UpdateRemittanceBatch(context.Context, *UpdateRemittanceBatchRequest) (*UpdateRemittanceBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRemittanceBatch not implemented")
+}
+func (UnimplementedPaymentServiceServer) RequestEarlyRemittance(context.Context, *EarlyRemittanceRequest) (*EarlyRemittanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestEarlyRemittance not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetRemittanceSettings(context.Context, *GetRemittanceSettingsRequest) (*RemittanceSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRemittanceSettings not implemented")
+}
+func (UnimplementedPaymentServiceServer) UpdateRemittanceSettings(context.Context, *UpdateRemittanceSettingsRequest) (*RemittanceSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRemittanceSettings not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListPayouts(context.Context, *ListPayoutsRequest) (*ListPayoutsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPayouts not implemented")
+}
+func (UnimplementedPaymentServiceServer) ImportCourierStatement(context.Context, *ImportCourierStatementRequest) (*ImportCourierStatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportCourierStatement not implemented")
+}
+func (UnimplementedPaymentServiceServer) GenerateInvoices(context.Context, *GenerateInvoicesRequest) (*GenerateInvoicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateInvoices not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListInvoices(context.Context, *ListInvoicesRequest) (*ListInvoicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInvoices not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetInvoicePDF(context.Context, *GetInvoicePDFRequest) (*GetInvoicePDFResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvoicePDF not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetBillingProfile(context.Context, *GetBillingProfileRequest) (*BillingProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBillingProfile not implemented")
+}
+func (UnimplementedPaymentServiceServer) PutBillingProfile(context.Context, *PutBillingProfileRequest) (*BillingProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutBillingProfile not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetCreditLine(context.Context, *GetCreditLineRequest) (*CreditLineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCreditLine not implemented")
+}
+func (UnimplementedPaymentServiceServer) SetCreditLine(context.Context, *SetCreditLineRequest) (*CreditLineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCreditLine not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListCreditBills(context.Context, *ListCreditBillsRequest) (*ListCreditBillsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCreditBills not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetBalanceAlert(context.Context, *GetBalanceAlertRequest) (*BalanceAlertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalanceAlert not implemented")
+}
+func (UnimplementedPaymentServiceServer) UpdateBalanceAlert(context.Context, *UpdateBalanceAlertRequest) (*BalanceAlertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBalanceAlert not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListPlans(context.Context, *ListPlansRequest) (*ListPlansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlans not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetAccountPlan(context.Context, *GetAccountPlanRequest) (*AccountPlanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountPlan not implemented")
+}
+func (UnimplementedPaymentServiceServer) ChangePlan(context.Context, *ChangePlanRequest) (*AccountPlanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePlan not implemented")
+}
+func (UnimplementedPaymentServiceServer) FreezeWallet(context.Context, *FreezeWalletRequest) (*FreezeWalletResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FreezeWallet not implemented")
+}
+func (UnimplementedPaymentServiceServer) UnfreezeWallet(context.Context, *FreezeWalletRequest) (*FreezeWalletResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnfreezeWallet not implemented")
+}
+func (UnimplementedPaymentServiceServer) RequestAdjustment(context.Context, *RequestAdjustmentRequest) (*AdjustmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestAdjustment not implemented")
+}
+func (UnimplementedPaymentServiceServer) ReviewAdjustment(context.Context, *ReviewAdjustmentRequest) (*AdjustmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewAdjustment not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListAdjustments(context.Context, *ListAdjustmentsRequest) (*ListAdjustmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAdjustments not implemented")
+}
+func (UnimplementedPaymentServiceServer) ListAuditLog(context.Context, *ListAuditLogRequest) (*ListAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditLog not implemented")
+}
 func (UnimplementedPaymentServiceServer) mustEmbedUnimplementedPaymentServiceServer() {}
 func (UnimplementedPaymentServiceServer) testEmbeddedByValue()                        {}
 
@@ -218,6 +798,550 @@ func _PaymentService_GetWalletDetails_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_ExportStatement_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportStatementRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PaymentServiceServer).ExportStatement(m, &grpc.GenericServerStream[ExportStatementRequest, StatementChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_ExportStatementServer = grpc.ServerStreamingServer[StatementChunk]
+
+func _PaymentService_StreamWalletEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamWalletEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PaymentServiceServer).StreamWalletEvents(m, &grpc.GenericServerStream[StreamWalletEventsRequest, WalletEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_StreamWalletEventsServer = grpc.ServerStreamingServer[WalletEvent]
+
+func _PaymentService_CreateRechargeIntent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRechargeIntentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).CreateRechargeIntent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_CreateRechargeIntent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).CreateRechargeIntent(ctx, req.(*CreateRechargeIntentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_HandleGatewayWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GatewayWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).HandleGatewayWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_HandleGatewayWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).HandleGatewayWebhook(ctx, req.(*GatewayWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_CreateRemittanceBatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRemittanceBatchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).CreateRemittanceBatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_CreateRemittanceBatches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).CreateRemittanceBatches(ctx, req.(*CreateRemittanceBatchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListRemittanceBatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRemittanceBatchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListRemittanceBatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListRemittanceBatches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListRemittanceBatches(ctx, req.(*ListRemittanceBatchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_UpdateRemittanceBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRemittanceBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).UpdateRemittanceBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_UpdateRemittanceBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).UpdateRemittanceBatch(ctx, req.(*UpdateRemittanceBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_RequestEarlyRemittance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EarlyRemittanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).RequestEarlyRemittance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_RequestEarlyRemittance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).RequestEarlyRemittance(ctx, req.(*EarlyRemittanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetRemittanceSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRemittanceSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetRemittanceSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetRemittanceSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetRemittanceSettings(ctx, req.(*GetRemittanceSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_UpdateRemittanceSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRemittanceSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).UpdateRemittanceSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_UpdateRemittanceSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).UpdateRemittanceSettings(ctx, req.(*UpdateRemittanceSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListPayouts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPayoutsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListPayouts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListPayouts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListPayouts(ctx, req.(*ListPayoutsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ImportCourierStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportCourierStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ImportCourierStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ImportCourierStatement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ImportCourierStatement(ctx, req.(*ImportCourierStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GenerateInvoices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateInvoicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GenerateInvoices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GenerateInvoices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GenerateInvoices(ctx, req.(*GenerateInvoicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListInvoices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInvoicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListInvoices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListInvoices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListInvoices(ctx, req.(*ListInvoicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetInvoicePDF_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInvoicePDFRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetInvoicePDF(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetInvoicePDF_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetInvoicePDF(ctx, req.(*GetInvoicePDFRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetBillingProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBillingProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetBillingProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetBillingProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetBillingProfile(ctx, req.(*GetBillingProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_PutBillingProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutBillingProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).PutBillingProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_PutBillingProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).PutBillingProfile(ctx, req.(*PutBillingProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetCreditLine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCreditLineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetCreditLine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetCreditLine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetCreditLine(ctx, req.(*GetCreditLineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_SetCreditLine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCreditLineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).SetCreditLine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_SetCreditLine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).SetCreditLine(ctx, req.(*SetCreditLineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListCreditBills_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCreditBillsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListCreditBills(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListCreditBills_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListCreditBills(ctx, req.(*ListCreditBillsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetBalanceAlert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceAlertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetBalanceAlert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetBalanceAlert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetBalanceAlert(ctx, req.(*GetBalanceAlertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_UpdateBalanceAlert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBalanceAlertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).UpdateBalanceAlert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_UpdateBalanceAlert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).UpdateBalanceAlert(ctx, req.(*UpdateBalanceAlertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListPlans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPlansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListPlans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListPlans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListPlans(ctx, req.(*ListPlansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetAccountPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetAccountPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetAccountPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetAccountPlan(ctx, req.(*GetAccountPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ChangePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ChangePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ChangePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ChangePlan(ctx, req.(*ChangePlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_FreezeWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreezeWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).FreezeWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_FreezeWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).FreezeWallet(ctx, req.(*FreezeWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_UnfreezeWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreezeWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).UnfreezeWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_UnfreezeWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).UnfreezeWallet(ctx, req.(*FreezeWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_RequestAdjustment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestAdjustmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).RequestAdjustment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_RequestAdjustment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).RequestAdjustment(ctx, req.(*RequestAdjustmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ReviewAdjustment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewAdjustmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ReviewAdjustment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ReviewAdjustment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ReviewAdjustment(ctx, req.(*ReviewAdjustmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListAdjustments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAdjustmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListAdjustments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListAdjustments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListAdjustments(ctx, req.(*ListAdjustmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ListAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ListAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ListAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ListAuditLog(ctx, req.(*ListAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaymentService_ServiceDesc is the grpc.ServiceDesc for PaymentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -241,7 +1365,134 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetWalletDetails",
 			Handler:    _PaymentService_GetWalletDetails_Handler,
 		},
+		{
+			MethodName: "CreateRechargeIntent",
+			Handler:    _PaymentService_CreateRechargeIntent_Handler,
+		},
+		{
+			MethodName: "HandleGatewayWebhook",
+			Handler:    _PaymentService_HandleGatewayWebhook_Handler,
+		},
+		{
+			MethodName: "CreateRemittanceBatches",
+			Handler:    _PaymentService_CreateRemittanceBatches_Handler,
+		},
+		{
+			MethodName: "ListRemittanceBatches",
+			Handler:    _PaymentService_ListRemittanceBatches_Handler,
+		},
+		{
+			MethodName: "UpdateRemittanceBatch",
+			Handler:    _PaymentService_UpdateRemittanceBatch_Handler,
+		},
+		{
+			MethodName: "RequestEarlyRemittance",
+			Handler:    _PaymentService_RequestEarlyRemittance_Handler,
+		},
+		{
+			MethodName: "GetRemittanceSettings",
+			Handler:    _PaymentService_GetRemittanceSettings_Handler,
+		},
+		{
+			MethodName: "UpdateRemittanceSettings",
+			Handler:    _PaymentService_UpdateRemittanceSettings_Handler,
+		},
+		{
+			MethodName: "ListPayouts",
+			Handler:    _PaymentService_ListPayouts_Handler,
+		},
+		{
+			MethodName: "ImportCourierStatement",
+			Handler:    _PaymentService_ImportCourierStatement_Handler,
+		},
+		{
+			MethodName: "GenerateInvoices",
+			Handler:    _PaymentService_GenerateInvoices_Handler,
+		},
+		{
+			MethodName: "ListInvoices",
+			Handler:    _PaymentService_ListInvoices_Handler,
+		},
+		{
+			MethodName: "GetInvoicePDF",
+			Handler:    _PaymentService_GetInvoicePDF_Handler,
+		},
+		{
+			MethodName: "GetBillingProfile",
+			Handler:    _PaymentService_GetBillingProfile_Handler,
+		},
+		{
+			MethodName: "PutBillingProfile",
+			Handler:    _PaymentService_PutBillingProfile_Handler,
+		},
+		{
+			MethodName: "GetCreditLine",
+			Handler:    _PaymentService_GetCreditLine_Handler,
+		},
+		{
+			MethodName: "SetCreditLine",
+			Handler:    _PaymentService_SetCreditLine_Handler,
+		},
+		{
+			MethodName: "ListCreditBills",
+			Handler:    _PaymentService_ListCreditBills_Handler,
+		},
+		{
+			MethodName: "GetBalanceAlert",
+			Handler:    _PaymentService_GetBalanceAlert_Handler,
+		},
+		{
+			MethodName: "UpdateBalanceAlert",
+			Handler:    _PaymentService_UpdateBalanceAlert_Handler,
+		},
+		{
+			MethodName: "ListPlans",
+			Handler:    _PaymentService_ListPlans_Handler,
+		},
+		{
+			MethodName: "GetAccountPlan",
+			Handler:    _PaymentService_GetAccountPlan_Handler,
+		},
+		{
+			MethodName: "ChangePlan",
+			Handler:    _PaymentService_ChangePlan_Handler,
+		},
+		{
+			MethodName: "FreezeWallet",
+			Handler:    _PaymentService_FreezeWallet_Handler,
+		},
+		{
+			MethodName: "UnfreezeWallet",
+			Handler:    _PaymentService_UnfreezeWallet_Handler,
+		},
+		{
+			MethodName: "RequestAdjustment",
+			Handler:    _PaymentService_RequestAdjustment_Handler,
+		},
+		{
+			MethodName: "ReviewAdjustment",
+			Handler:    _PaymentService_ReviewAdjustment_Handler,
+		},
+		{
+			MethodName: "ListAdjustments",
+			Handler:    _PaymentService_ListAdjustments_Handler,
+		},
+		{
+			MethodName: "ListAuditLog",
+			Handler:    _PaymentService_ListAuditLog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportStatement",
+			Handler:       _PaymentService_ExportStatement_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamWalletEvents",
+			Handler:       _PaymentService_StreamWalletEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "payment.proto",
 }
@@ -0,0 +1,269 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// Plan codes.
+const (
+	PlanLite       = "lite"
+	PlanPro        = "pro"
+	PlanEnterprise = "enterprise"
+)
+
+// Plan features.
+const (
+	FeatureEarlyRemittance = "early_remittance"
+	FeatureCreditLine      = "credit_line"
+	FeatureAPIAccess       = "api_access"
+)
+
+// Subscription statuses.
+const (
+	SubscriptionActive  = "active"
+	SubscriptionPastDue = "past_due"
+)
+
+var (
+	ErrPlanNotFound      = errors.New("plan not found")
+	ErrFeatureNotInPlan  = errors.New("feature is not included in the account's plan")
+	ErrSubscriptionStale = errors.New("subscription changed concurrently, retry")
+)
+
+// Plan is a pricing tier that sets a merchant's shipping discount, COD
+// remittance cycle and feature access for a monthly fee.
+type Plan struct {
+	Code                string
+	Name                string
+	MonthlyFee          float64
+	RateDiscountPercent float64 // Discount on the rate card for shipping charges.
+	Cycle               RemittanceCycle
+	Features            []string
+}
+
+// HasFeature reports whether the plan includes feature.
+func (p *Plan) HasFeature(feature string) bool {
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// DiscountedRate applies the plan's discount to a rate card price.
+func (p *Plan) DiscountedRate(rate float64) float64 {
+	return math.Round(rate*(100-p.RateDiscountPercent)) / 100
+}
+
+// PlanCatalog holds the plans merchants can subscribe to, by code.
+type PlanCatalog map[string]Plan
+
+// DefaultPlanCatalog builds the Lite, Pro and Enterprise plans. Lite is free
+// and uses the given remittance cycle; paid plans remit sooner and more often.
+func DefaultPlanCatalog(lite RemittanceCycle) PlanCatalog {
+	return PlanCatalog{
+		PlanLite: {
+			Code:  PlanLite,
+			Name:  "Lite",
+			Cycle: lite,
+		},
+		PlanPro: {
+			Code:                PlanPro,
+			Name:                "Pro",
+			MonthlyFee:          999,
+			RateDiscountPercent: 10,
+			Cycle: RemittanceCycle{
+				DelayDays:       3,
+				Weekdays:        []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+				EarlyFeePercent: 1,
+			},
+			Features: []string{FeatureEarlyRemittance},
+		},
+		PlanEnterprise: {
+			Code:                PlanEnterprise,
+			Name:                "Enterprise",
+			MonthlyFee:          4999,
+			RateDiscountPercent: 20,
+			Cycle: RemittanceCycle{
+				DelayDays: 2,
+				Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+					time.Friday, time.Saturday},
+				EarlyFeePercent: 0.5,
+			},
+			Features: []string{FeatureEarlyRemittance, FeatureCreditLine, FeatureAPIAccess},
+		},
+	}
+}
+
+// Subscription attaches a plan to a merchant account for the current billing period.
+type Subscription struct {
+	AccountID   string
+	PlanCode    string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Status      string
+	UpdatedAt   time.Time
+}
+
+// prorate returns the share of fee for the part of the period left at now.
+func (sub *Subscription) prorate(fee float64, now time.Time) float64 {
+	total := sub.PeriodEnd.Sub(sub.PeriodStart)
+	left := sub.PeriodEnd.Sub(now)
+	if total <= 0 || left <= 0 {
+		return 0
+	}
+	return math.Round(fee*float64(left)/float64(total)*100) / 100
+}
+
+// ListPlans returns the plans merchants can subscribe to.
+func (s *paymentService) ListPlans(ctx context.Context) ([]Plan, error) {
+	plans := make([]Plan, 0, len(s.plans))
+	for _, code := range []string{PlanLite, PlanPro, PlanEnterprise} {
+		if p, ok := s.plans[code]; ok {
+			plans = append(plans, p)
+		}
+	}
+	return plans, nil
+}
+
+// GetAccountPlan returns the merchant's subscription and the plan in effect.
+// Merchants without a subscription, and those past due, get Lite.
+func (s *paymentService) GetAccountPlan(ctx context.Context, accountID string) (*Subscription, *Plan, error) {
+	sub, err := s.repo.GetSubscription(ctx, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	code := PlanLite
+	if sub != nil && sub.Status == SubscriptionActive {
+		code = sub.PlanCode
+	}
+	plan, ok := s.plans[code]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrPlanNotFound, code)
+	}
+	return sub, &plan, nil
+}
+
+// accountPlan returns the plan in effect for the merchant.
+func (s *paymentService) accountPlan(ctx context.Context, accountID string) (*Plan, error) {
+	_, plan, err := s.GetAccountPlan(ctx, accountID)
+	return plan, err
+}
+
+// ChangePlan moves a merchant to another plan. The unused part of the current
+// period's fee is credited and the new plan is charged for the rest of the
+// period; merchants starting a subscription pay for a full month.
+func (s *paymentService) ChangePlan(ctx context.Context, accountID string, planCode string) (*Subscription, error) {
+	plan, ok := s.plans[planCode]
+	if !ok {
+		return nil, ErrPlanNotFound
+	}
+
+	current, err := s.repo.GetSubscription(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	next := Subscription{
+		AccountID:   accountID,
+		PlanCode:    plan.Code,
+		PeriodStart: now,
+		PeriodEnd:   now.AddDate(0, 1, 0),
+		Status:      SubscriptionActive,
+	}
+	charge := plan.MonthlyFee
+	expected := ""
+	if current != nil {
+		if current.PlanCode == plan.Code && current.Status == SubscriptionActive {
+			return current, nil
+		}
+		expected = current.PlanCode
+		if current.Status == SubscriptionActive && current.PeriodEnd.After(now) {
+			old := s.plans[current.PlanCode]
+			next.PeriodStart, next.PeriodEnd = current.PeriodStart, current.PeriodEnd
+			charge = current.prorate(plan.MonthlyFee, now) - current.prorate(old.MonthlyFee, now)
+		}
+	}
+
+	if err := s.repo.ChangeSubscription(ctx, next, expected, charge); err != nil {
+		return nil, err
+	}
+	if charge != 0 {
+		s.refreshBalanceAlert(ctx, accountID)
+	}
+	return s.repo.GetSubscription(ctx, accountID)
+}
+
+// RenewSubscriptions charges the monthly fee of subscriptions whose period has
+// ended. Merchants who cannot pay, including those whose wallet is frozen, fall
+// back to Lite until a renewal succeeds.
+func (s *paymentService) RenewSubscriptions(ctx context.Context, now time.Time) error {
+	subs, err := s.repo.ListSubscriptionsDue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		plan, ok := s.plans[sub.PlanCode]
+		if !ok {
+			log.Printf("Skipping renewal for account %s: unknown plan %q", sub.AccountID, sub.PlanCode)
+			continue
+		}
+
+		periodEnd := sub.PeriodEnd
+		for !periodEnd.After(now) {
+			periodEnd = periodEnd.AddDate(0, 1, 0)
+		}
+		err := s.repo.RenewSubscription(ctx, sub.AccountID, periodEnd.AddDate(0, -1, 0), periodEnd, plan.MonthlyFee)
+		if errors.Is(err, ErrInsufficientFunds) || errors.Is(err, ErrShippingFrozen) || errors.Is(err, ErrWalletFrozen) {
+			if sub.Status == SubscriptionActive {
+				if err := s.repo.MarkSubscriptionPastDue(ctx, sub.AccountID); err != nil {
+					return err
+				}
+				s.notify(ctx, sub.AccountID, "Subscription renewal failed",
+					fmt.Sprintf("We could not charge %.2f for your %s plan. Recharge your wallet to keep its benefits.", plan.MonthlyFee, plan.Name))
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		s.refreshBalanceAlert(ctx, sub.AccountID)
+	}
+	return nil
+}
+
+// SubscriptionWorker periodically renews subscriptions.
+type SubscriptionWorker struct {
+	service Service
+}
+
+// NewSubscriptionWorker creates a worker that renews subscriptions through the service.
+func NewSubscriptionWorker(service Service) *SubscriptionWorker {
+	return &SubscriptionWorker{service: service}
+}
+
+// Run renews due subscriptions every interval until ctx is cancelled.
+func (w *SubscriptionWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.service.RenewSubscriptions(ctx, time.Now()); err != nil {
+			log.Printf("Failed to renew subscriptions: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
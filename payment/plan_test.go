@@ -0,0 +1,54 @@
+package payment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscriptionProrate(t *testing.T) {
+	start := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+	sub := &Subscription{PeriodStart: start, PeriodEnd: start.AddDate(0, 0, 30)}
+
+	tests := []struct {
+		name string
+		sub  *Subscription
+		fee  float64
+		now  time.Time
+		want float64
+	}{
+		{"period start", sub, 999, start, 999},
+		{"half way", sub, 1000, start.AddDate(0, 0, 15), 500},
+		{"a third left", sub, 999, start.AddDate(0, 0, 20), 333},
+		{"rounded to paise", sub, 100, start.AddDate(0, 0, 1), 96.67},
+		{"period end", sub, 999, sub.PeriodEnd, 0},
+		{"after period end", sub, 999, sub.PeriodEnd.Add(time.Hour), 0},
+		{"empty period", &Subscription{PeriodStart: start, PeriodEnd: start}, 999, start, 0},
+		{"free plan", sub, 0, start.AddDate(0, 0, 10), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub.prorate(tt.fee, tt.now); got != tt.want {
+				t.Errorf("prorate(%v) = %v, want %v", tt.fee, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanDiscountedRate(t *testing.T) {
+	tests := []struct {
+		discount float64
+		rate     float64
+		want     float64
+	}{
+		{0, 85, 85},
+		{10, 85, 76.5},
+		{15, 99.99, 84.99},
+		{100, 85, 0},
+	}
+	for _, tt := range tests {
+		plan := &Plan{RateDiscountPercent: tt.discount}
+		if got := plan.DiscountedRate(tt.rate); got != tt.want {
+			t.Errorf("DiscountedRate(%v) with %v%% off = %v, want %v", tt.rate, tt.discount, got, tt.want)
+		}
+	}
+}
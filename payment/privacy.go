@@ -0,0 +1,71 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/Shridhar2104/logilo/datasubject"
+)
+
+var ErrOutstandingFunds = errors.New("settle the wallet balance, credit bills, payouts and COD remittances before erasing the account")
+
+// ExportSubjectData returns the account's rows in every payment table, one
+// JSON file per table.
+func (s *paymentService) ExportSubjectData(ctx context.Context, accountID string) ([]datasubject.File, error) {
+	tables, err := s.repo.ExportAccountTables(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]datasubject.File, len(names))
+	for i, name := range names {
+		if files[i], err = datasubject.JSONFile(name+".json", tables[name]); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// EraseSubjectData removes the account's bank details, including those on past
+// payouts, and standing instructions and freezes its wallets. Money must be settled first, so
+// nothing is owed either way once the account is gone. The ledger is kept as
+// the law requires.
+func (s *paymentService) EraseSubjectData(ctx context.Context, accountID string) (*datasubject.Erasure, error) {
+	outstanding, err := s.repo.HasOutstandingFunds(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if outstanding {
+		return nil, ErrOutstandingFunds
+	}
+
+	err = s.repo.EraseAccountData(ctx, accountID, AuditEntry{
+		Actor:     "data-subject-request",
+		Action:    AuditAccountErase,
+		AccountID: accountID,
+		Details:   "personal data erased at the merchant's request",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &datasubject.Erasure{
+		Erased: []string{
+			"remittance bank account details",
+			"bank account numbers on past payouts, masked to their last four digits",
+			"beneficiary names on past payouts",
+			"low-balance alerts and auto-recharge mandate",
+			"plan subscription",
+		},
+		Retained: []string{
+			"wallet transactions, recharges, remittances, payouts and credit bills for 8 years (Companies Act, section 128)",
+			"wallet adjustments and the admin audit log for 8 years (Companies Act, section 128)",
+		},
+	}, nil
+}
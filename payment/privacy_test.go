@@ -0,0 +1,180 @@
+package payment
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Shridhar2104/logilo/internal/sqltest"
+	"github.com/Shridhar2104/logilo/payment/invoice"
+)
+
+// What erasing a merchant does to a column holding their personal data.
+const (
+	deleted  = "deleted"  // The row is deleted.
+	masked   = "masked"   // The column is overwritten in place.
+	retained = "retained" // Kept for the period given in Erasure.Retained.
+)
+
+// personalData lists every column in up.sql that may hold a merchant's
+// personal data, as "table.column".
+var personalData = map[string]string{
+	"remittance_settings.bank_account_number": deleted,
+	"remittance_settings.ifsc":                deleted,
+	"remittance_settings.beneficiary_name":    deleted,
+	"payouts.bank_account_number":             masked,
+	"payouts.beneficiary_name":                masked,
+	"payouts.ifsc":                            retained,
+	"balance_alerts.mandate_id":               deleted,
+	"billing_profiles.legal_name":             deleted,
+	"billing_profiles.gstin":                  deleted,
+	"billing_profiles.address":                deleted,
+	"invoices.legal_name":                     retained,
+	"invoices.gstin":                          retained,
+	"invoices.address":                        retained,
+	"remittance_batches.failure_reason":       retained,
+	"wallet_adjustments.reason":               retained,
+	"wallet_adjustments.review_note":          retained,
+	"admin_audit_log.details":                 retained,
+}
+
+// noPersonalData lists the tables with no column in personalData.
+var noPersonalData = []string{
+	"remittance_eligibility", "shipment_events", "wallets", "transactions", "recharge_intents",
+	"gateway_events", "remittance_batch_items", "courier_statements", "courier_statement_lines",
+	"invoice_sequences", "credit_lines", "credit_bills", "subscriptions",
+}
+
+// mayBePersonal matches column names that suggest personal data, so a new one
+// must be added to personalData.
+var mayBePersonal = regexp.MustCompile(`name|email|phone|address|bank_account|ifsc|gstin|pan|mandate|reason|note|details`)
+
+// schemaColumns reads the columns of each table in up.sql.
+func schemaColumns(t *testing.T) map[string][]string {
+	f, err := os.Open("up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	createTable := regexp.MustCompile(`^CREATE TABLE (\w+) \($`)
+	column := regexp.MustCompile(`^\s+(\w+) [A-Z]`)
+	tables := map[string][]string{}
+	var table string
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		line := sc.Text()
+		switch m := createTable.FindStringSubmatch(line); {
+		case m != nil:
+			table = m[1]
+			tables[table] = nil
+		case strings.HasPrefix(line, ")"):
+			table = ""
+		case table != "":
+			if m := column.FindStringSubmatch(line); m != nil {
+				switch m[1] {
+				case "PRIMARY", "FOREIGN", "UNIQUE", "CHECK":
+				default:
+					tables[table] = append(tables[table], m[1])
+				}
+			}
+		}
+	}
+	if len(tables) == 0 {
+		t.Fatal("no tables in up.sql")
+	}
+	return tables
+}
+
+func TestPersonalDataIsClassified(t *testing.T) {
+	tables := schemaColumns(t)
+	classified := map[string]bool{}
+	for _, table := range noPersonalData {
+		classified[table] = true
+	}
+	for col := range personalData {
+		table, name, _ := strings.Cut(col, ".")
+		classified[table] = true
+		found := false
+		for _, c := range tables[table] {
+			found = found || c == name
+		}
+		if !found {
+			t.Errorf("%s is not in up.sql", col)
+		}
+	}
+
+	for table, columns := range tables {
+		if !classified[table] {
+			t.Errorf("table %s is neither in personalData nor noPersonalData", table)
+		}
+		for _, c := range columns {
+			if _, ok := personalData[table+"."+c]; !ok && mayBePersonal.MatchString(c) {
+				t.Errorf("%s.%s may hold personal data but is not in personalData", table, c)
+			}
+		}
+	}
+}
+
+func TestErasureCoversPersonalData(t *testing.T) {
+	db, m := sqltest.Open(t)
+	var ran []*sqltest.Expectation
+	m.ExpectBegin()
+	for _, stmt := range []string{
+		"DELETE FROM remittance_settings",
+		"DELETE FROM balance_alerts",
+		"DELETE FROM subscriptions",
+		"UPDATE payouts",
+		"UPDATE wallets",
+		"UPDATE credit_lines",
+		"INSERT INTO admin_audit_log",
+	} {
+		ran = append(ran, m.ExpectExec(stmt).WillReturnResult(1))
+	}
+	m.ExpectCommit()
+	ran = append(ran, m.ExpectExec("DELETE FROM billing_profiles").WithArgs("acct").WillReturnResult(1))
+
+	ctx := context.Background()
+	if err := NewPostgresRepository(db).EraseAccountData(ctx, "acct", AuditEntry{Action: AuditAccountErase, AccountID: "acct"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := invoice.NewPostgresRepository(db).DeleteBillingProfile(ctx, "acct"); err != nil {
+		t.Fatal(err)
+	}
+
+	deletes := func(table string) bool {
+		for _, e := range ran {
+			if strings.HasPrefix(e.Query, "DELETE FROM "+table+" WHERE account_id = $1") {
+				return true
+			}
+		}
+		return false
+	}
+	overwrites := func(table, col string) bool {
+		for _, e := range ran {
+			if strings.HasPrefix(e.Query, "UPDATE "+table+" SET") && strings.Contains(e.Query, " "+col+" = ") {
+				return true
+			}
+		}
+		return false
+	}
+	for col, erasure := range personalData {
+		table, name, _ := strings.Cut(col, ".")
+		switch erasure {
+		case deleted:
+			if !deletes(table) {
+				t.Errorf("erasure does not delete %s", col)
+			}
+		case masked:
+			if !overwrites(table, name) {
+				t.Errorf("erasure does not overwrite %s", col)
+			}
+		case retained:
+			if deletes(table) || overwrites(table, name) {
+				t.Errorf("erasure changes %s, which must be retained", col)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package payment
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reconciliation outcomes for a courier statement line.
+const (
+	LineStatusMatched        = "matched"
+	LineStatusShortPayment   = "short_payment"   // Courier remitted less than the COD amount.
+	LineStatusAmountMismatch = "amount_mismatch" // Courier remitted more than the COD amount.
+	LineStatusMissingAWB     = "missing_awb"     // No COD shipment has this AWB.
+	LineStatusDuplicate      = "duplicate"       // The AWB was already reconciled.
+)
+
+var (
+	ErrStatementExists = errors.New("courier statement has already been imported")
+	ErrEmptyStatement  = errors.New("courier statement has no lines")
+)
+
+// CourierStatement is a bulk COD remittance file received from a courier.
+type CourierStatement struct {
+	ID          string
+	Courier     string
+	Reference   string
+	TotalAmount float64
+	Lines       []StatementLine
+	ImportedAt  time.Time
+}
+
+// StatementLine is one AWB remitted in a courier statement, with the result of
+// matching it against our COD shipments.
+type StatementLine struct {
+	LineNo         int
+	AWB            string
+	Amount         float64
+	OrderID        string
+	ExpectedAmount float64
+	Status         string
+}
+
+// Flagged returns the lines that did not reconcile cleanly.
+func (s *CourierStatement) Flagged() []StatementLine {
+	var flagged []StatementLine
+	for _, l := range s.Lines {
+		if l.Status != LineStatusMatched {
+			flagged = append(flagged, l)
+		}
+	}
+	return flagged
+}
+
+// ParseCourierStatement reads a courier CSV statement. The first row is a header
+// that must contain "awb" and "amount" columns; other columns are ignored.
+func ParseCourierStatement(r io.Reader) ([]StatementLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyStatement
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read statement header: %w", err)
+	}
+
+	awbCol, amountCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "awb", "awb_number", "awb no":
+			awbCol = i
+		case "amount", "cod_amount", "cod amount":
+			amountCol = i
+		}
+	}
+	if awbCol < 0 || amountCol < 0 {
+		return nil, errors.New("statement header must contain awb and amount columns")
+	}
+
+	var lines []StatementLine
+	for lineNo := 2; ; lineNo++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read statement line %d: %w", lineNo, err)
+		}
+		if len(record) <= awbCol || len(record) <= amountCol {
+			return nil, fmt.Errorf("statement line %d is missing columns", lineNo)
+		}
+
+		awb := strings.TrimSpace(record[awbCol])
+		if awb == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(record[amountCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount on statement line %d: %w", lineNo, err)
+		}
+		lines = append(lines, StatementLine{LineNo: lineNo, AWB: awb, Amount: amount})
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrEmptyStatement
+	}
+	return lines, nil
+}
+
+// reconcileLine classifies a statement line against the expected COD amount.
+func reconcileLine(paid, expected float64) string {
+	switch diff := paid - expected; {
+	case diff < -0.005:
+		return LineStatusShortPayment
+	case diff > 0.005:
+		return LineStatusAmountMismatch
+	default:
+		return LineStatusMatched
+	}
+}
@@ -0,0 +1,75 @@
+package payment
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCourierStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []StatementLine
+		wantErr bool
+	}{
+		{
+			name: "basic",
+			csv:  "awb,amount\nAWB1,250.50\nAWB2,100\n",
+			want: []StatementLine{{LineNo: 2, AWB: "AWB1", Amount: 250.5}, {LineNo: 3, AWB: "AWB2", Amount: 100}},
+		},
+		{
+			name: "alternate headers and extra columns",
+			csv:  "Date, AWB No, Courier, COD Amount\n2024-05-01, AWB1 , bluedart, 99.99\n",
+			want: []StatementLine{{LineNo: 2, AWB: "AWB1", Amount: 99.99}},
+		},
+		{
+			name: "blank awb skipped",
+			csv:  "awb_number,cod_amount\n,10\nAWB2,20\n",
+			want: []StatementLine{{LineNo: 3, AWB: "AWB2", Amount: 20}},
+		},
+		{name: "missing amount column", csv: "awb,total\nAWB1,10\n", wantErr: true},
+		{name: "missing columns on a line", csv: "awb,amount\nAWB1\n", wantErr: true},
+		{name: "invalid amount", csv: "awb,amount\nAWB1,ten\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCourierStatement(strings.NewReader(tt.csv))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCourierStatement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCourierStatement() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCourierStatementEmpty(t *testing.T) {
+	for _, csv := range []string{"", "awb,amount\n", "awb,amount\n,5\n"} {
+		if _, err := ParseCourierStatement(strings.NewReader(csv)); !errors.Is(err, ErrEmptyStatement) {
+			t.Errorf("ParseCourierStatement(%q) error = %v, want %v", csv, err, ErrEmptyStatement)
+		}
+	}
+}
+
+func TestReconcileLine(t *testing.T) {
+	tests := []struct {
+		paid, expected float64
+		want           string
+	}{
+		{100, 100, LineStatusMatched},
+		{100.004, 100, LineStatusMatched},
+		{99.996, 100, LineStatusMatched},
+		{99.99, 100, LineStatusShortPayment},
+		{0, 100, LineStatusShortPayment},
+		{100.01, 100, LineStatusAmountMismatch},
+		{50, 0, LineStatusAmountMismatch},
+	}
+	for _, tt := range tests {
+		if got := reconcileLine(tt.paid, tt.expected); got != tt.want {
+			t.Errorf("reconcileLine(%v, %v) = %q, want %q", tt.paid, tt.expected, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Remittance batch statuses.
+const (
+	BatchStatusCreated  = "created"
+	BatchStatusApproved = "approved"
+	BatchStatusPaid     = "paid"
+	BatchStatusFailed   = "failed"
+)
+
+var (
+	ErrBatchNotFound          = errors.New("remittance batch not found")
+	ErrInvalidBatchTransition = errors.New("invalid remittance batch status transition")
+	ErrMissingUTR             = errors.New("a bank UTR reference is required to mark a batch paid")
+	ErrBatchHasPayout         = errors.New("remittance batch is settled by a bank payout and cannot be marked paid or failed by hand")
+)
+
+// batchTransitions lists the statuses each batch status may move to.
+var batchTransitions = map[string][]string{
+	BatchStatusCreated:  {BatchStatusApproved, BatchStatusFailed},
+	BatchStatusApproved: {BatchStatusPaid, BatchStatusFailed},
+}
+
+func canTransitionBatch(from, to string) bool {
+	for _, s := range batchTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// RemittanceBatch groups the COD orders remitted to a merchant in one payout.
+type RemittanceBatch struct {
+	ID            string
+	AccountID     string
+	Status        string
+	TotalAmount   float64
+	Fee           float64 // Early settlement fee withheld from the payout.
+	Items         []RemittanceDetail
+	UTR           string // Bank reference, set once the batch is paid.
+	FailureReason string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// NetAmount is the amount paid out to the merchant after fees.
+func (b *RemittanceBatch) NetAmount() float64 {
+	return b.TotalAmount - b.Fee
+}
+
+// RemittanceCycle configures when collected COD becomes payable to merchants.
+type RemittanceCycle struct {
+	DelayDays       int            // Days after delivery before COD is remitted (T+N).
+	Weekdays        []time.Weekday // Days of the week on which batches are created.
+	EarlyFeePercent float64        // Fee charged to settle COD before the cycle delay.
+}
+
+// DefaultRemittanceCycle remits at T+7, twice a week, with a 1.5% early settlement fee.
+func DefaultRemittanceCycle() RemittanceCycle {
+	return RemittanceCycle{DelayDays: 7, Weekdays: []time.Weekday{time.Tuesday, time.Friday}, EarlyFeePercent: 1.5}
+}
+
+// ParseRemittanceCycle builds a cycle from a delay and a comma separated list of
+// weekday names such as "tue,fri".
+func ParseRemittanceCycle(delayDays int, weekdays string) (RemittanceCycle, error) {
+	cycle := RemittanceCycle{DelayDays: delayDays}
+	for _, name := range strings.Split(weekdays, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.HasPrefix(strings.ToLower(d.String()), name) && len(name) >= 3 {
+				cycle.Weekdays = append(cycle.Weekdays, d)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return RemittanceCycle{}, fmt.Errorf("unknown weekday %q", name)
+		}
+	}
+	if delayDays < 0 || len(cycle.Weekdays) == 0 {
+		return RemittanceCycle{}, fmt.Errorf("invalid remittance cycle T+%d on %q", delayDays, weekdays)
+	}
+	return cycle, nil
+}
+
+// Cutoff returns the latest delivery time eligible for remittance at t.
+func (c RemittanceCycle) Cutoff(t time.Time) time.Time {
+	return t.AddDate(0, 0, -c.DelayDays)
+}
+
+// IsRunDay reports whether batches should be created on the day of t.
+func (c RemittanceCycle) IsRunDay(t time.Time) bool {
+	for _, d := range c.Weekdays {
+		if t.Weekday() == d {
+			return true
+		}
+	}
+	return false
+}
+
+// RemittanceScheduler creates remittance batches once a day. The service
+// batches the merchants whose plan remits on that day.
+type RemittanceScheduler struct {
+	service Service
+	lastRun string
+}
+
+// NewRemittanceScheduler creates a scheduler for the service.
+func NewRemittanceScheduler(service Service) *RemittanceScheduler {
+	return &RemittanceScheduler{service: service}
+}
+
+// Run checks every interval whether a batch run is due, until ctx is cancelled.
+// Batches are created at most once per day.
+func (s *RemittanceScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RemittanceScheduler) tick(ctx context.Context, now time.Time) {
+	day := now.Format("2006-01-02")
+	if s.lastRun == day {
+		return
+	}
+
+	batches, err := s.service.CreateRemittanceBatches(ctx, now)
+	if err != nil {
+		log.Printf("Failed to create remittance batches: %v", err)
+		return
+	}
+	s.lastRun = day
+	log.Printf("Created %d remittance batches", len(batches))
+}
@@ -0,0 +1,55 @@
+package payment
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRemittanceCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    int
+		weekdays string
+		want     []time.Weekday
+		wantErr  bool
+	}{
+		{"short names", 7, "tue,fri", []time.Weekday{time.Tuesday, time.Friday}, false},
+		{"full names and spacing", 2, " Monday , WEDNESDAY,friday ", []time.Weekday{time.Monday, time.Wednesday, time.Friday}, false},
+		{"empty entries skipped", 0, "sat,,", []time.Weekday{time.Saturday}, false},
+		{"same day", 0, "sun", []time.Weekday{time.Sunday}, false},
+		{"too short", 7, "tu", nil, true},
+		{"unknown day", 7, "tue,funday", nil, true},
+		{"no days", 7, "", nil, true},
+		{"negative delay", -1, "tue", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycle, err := ParseRemittanceCycle(tt.delay, tt.weekdays)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRemittanceCycle(%d, %q) error = %v, wantErr %v", tt.delay, tt.weekdays, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if cycle.DelayDays != tt.delay || !reflect.DeepEqual(cycle.Weekdays, tt.want) {
+				t.Errorf("ParseRemittanceCycle(%d, %q) = %+v, want T+%d on %v", tt.delay, tt.weekdays, cycle, tt.delay, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemittanceCycleSchedule(t *testing.T) {
+	cycle := DefaultRemittanceCycle()
+	tuesday := time.Date(2024, time.May, 7, 10, 0, 0, 0, time.UTC)
+
+	if got, want := cycle.Cutoff(tuesday), time.Date(2024, time.April, 30, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Cutoff() = %v, want %v", got, want)
+	}
+	for offset, want := range []bool{true, false, false, true, false, false, false} {
+		day := tuesday.AddDate(0, 0, offset)
+		if got := cycle.IsRunDay(day); got != want {
+			t.Errorf("IsRunDay(%s) = %v, want %v", day.Weekday(), got, want)
+		}
+	}
+}
@@ -3,16 +3,67 @@ package payment
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"math"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // WalletRepository defines the interface for wallet operations.
 type Repository interface {
 	Close() 
-	RechargeWallet(ctx context.Context, accountID string, amount float64) (float64, error)
-	DeductBalance(ctx context.Context, accountID string, amount float64, orderID string) (float64, error)
-	ProcessRemittance(ctx context.Context, accountID string, orderIDs []string) ([]RemittanceDetail, error)
-	GetWalletDetails(ctx context.Context, accountID string) (float64, []Transaction, error)
+	RechargeWallet(ctx context.Context, accountID string, currency string, amount float64, fx *FXConversion) (float64, error)
+	DeductBalance(ctx context.Context, accountID string, currency string, amount float64, orderID string) (float64, error)
+	ProcessRemittance(ctx context.Context, accountID string, orderIDs []string, deliveredBefore time.Time) ([]RemittanceDetail, error)
+	GetWalletDetails(ctx context.Context, accountID string, filter TransactionFilter) ([]WalletBalance, []Transaction, error)
+	ListTransactions(ctx context.Context, accountID string, filter TransactionFilter) ([]Transaction, error)
+	GetBalanceBefore(ctx context.Context, accountID string, currency string, before time.Time) (float64, error)
+	GetBalance(ctx context.Context, accountID string, currency string) (float64, error)
+	SequenceWalletEvents(ctx context.Context) (int, error)
+	ListWalletEvents(ctx context.Context, accountID string, afterSeq int64, limit int) ([]WalletEvent, error)
+	LatestWalletEventSeq(ctx context.Context) (int64, error)
+	CreateRechargeIntent(ctx context.Context, intent RechargeIntent) error
+	ApplyGatewayEvent(ctx context.Context, event GatewayEvent) (*RechargeIntent, error)
+	ApplyShipmentEvent(ctx context.Context, event ShipmentEvent) error
+	CreateRemittanceBatches(ctx context.Context, deliveredBefore time.Time, accountID string, planCode string, feePercent float64) ([]RemittanceBatch, error)
+	GetRemittanceBatch(ctx context.Context, batchID string) (*RemittanceBatch, error)
+	ListRemittanceBatches(ctx context.Context, accountID string) ([]RemittanceBatch, error)
+	UpdateRemittanceBatchStatus(ctx context.Context, batchID string, status string, utr string, reason string) (*RemittanceBatch, error)
+	SettleBatchToWallet(ctx context.Context, batchID string) (*RemittanceBatch, error)
+	GetRemittanceSettings(ctx context.Context, accountID string) (*RemittanceSettings, error)
+	PutRemittanceSettings(ctx context.Context, settings RemittanceSettings) error
+	CreatePayout(ctx context.Context, payout Payout) error
+	UpdatePayout(ctx context.Context, payout Payout) error
+	ListDuePayouts(ctx context.Context, now time.Time, limit int) ([]Payout, error)
+	ListPayouts(ctx context.Context, accountID string) ([]Payout, error)
+	BatchHasPayout(ctx context.Context, batchID string) (bool, error)
+	ImportCourierStatement(ctx context.Context, statement CourierStatement) (*CourierStatement, error)
+	GetCreditLine(ctx context.Context, accountID string) (*CreditLine, error)
+	PutCreditLine(ctx context.Context, line CreditLine) (*CreditLine, error)
+	ListCreditLinesDue(ctx context.Context, now time.Time) ([]CreditLine, error)
+	CloseCreditCycle(ctx context.Context, accountID string, now time.Time) (*CreditBill, error)
+	ListCreditBills(ctx context.Context, accountID string) ([]CreditBill, error)
+	ListUnpaidBills(ctx context.Context) ([]CreditBill, error)
+	MarkBillReminded(ctx context.Context, billID string, remindersSent int) error
+	MarkBillOverdue(ctx context.Context, billID string) error
+	GetBalanceAlert(ctx context.Context, accountID string) (*BalanceAlert, error)
+	PutBalanceAlert(ctx context.Context, alert BalanceAlert) (*BalanceAlert, error)
+	SetBalanceAlertTriggered(ctx context.Context, accountID string, triggered bool) (bool, error)
+	GetSubscription(ctx context.Context, accountID string) (*Subscription, error)
+	ChangeSubscription(ctx context.Context, sub Subscription, expectedPlan string, charge float64) error
+	ListSubscriptionsDue(ctx context.Context, now time.Time) ([]Subscription, error)
+	RenewSubscription(ctx context.Context, accountID string, periodStart, periodEnd time.Time, fee float64) error
+	MarkSubscriptionPastDue(ctx context.Context, accountID string) error
+	SetWalletFrozen(ctx context.Context, accountID string, frozen bool, entry AuditEntry) error
+	CreateAdjustment(ctx context.Context, adj Adjustment, entry AuditEntry) error
+	GetAdjustment(ctx context.Context, adjustmentID string) (*Adjustment, error)
+	ReviewAdjustment(ctx context.Context, adjustmentID string, status string, reviewer string, note string, entry AuditEntry) (*Adjustment, error)
+	ListAdjustments(ctx context.Context, status string) ([]Adjustment, error)
+	ListAuditEntries(ctx context.Context, accountID string, limit int) ([]AuditEntry, error)
+	ExportAccountTables(ctx context.Context, accountID string) (map[string][]map[string]any, error)
+	HasOutstandingFunds(ctx context.Context, accountID string) (bool, error)
+	EraseAccountData(ctx context.Context, accountID string, entry AuditEntry) error
 }
 
 // Implementation of WalletRepository.
@@ -31,18 +82,38 @@ type RemittanceDetail struct {
 type Transaction struct {
 	TransactionID string
 	TransactionType string
+	Currency       string
 	Amount         float64
 	OrderID        sql.NullString
+	Reference      sql.NullString
+	BalanceAfter   float64 // Wallet balance after this transaction.
+	FX             *FXConversion // Set when the amount was converted from another currency.
 	Timestamp      time.Time
 }
 
+// RechargeIntent represents a wallet recharge awaiting confirmation from the payment gateway.
+type RechargeIntent struct {
+	ID             string
+	AccountID      string
+	Amount         float64 // Charged by the gateway, in Currency.
+	Currency       string
+	WalletCurrency string  // The wallet credited on capture.
+	CreditAmount   float64 // Credited to the wallet, in WalletCurrency.
+	FXRate         float64 // Rate locked when the intent was created.
+	Status         string
+	CheckoutRef    string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
 // NewPostgresWalletRepository creates a new WalletRepository implementation.
 func NewPostgresRepository(db *sql.DB) Repository {
 	return &postgresRepository{db: db}
 }
 
-// RechargeWallet adds funds to the user's wallet.
-func (r *postgresRepository) RechargeWallet(ctx context.Context, accountID string, amount float64) (float64, error) {
+// RechargeWallet adds funds to the user's wallet in currency. fx records the
+// conversion when the merchant paid in another currency.
+func (r *postgresRepository) RechargeWallet(ctx context.Context, accountID string, currency string, amount float64, fx *FXConversion) (float64, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, err
@@ -56,31 +127,13 @@ func (r *postgresRepository) RechargeWallet(ctx context.Context, accountID strin
 	}()
 
 	// Update wallet balance.
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO wallets (account_id, balance, updated_at)
-		VALUES ($1, $2, NOW())
-		ON CONFLICT (account_id)
-		DO UPDATE SET balance = wallets.balance + $2, updated_at = NOW()`,
-		accountID, amount,
-	)
-	if err != nil {
-		return 0, err
-	}
-
-	// Fetch updated balance.
-	var newBalance float64
-	err = tx.QueryRowContext(ctx, `
-		SELECT balance FROM wallets WHERE account_id = $1`, accountID).Scan(&newBalance)
+	newBalance, err := creditWallet(ctx, tx, accountID, currency, amount)
 	if err != nil {
 		return 0, err
 	}
 
 	// Log the transaction.
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO transactions (account_id, transaction_type, amount)
-		VALUES ($1, 'recharge', $2)`,
-		accountID, amount,
-	)
+	err = insertTransaction(ctx, tx, accountID, currency, TransactionRecharge, amount, "", "", fx)
 	if err != nil {
 		return 0, err
 	}
@@ -89,7 +142,7 @@ func (r *postgresRepository) RechargeWallet(ctx context.Context, accountID strin
 }
 
 // DeductBalance deducts funds from the user's wallet for shipping an order.
-func (r *postgresRepository) DeductBalance(ctx context.Context, accountID string, amount float64, orderID string) (float64, error) {
+func (r *postgresRepository) DeductBalance(ctx context.Context, accountID string, currency string, amount float64, orderID string) (float64, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, err
@@ -102,41 +155,14 @@ func (r *postgresRepository) DeductBalance(ctx context.Context, accountID string
 		err = tx.Commit()
 	}()
 
-	// Check if the wallet has sufficient balance.
-	var currentBalance float64
-	err = tx.QueryRowContext(ctx, `
-		SELECT balance FROM wallets WHERE account_id = $1`, accountID).Scan(&currentBalance)
-	if err != nil {
-		return 0, err
-	}
-	if currentBalance < amount {
-		return 0, sql.ErrNoRows // Insufficient funds.
-	}
-
-	// Deduct the balance.
-	_, err = tx.ExecContext(ctx, `
-		UPDATE wallets SET balance = balance - $2, updated_at = NOW()
-		WHERE account_id = $1`,
-		accountID, amount,
-	)
+	// Deduct the balance, subject to the credit policy.
+	newBalance, err := debitWallet(ctx, tx, accountID, currency, amount)
 	if err != nil {
 		return 0, err
 	}
 
 	// Log the transaction.
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO transactions (account_id, transaction_type, amount, order_id)
-		VALUES ($1, 'deduction', $2, $3)`,
-		accountID, amount, orderID,
-	)
-	if err != nil {
-		return 0, err
-	}
-
-	// Fetch updated balance.
-	var newBalance float64
-	err = tx.QueryRowContext(ctx, `
-		SELECT balance FROM wallets WHERE account_id = $1`, accountID).Scan(&newBalance)
+	err = recordTransaction(ctx, tx, accountID, currency, TransactionDeduction, amount, orderID, "")
 	if err != nil {
 		return 0, err
 	}
@@ -144,10 +170,9 @@ func (r *postgresRepository) DeductBalance(ctx context.Context, accountID string
 	return newBalance, nil
 }
 
-// ProcessRemittance processes COD remittance for delivered orders after 15 days.
-func (r *postgresRepository) ProcessRemittance(ctx context.Context, accountID string, orderIDs []string) ([]RemittanceDetail, error) {
-	var details []RemittanceDetail
-
+// ProcessRemittance processes COD remittance for the given orders delivered before the cutoff.
+// Orders already held in an open remittance batch are skipped.
+func (r *postgresRepository) ProcessRemittance(ctx context.Context, accountID string, orderIDs []string, deliveredBefore time.Time) (details []RemittanceDetail, err error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -157,42 +182,43 @@ func (r *postgresRepository) ProcessRemittance(ctx context.Context, accountID st
 			tx.Rollback()
 			return
 		}
-		err = tx.Commit()
+		if err = tx.Commit(); err != nil {
+			details = nil
+		}
 	}()
 
 	for _, orderID := range orderIDs {
 		var amount float64
-		err = tx.QueryRowContext(ctx, `
-			SELECT total_price FROM orders 
-			WHERE id = $1 AND delivery_date <= NOW() - INTERVAL '15 days'
-			AND remittance_processed = FALSE`, orderID).Scan(&amount)
-		if err == sql.ErrNoRows {
+		qerr := tx.QueryRowContext(ctx, `
+			SELECT `+receivedCOD+` FROM remittance_eligibility
+			WHERE order_id = $1 AND account_id = $2
+			AND shipment_status = 'delivered' AND delivered_at <= $3
+			AND cod_collected_by_courier = TRUE
+			AND remittance_processed = FALSE
+			AND order_id NOT IN (`+openBatchOrders+`)`, orderID, accountID, deliveredBefore).Scan(&amount)
+		if qerr == sql.ErrNoRows {
 			details = append(details, RemittanceDetail{OrderID: orderID, Amount: 0, Processed: false})
 			continue
-		} else if err != nil {
-			return nil, err
+		} else if qerr != nil {
+			return nil, qerr
 		}
 
 		// Update the wallet balance.
-		_, err = tx.ExecContext(ctx, `
-			UPDATE wallets SET balance = balance + $1, updated_at = NOW()
-			WHERE account_id = $2`, amount, accountID)
+		_, err = creditWallet(ctx, tx, accountID, BaseCurrency, amount)
 		if err != nil {
 			return nil, err
 		}
 
 		// Mark the order as remitted.
 		_, err = tx.ExecContext(ctx, `
-			UPDATE orders SET remittance_processed = TRUE
-			WHERE id = $1`, orderID)
+			UPDATE remittance_eligibility SET remittance_processed = TRUE, updated_at = NOW()
+			WHERE order_id = $1`, orderID)
 		if err != nil {
 			return nil, err
 		}
 
 		// Log the transaction.
-		_, err = tx.ExecContext(ctx, `
-			INSERT INTO transactions (account_id, transaction_type, amount, order_id)
-			VALUES ($1, 'remittance', $2, $3)`, accountID, amount, orderID)
+		err = recordTransaction(ctx, tx, accountID, BaseCurrency, TransactionRemittance, amount, orderID, "")
 		if err != nil {
 			return nil, err
 		}
@@ -203,34 +229,1692 @@ func (r *postgresRepository) ProcessRemittance(ctx context.Context, accountID st
 	return details, nil
 }
 
-// GetWalletDetails retrieves wallet balance and transaction history for a user.
-func (r *postgresRepository) GetWalletDetails(ctx context.Context, accountID string) (float64, []Transaction, error) {
-	var balance float64
-	err := r.db.QueryRowContext(ctx, `
-		SELECT balance FROM wallets WHERE account_id = $1`, accountID).Scan(&balance)
+// GetWalletDetails retrieves the balance of each of a user's wallets and the
+// transactions matching filter. An account that has never been funded has an
+// empty INR wallet.
+func (r *postgresRepository) GetWalletDetails(ctx context.Context, accountID string, filter TransactionFilter) ([]WalletBalance, []Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT currency, balance FROM wallets WHERE account_id = $1
+		ORDER BY currency <> 'INR', currency`, accountID)
 	if err != nil {
-		return 0, nil, err
+		return nil, nil, err
 	}
+	defer rows.Close()
 
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT transaction_id, transaction_type, amount, order_id, created_at 
-		FROM transactions WHERE account_id = $1 ORDER BY created_at DESC`, accountID)
+	var balances []WalletBalance
+	for rows.Next() {
+		var b WalletBalance
+		if err := rows.Scan(&b.Currency, &b.Balance); err != nil {
+			return nil, nil, err
+		}
+		balances = append(balances, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(balances) == 0 {
+		balances = []WalletBalance{{Currency: BaseCurrency}}
+	}
+
+	transactions, err := r.ListTransactions(ctx, accountID, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return balances, transactions, nil
+}
+
+// ListTransactions retrieves an account's transactions matching filter.
+func (r *postgresRepository) ListTransactions(ctx context.Context, accountID string, filter TransactionFilter) ([]Transaction, error) {
+	query := `
+		SELECT transaction_id, transaction_type, currency, amount, order_id, reference, balance_after,
+			source_amount, source_currency, fx_rate, created_at
+		FROM transactions WHERE account_id = $1`
+	args := []any{accountID}
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+cond, len(args))
+	}
+
+	if filter.Currency != "" {
+		where("currency = $%d", filter.Currency)
+	}
+	if filter.Type != "" {
+		where("transaction_type = $%d", filter.Type)
+	}
+	if filter.OrderID != "" {
+		where("order_id = $%d", filter.OrderID)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+	order := "DESC"
+	if filter.Ascending {
+		order = "ASC"
+	}
+	if filter.Cursor != "" && filter.Ascending {
+		where("transaction_id > $%d", filter.Cursor)
+	} else if filter.Cursor != "" {
+		where("transaction_id < $%d", filter.Cursor)
+	}
+	query += " ORDER BY transaction_id " + order
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 	defer rows.Close()
 
 	var transactions []Transaction
 	for rows.Next() {
 		var txn Transaction
-		err = rows.Scan(&txn.TransactionID, &txn.TransactionType, &txn.Amount, &txn.OrderID, &txn.Timestamp)
+		var sourceAmount, rate sql.NullFloat64
+		var sourceCurrency sql.NullString
+		err = rows.Scan(&txn.TransactionID, &txn.TransactionType, &txn.Currency, &txn.Amount, &txn.OrderID, &txn.Reference, &txn.BalanceAfter,
+			&sourceAmount, &sourceCurrency, &rate, &txn.Timestamp)
 		if err != nil {
-			return 0, nil, err
+			return nil, err
+		}
+		if sourceCurrency.Valid {
+			txn.FX = &FXConversion{SourceAmount: sourceAmount.Float64, SourceCurrency: sourceCurrency.String, Rate: rate.Float64}
 		}
 		transactions = append(transactions, txn)
 	}
 
-	return balance, transactions, nil
+	return transactions, rows.Err()
+}
+
+// GetBalance returns the current balance of the wallet in currency, or zero if
+// the account has no such wallet.
+func (r *postgresRepository) GetBalance(ctx context.Context, accountID string, currency string) (float64, error) {
+	var balance float64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT balance FROM wallets WHERE account_id = $1 AND currency = $2`, accountID, currency).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return balance, err
+}
+
+// GetBalanceBefore returns the balance of the wallet in currency as it stood just before the given time.
+func (r *postgresRepository) GetBalanceBefore(ctx context.Context, accountID string, currency string, before time.Time) (float64, error) {
+	var balance float64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT balance_after FROM transactions
+		WHERE account_id = $1 AND currency = $2 AND created_at < $3
+		ORDER BY transaction_id DESC LIMIT 1`, accountID, currency, before).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return balance, err
+}
+
+// SequenceWalletEvents numbers unsequenced transactions in commit order. Only
+// transactions whose database transaction ended before every transaction still
+// running are numbered, so a transaction that commits late can never receive a
+// lower number than one already streamed. Runs are serialized by an advisory
+// lock, which keeps the numbering gapless.
+func (r *postgresRepository) SequenceWalletEvents(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('wallet_event_seq'))`); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE transactions t SET seq = n.seq
+		FROM (
+			SELECT transaction_id,
+				(SELECT COALESCE(MAX(seq), 0) FROM transactions)
+					+ ROW_NUMBER() OVER (ORDER BY xact_id, transaction_id) AS seq
+			FROM transactions
+			WHERE seq IS NULL AND xact_id < pg_snapshot_xmin(pg_current_snapshot())
+		) n
+		WHERE t.transaction_id = n.transaction_id`)
+	if err != nil {
+		return 0, err
+	}
+	sequenced, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(sequenced), nil
+}
+
+// ListWalletEvents retrieves up to limit sequenced wallet events after afterSeq,
+// for one account or all accounts if accountID is empty.
+func (r *postgresRepository) ListWalletEvents(ctx context.Context, accountID string, afterSeq int64, limit int) ([]WalletEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT seq, account_id, currency, transaction_type, amount, balance_after,
+			COALESCE(order_id, ''), COALESCE(reference, ''), created_at
+		FROM transactions
+		WHERE seq > $1 AND ($2 = '' OR account_id = $2)
+		ORDER BY seq LIMIT $3`, afterSeq, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []WalletEvent
+	for rows.Next() {
+		var e WalletEvent
+		err := rows.Scan(&e.Seq, &e.AccountID, &e.Currency, &e.Type, &e.Amount, &e.BalanceAfter, &e.OrderID, &e.Reference, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// LatestWalletEventSeq returns the number of the latest sequenced wallet event.
+func (r *postgresRepository) LatestWalletEventSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM transactions`).Scan(&seq)
+	return seq, err
+}
+
+// CreateRechargeIntent stores a new recharge intent.
+func (r *postgresRepository) CreateRechargeIntent(ctx context.Context, intent RechargeIntent) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO recharge_intents (id, account_id, amount, currency, wallet_currency, credit_amount, fx_rate, status, checkout_ref, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		intent.ID, intent.AccountID, intent.Amount, intent.Currency, intent.WalletCurrency, intent.CreditAmount, intent.FXRate,
+		intent.Status, intent.CheckoutRef, intent.CreatedAt, intent.UpdatedAt,
+	)
+	return err
+}
+
+// ApplyGatewayEvent moves a recharge intent to the status reported by the gateway.
+// The wallet is credited only when the event confirms capture. Events that were
+// already applied, and events for intents that already reached a final status,
+// leave the intent unchanged.
+func (r *postgresRepository) ApplyGatewayEvent(ctx context.Context, event GatewayEvent) (*RechargeIntent, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Lock the intent so concurrent deliveries of the same webhook are serialized.
+	var intent RechargeIntent
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, account_id, amount, currency, wallet_currency, credit_amount, fx_rate, status, checkout_ref, created_at, updated_at
+		FROM recharge_intents WHERE id = $1 FOR UPDATE`, event.IntentID).Scan(
+		&intent.ID, &intent.AccountID, &intent.Amount, &intent.Currency, &intent.WalletCurrency, &intent.CreditAmount, &intent.FXRate,
+		&intent.Status, &intent.CheckoutRef, &intent.CreatedAt, &intent.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		err = ErrIntentNotFound
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Record the event; a conflict means the gateway redelivered it.
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO gateway_events (event_id, intent_id, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO NOTHING`,
+		event.EventID, event.IntentID, event.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	apply, credit, err := gatewayTransition(&intent, event, inserted == 0)
+	if err != nil {
+		return nil, err
+	}
+	if !apply {
+		return &intent, nil
+	}
+
+	if credit {
+		if _, err = creditWallet(ctx, tx, intent.AccountID, intent.WalletCurrency, intent.CreditAmount); err != nil {
+			return nil, err
+		}
+		var fx *FXConversion
+		if intent.Currency != intent.WalletCurrency {
+			fx = &FXConversion{SourceAmount: intent.Amount, SourceCurrency: intent.Currency, Rate: intent.FXRate}
+		}
+		err = insertTransaction(ctx, tx, intent.AccountID, intent.WalletCurrency, TransactionRecharge, intent.CreditAmount, "", intent.ID, fx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE recharge_intents SET status = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING status, updated_at`, intent.ID, event.Status).Scan(&intent.Status, &intent.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &intent, nil
+}
+
+// ApplyShipmentEvent upserts an order's row in the remittance-eligibility
+// projection from a shipment event. Redelivered events, and events older than
+// the last one applied to the order, leave the row unchanged.
+func (r *postgresRepository) ApplyShipmentEvent(ctx context.Context, event ShipmentEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Record the event; a conflict means it was redelivered.
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO shipment_events (event_id, order_id, event_type, occurred_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id) DO NOTHING`,
+		event.EventID, event.OrderID, event.Type, event.OccurredAt,
+	)
+	if err != nil {
+		return err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return nil
+	}
+
+	var deliveredAt *time.Time
+	if event.Type == ShipmentDelivered {
+		deliveredAt = &event.OccurredAt
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO remittance_eligibility (order_id, account_id, awb, is_cod, cod_amount, shipment_status, delivered_at, last_event_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NOW())
+		ON CONFLICT (order_id) DO UPDATE SET
+			awb = COALESCE(EXCLUDED.awb, remittance_eligibility.awb),
+			is_cod = EXCLUDED.is_cod,
+			cod_amount = EXCLUDED.cod_amount,
+			shipment_status = EXCLUDED.shipment_status,
+			delivered_at = COALESCE(EXCLUDED.delivered_at, remittance_eligibility.delivered_at),
+			last_event_at = EXCLUDED.last_event_at,
+			updated_at = NOW()
+		WHERE remittance_eligibility.last_event_at <= EXCLUDED.last_event_at`,
+		event.OrderID, event.AccountID, event.AWB, event.IsCOD, event.CODAmount,
+		eventStatuses[event.Type], deliveredAt, event.OccurredAt,
+	)
+	return err
+}
+
+// receivedCOD is the COD amount of an order that the courier actually remitted to us.
+const receivedCOD = `LEAST(cod_amount, courier_remitted_amount)`
+
+// openBatchOrders selects the orders held by remittance batches that have not failed.
+const openBatchOrders = `
+	SELECT i.order_id FROM remittance_batch_items i
+	JOIN remittance_batches b ON b.id = i.batch_id
+	WHERE b.status <> 'failed'`
+
+// effectivePlan selects the plan in effect for an order's merchant, Lite unless
+// they have an active subscription.
+const effectivePlan = `COALESCE((SELECT s.plan_code FROM subscriptions s
+	WHERE s.account_id = remittance_eligibility.account_id AND s.status = 'active'), 'lite')`
+
+// CreateRemittanceBatches groups the unremitted COD orders delivered before the
+// cutoff into one batch per merchant. An empty accountID batches every merchant,
+// and an empty planCode merchants on every plan.
+// feePercent of each batch total is withheld as a settlement fee.
+func (r *postgresRepository) CreateRemittanceBatches(ctx context.Context, deliveredBefore time.Time, accountID string, planCode string, feePercent float64) ([]RemittanceBatch, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT order_id, account_id, `+receivedCOD+` FROM remittance_eligibility
+		WHERE is_cod = TRUE AND cod_collected_by_courier = TRUE
+		AND shipment_status = 'delivered' AND delivered_at <= $1
+		AND ($2 = '' OR account_id = $2)
+		AND ($3 = '' OR `+effectivePlan+` = $3)
+		AND remittance_processed = FALSE
+		AND order_id NOT IN (`+openBatchOrders+`)
+		ORDER BY account_id, delivered_at
+		FOR UPDATE`, deliveredBefore, accountID, planCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []RemittanceBatch
+	for rows.Next() {
+		var accountID string
+		var item RemittanceDetail
+		if err = rows.Scan(&item.OrderID, &accountID, &item.Amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if len(batches) == 0 || batches[len(batches)-1].AccountID != accountID {
+			batches = append(batches, RemittanceBatch{
+				ID:        uuid.NewString(),
+				AccountID: accountID,
+				Status:    BatchStatusCreated,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			})
+		}
+		b := &batches[len(batches)-1]
+		b.Items = append(b.Items, item)
+		b.TotalAmount += item.Amount
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range batches {
+		b := &batches[i]
+		b.Fee = math.Round(b.TotalAmount*feePercent) / 100
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO remittance_batches (id, account_id, status, total_amount, fee_amount, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			b.ID, b.AccountID, b.Status, b.TotalAmount, b.Fee, b.CreatedAt, b.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range b.Items {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO remittance_batch_items (batch_id, order_id, amount)
+				VALUES ($1, $2, $3)`, b.ID, item.OrderID, item.Amount)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return batches, nil
+}
+
+// GetRemittanceBatch retrieves a remittance batch and its orders.
+func (r *postgresRepository) GetRemittanceBatch(ctx context.Context, batchID string) (*RemittanceBatch, error) {
+	b, err := scanRemittanceBatch(r.db.QueryRowContext(ctx, `
+		SELECT `+batchColumns+` FROM remittance_batches WHERE id = $1`, batchID))
+	if err != nil {
+		return nil, err
+	}
+	if b.Items, err = remittanceBatchItems(ctx, r.db, b.ID); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ListRemittanceBatches retrieves a merchant's remittance batches, newest first.
+func (r *postgresRepository) ListRemittanceBatches(ctx context.Context, accountID string) ([]RemittanceBatch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+batchColumns+` FROM remittance_batches
+		WHERE account_id = $1 ORDER BY created_at DESC`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches []RemittanceBatch
+	for rows.Next() {
+		b, err := scanRemittanceBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, *b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range batches {
+		if batches[i].Items, err = remittanceBatchItems(ctx, r.db, batches[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return batches, nil
+}
+
+// UpdateRemittanceBatchStatus moves a batch to a new status. Paying a batch marks
+// its orders as remitted; failing a batch releases its orders for the next cycle.
+func (r *postgresRepository) UpdateRemittanceBatchStatus(ctx context.Context, batchID string, status string, utr string, reason string) (*RemittanceBatch, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	b, err := scanRemittanceBatch(tx.QueryRowContext(ctx, `
+		SELECT `+batchColumns+` FROM remittance_batches WHERE id = $1 FOR UPDATE`, batchID))
+	if err != nil {
+		return nil, err
+	}
+	if !canTransitionBatch(b.Status, status) {
+		err = fmt.Errorf("%w: %s to %s", ErrInvalidBatchTransition, b.Status, status)
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE remittance_batches
+		SET status = $2, utr = NULLIF($3, ''), failure_reason = NULLIF($4, ''), updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`, batchID, status, utr, reason).Scan(&b.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	b.Status, b.UTR, b.FailureReason = status, utr, reason
+
+	if status == BatchStatusPaid {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE remittance_eligibility SET remittance_processed = TRUE, updated_at = NOW()
+			WHERE order_id IN (SELECT order_id FROM remittance_batch_items WHERE batch_id = $1)`, batchID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if b.Items, err = remittanceBatchItems(ctx, tx, batchID); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+const batchColumns = `id, account_id, status, total_amount, fee_amount, COALESCE(utr, ''), COALESCE(failure_reason, ''), created_at, updated_at`
+
+// SettleBatchToWallet credits an approved batch's net amount to the merchant's
+// wallet and marks the batch paid.
+func (r *postgresRepository) SettleBatchToWallet(ctx context.Context, batchID string) (*RemittanceBatch, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	b, err := scanRemittanceBatch(tx.QueryRowContext(ctx, `
+		SELECT `+batchColumns+` FROM remittance_batches WHERE id = $1 FOR UPDATE`, batchID))
+	if err != nil {
+		return nil, err
+	}
+	if !canTransitionBatch(b.Status, BatchStatusPaid) {
+		err = fmt.Errorf("%w: %s to %s", ErrInvalidBatchTransition, b.Status, BatchStatusPaid)
+		return nil, err
+	}
+
+	if _, err = creditWallet(ctx, tx, b.AccountID, BaseCurrency, b.NetAmount()); err != nil {
+		return nil, err
+	}
+	err = recordTransaction(ctx, tx, b.AccountID, BaseCurrency, TransactionRemittance, b.NetAmount(), "", b.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE remittance_eligibility SET remittance_processed = TRUE, updated_at = NOW()
+		WHERE order_id IN (SELECT order_id FROM remittance_batch_items WHERE batch_id = $1)`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE remittance_batches SET status = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`, batchID, BatchStatusPaid).Scan(&b.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	b.Status = BatchStatusPaid
+
+	if b.Items, err = remittanceBatchItems(ctx, tx, batchID); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GetRemittanceSettings retrieves a merchant's remittance settings. Merchants
+// without settings are remitted to their wallet.
+func (r *postgresRepository) GetRemittanceSettings(ctx context.Context, accountID string) (*RemittanceSettings, error) {
+	settings := RemittanceSettings{AccountID: accountID, Destination: DestinationWallet}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT destination, bank_account_number, ifsc, beneficiary_name, bank_verified, updated_at
+		FROM remittance_settings WHERE account_id = $1`, accountID).Scan(
+		&settings.Destination, &settings.Bank.AccountNumber, &settings.Bank.IFSC, &settings.Bank.BeneficiaryName,
+		&settings.BankVerified, &settings.UpdatedAt,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// PutRemittanceSettings creates or replaces a merchant's remittance settings.
+func (r *postgresRepository) PutRemittanceSettings(ctx context.Context, settings RemittanceSettings) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO remittance_settings (account_id, destination, bank_account_number, ifsc, beneficiary_name, bank_verified, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (account_id)
+		DO UPDATE SET destination = $2, bank_account_number = $3, ifsc = $4, beneficiary_name = $5,
+			bank_verified = $6, updated_at = NOW()`,
+		settings.AccountID, settings.Destination, settings.Bank.AccountNumber, settings.Bank.IFSC,
+		settings.Bank.BeneficiaryName, settings.BankVerified,
+	)
+	return err
+}
+
+const payoutColumns = `id, batch_id, account_id, amount, bank_account_number, ifsc, beneficiary_name, status,
+	COALESCE(provider_ref, ''), COALESCE(utr, ''), attempts, COALESCE(last_error, ''), next_attempt_at, created_at, updated_at`
+
+func scanPayout(row rowScanner) (*Payout, error) {
+	var p Payout
+	err := row.Scan(&p.ID, &p.BatchID, &p.AccountID, &p.Amount, &p.Bank.AccountNumber, &p.Bank.IFSC, &p.Bank.BeneficiaryName,
+		&p.Status, &p.ProviderRef, &p.UTR, &p.Attempts, &p.LastError, &p.NextAttemptAt, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreatePayout stores a new payout.
+func (r *postgresRepository) CreatePayout(ctx context.Context, payout Payout) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO payouts (id, batch_id, account_id, amount, bank_account_number, ifsc, beneficiary_name,
+			status, attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		payout.ID, payout.BatchID, payout.AccountID, payout.Amount, payout.Bank.AccountNumber, payout.Bank.IFSC,
+		payout.Bank.BeneficiaryName, payout.Status, payout.Attempts, payout.NextAttemptAt, payout.CreatedAt, payout.UpdatedAt,
+	)
+	return err
+}
+
+// UpdatePayout saves the progress of a payout.
+func (r *postgresRepository) UpdatePayout(ctx context.Context, payout Payout) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE payouts
+		SET status = $2, provider_ref = NULLIF($3, ''), utr = NULLIF($4, ''), attempts = $5,
+			last_error = NULLIF($6, ''), next_attempt_at = $7, updated_at = NOW()
+		WHERE id = $1`,
+		payout.ID, payout.Status, payout.ProviderRef, payout.UTR, payout.Attempts, payout.LastError, payout.NextAttemptAt,
+	)
+	return err
+}
+
+// ListDuePayouts retrieves queued and in-flight payouts whose next attempt is due.
+func (r *postgresRepository) ListDuePayouts(ctx context.Context, now time.Time, limit int) ([]Payout, error) {
+	return r.listPayouts(ctx, `
+		SELECT `+payoutColumns+` FROM payouts
+		WHERE status IN ('queued', 'processing') AND next_attempt_at <= $1
+		ORDER BY next_attempt_at LIMIT $2`, now, limit)
+}
+
+// ListPayouts retrieves a merchant's payouts, newest first.
+func (r *postgresRepository) ListPayouts(ctx context.Context, accountID string) ([]Payout, error) {
+	return r.listPayouts(ctx, `
+		SELECT `+payoutColumns+` FROM payouts
+		WHERE account_id = $1 ORDER BY created_at DESC`, accountID)
+}
+
+// BatchHasPayout reports whether a bank payout was created for the batch.
+func (r *postgresRepository) BatchHasPayout(ctx context.Context, batchID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM payouts WHERE batch_id = $1)`, batchID).Scan(&exists)
+	return exists, err
+}
+
+func (r *postgresRepository) listPayouts(ctx context.Context, query string, args ...any) ([]Payout, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payouts []Payout
+	for rows.Next() {
+		p, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, *p)
+	}
+	return payouts, rows.Err()
+}
+
+// ImportCourierStatement stores a courier statement, matching each line to a COD
+// order by AWB. Matched orders are marked as collected by the courier, which
+// makes the amount received payable to the merchant.
+func (r *postgresRepository) ImportCourierStatement(ctx context.Context, statement CourierStatement) (_ *CourierStatement, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO courier_statements (id, courier, reference, total_amount, imported_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (courier, reference) DO NOTHING`,
+		statement.ID, statement.Courier, statement.Reference, statement.TotalAmount, statement.ImportedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if inserted == 0 {
+		err = ErrStatementExists
+		return nil, err
+	}
+
+	for i := range statement.Lines {
+		line := &statement.Lines[i]
+
+		var collected bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT order_id, cod_amount, cod_collected_by_courier FROM remittance_eligibility
+			WHERE awb = $1 AND is_cod = TRUE
+			FOR UPDATE`, line.AWB).Scan(&line.OrderID, &line.ExpectedAmount, &collected)
+		switch {
+		case err == sql.ErrNoRows:
+			line.Status = LineStatusMissingAWB
+		case err != nil:
+			return nil, err
+		case collected:
+			line.Status = LineStatusDuplicate
+		default:
+			line.Status = reconcileLine(line.Amount, line.ExpectedAmount)
+			_, err = tx.ExecContext(ctx, `
+				UPDATE remittance_eligibility
+				SET cod_collected_by_courier = TRUE, courier_remitted_amount = $2, updated_at = NOW()
+				WHERE order_id = $1`, line.OrderID, line.Amount)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO courier_statement_lines (statement_id, line_no, awb, amount, order_id, expected_amount, status)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)`,
+			statement.ID, line.LineNo, line.AWB, line.Amount, line.OrderID, line.ExpectedAmount, line.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &statement, nil
+}
+
+const creditLineColumns = `account_id, credit_limit, cycle_days, terms_days, cycle_start, frozen, updated_at`
+
+func scanCreditLine(row rowScanner) (*CreditLine, error) {
+	var c CreditLine
+	err := row.Scan(&c.AccountID, &c.Limit, &c.CycleDays, &c.TermsDays, &c.CycleStart, &c.Frozen, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoCreditLine
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetCreditLine retrieves a merchant's credit line.
+func (r *postgresRepository) GetCreditLine(ctx context.Context, accountID string) (*CreditLine, error) {
+	return scanCreditLine(r.db.QueryRowContext(ctx, `
+		SELECT `+creditLineColumns+` FROM credit_lines WHERE account_id = $1`, accountID))
+}
+
+// PutCreditLine creates or updates a merchant's credit line. Updating keeps the
+// current billing cycle and freeze state.
+func (r *postgresRepository) PutCreditLine(ctx context.Context, line CreditLine) (*CreditLine, error) {
+	return scanCreditLine(r.db.QueryRowContext(ctx, `
+		INSERT INTO credit_lines (account_id, credit_limit, cycle_days, terms_days, cycle_start, frozen, updated_at)
+		VALUES ($1, $2, $3, $4, $5, FALSE, NOW())
+		ON CONFLICT (account_id)
+		DO UPDATE SET credit_limit = $2, cycle_days = $3, terms_days = $4, updated_at = NOW()
+		RETURNING `+creditLineColumns,
+		line.AccountID, line.Limit, line.CycleDays, line.TermsDays, line.CycleStart,
+	))
+}
+
+// ListCreditLinesDue retrieves the credit lines whose billing cycle has ended.
+func (r *postgresRepository) ListCreditLinesDue(ctx context.Context, now time.Time) ([]CreditLine, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+creditLineColumns+` FROM credit_lines
+		WHERE cycle_start + cycle_days * INTERVAL '1 day' <= $1`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []CreditLine
+	for rows.Next() {
+		line, err := scanCreditLine(rows)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, *line)
+	}
+	return lines, rows.Err()
+}
+
+// CloseCreditCycle ends the account's billing cycle and bills the credit used
+// that has not already been billed. It returns a nil bill when nothing is owed
+// or the cycle has not ended.
+func (r *postgresRepository) CloseCreditCycle(ctx context.Context, accountID string, now time.Time) (*CreditBill, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	line, err := scanCreditLine(tx.QueryRowContext(ctx, `
+		SELECT `+creditLineColumns+` FROM credit_lines WHERE account_id = $1 FOR UPDATE`, accountID))
+	if err != nil {
+		return nil, err
+	}
+	if line.CycleEnd().After(now) {
+		return nil, nil
+	}
+
+	// Skip over any cycles missed while billing was not running.
+	periodEnd := line.CycleEnd()
+	for !periodEnd.AddDate(0, 0, line.CycleDays).After(now) {
+		periodEnd = periodEnd.AddDate(0, 0, line.CycleDays)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE credit_lines SET cycle_start = $2, updated_at = NOW() WHERE account_id = $1`,
+		accountID, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	// The amount owed is the negative balance less what earlier bills still cover.
+	var owed float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT GREATEST(0, -COALESCE((SELECT balance FROM wallets WHERE account_id = $1 AND currency = 'INR'), 0))
+			- COALESCE((SELECT SUM(amount - paid_amount) FROM credit_bills
+				WHERE account_id = $1 AND status IN ('open', 'overdue')), 0)`,
+		accountID).Scan(&owed)
+	if err != nil {
+		return nil, err
+	}
+	owed = math.Round(owed*100) / 100
+	if owed <= 0 {
+		return nil, nil
+	}
+
+	bill := CreditBill{
+		ID:          uuid.New().String(),
+		AccountID:   accountID,
+		Amount:      owed,
+		PeriodStart: line.CycleStart,
+		PeriodEnd:   periodEnd,
+		DueDate:     periodEnd.AddDate(0, 0, line.TermsDays),
+		Status:      BillStatusOpen,
+		CreatedAt:   now,
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO credit_bills (id, account_id, amount, paid_amount, period_start, period_end, due_date,
+			status, reminders_sent, created_at)
+		VALUES ($1, $2, $3, 0, $4, $5, $6, $7, 0, $8)`,
+		bill.ID, bill.AccountID, bill.Amount, bill.PeriodStart, bill.PeriodEnd, bill.DueDate, bill.Status, bill.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &bill, nil
+}
+
+const creditBillColumns = `id, account_id, amount, paid_amount, period_start, period_end, due_date,
+	status, reminders_sent, created_at`
+
+// ListCreditBills retrieves a merchant's credit bills, newest first.
+func (r *postgresRepository) ListCreditBills(ctx context.Context, accountID string) ([]CreditBill, error) {
+	return r.listCreditBills(ctx, `
+		SELECT `+creditBillColumns+` FROM credit_bills
+		WHERE account_id = $1 ORDER BY period_end DESC`, accountID)
+}
+
+// ListUnpaidBills retrieves every open and overdue credit bill.
+func (r *postgresRepository) ListUnpaidBills(ctx context.Context) ([]CreditBill, error) {
+	return r.listCreditBills(ctx, `
+		SELECT `+creditBillColumns+` FROM credit_bills
+		WHERE status IN ('open', 'overdue') ORDER BY due_date`)
+}
+
+func (r *postgresRepository) listCreditBills(ctx context.Context, query string, args ...any) ([]CreditBill, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bills []CreditBill
+	for rows.Next() {
+		var b CreditBill
+		err := rows.Scan(&b.ID, &b.AccountID, &b.Amount, &b.PaidAmount, &b.PeriodStart, &b.PeriodEnd, &b.DueDate,
+			&b.Status, &b.RemindersSent, &b.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		bills = append(bills, b)
+	}
+	return bills, rows.Err()
+}
+
+// MarkBillReminded records how many due-date reminders were sent for a bill.
+func (r *postgresRepository) MarkBillReminded(ctx context.Context, billID string, remindersSent int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE credit_bills SET reminders_sent = $2 WHERE id = $1`, billID, remindersSent)
+	return err
+}
+
+// MarkBillOverdue marks an open bill overdue and freezes the account's credit line.
+func (r *postgresRepository) MarkBillOverdue(ctx context.Context, billID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var accountID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE credit_bills SET status = 'overdue'
+		WHERE id = $1 AND status = 'open'
+		RETURNING account_id`, billID).Scan(&accountID)
+	if err == sql.ErrNoRows {
+		return nil // Paid or already overdue.
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE credit_lines SET frozen = TRUE, updated_at = NOW() WHERE account_id = $1`, accountID)
+	return err
+}
+
+const balanceAlertColumns = `account_id, threshold, auto_recharge_amount, COALESCE(mandate_id, ''), triggered, updated_at`
+
+func scanBalanceAlert(row rowScanner) (*BalanceAlert, error) {
+	var a BalanceAlert
+	err := row.Scan(&a.AccountID, &a.Threshold, &a.AutoRechargeAmount, &a.MandateID, &a.Triggered, &a.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoBalanceAlert
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetBalanceAlert retrieves a merchant's low-balance alert.
+func (r *postgresRepository) GetBalanceAlert(ctx context.Context, accountID string) (*BalanceAlert, error) {
+	return scanBalanceAlert(r.db.QueryRowContext(ctx, `
+		SELECT `+balanceAlertColumns+` FROM balance_alerts WHERE account_id = $1`, accountID))
+}
+
+// PutBalanceAlert creates or replaces a merchant's low-balance alert. Changing
+// the alert re-arms it.
+func (r *postgresRepository) PutBalanceAlert(ctx context.Context, alert BalanceAlert) (*BalanceAlert, error) {
+	return scanBalanceAlert(r.db.QueryRowContext(ctx, `
+		INSERT INTO balance_alerts (account_id, threshold, auto_recharge_amount, mandate_id, triggered, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), FALSE, NOW())
+		ON CONFLICT (account_id)
+		DO UPDATE SET threshold = $2, auto_recharge_amount = $3, mandate_id = NULLIF($4, ''),
+			triggered = FALSE, updated_at = NOW()
+		RETURNING `+balanceAlertColumns,
+		alert.AccountID, alert.Threshold, alert.AutoRechargeAmount, alert.MandateID,
+	))
+}
+
+// SetBalanceAlertTriggered sets whether the alert has fired for the current dip
+// below the threshold. It reports false if the flag already had that value.
+func (r *postgresRepository) SetBalanceAlertTriggered(ctx context.Context, accountID string, triggered bool) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE balance_alerts SET triggered = $2, updated_at = NOW()
+		WHERE account_id = $1 AND triggered <> $2`, accountID, triggered)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+const subscriptionColumns = `account_id, plan_code, period_start, period_end, status, updated_at`
+
+func scanSubscription(row rowScanner) (*Subscription, error) {
+	var sub Subscription
+	err := row.Scan(&sub.AccountID, &sub.PlanCode, &sub.PeriodStart, &sub.PeriodEnd, &sub.Status, &sub.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// GetSubscription retrieves a merchant's subscription, or nil if they have none.
+func (r *postgresRepository) GetSubscription(ctx context.Context, accountID string) (*Subscription, error) {
+	sub, err := scanSubscription(r.db.QueryRowContext(ctx, `
+		SELECT `+subscriptionColumns+` FROM subscriptions WHERE account_id = $1`, accountID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// ChangeSubscription moves a merchant to sub's plan and settles the prorated
+// charge against their wallet; a negative charge is refunded. It fails with
+// ErrSubscriptionStale if the merchant is no longer on expectedPlan.
+func (r *postgresRepository) ChangeSubscription(ctx context.Context, sub Subscription, expectedPlan string, charge float64) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var current string
+	err = tx.QueryRowContext(ctx, `
+		SELECT plan_code FROM subscriptions WHERE account_id = $1 FOR UPDATE`, sub.AccountID).Scan(&current)
+	if err == sql.ErrNoRows {
+		err = nil // The merchant has no subscription yet.
+	}
+	if err != nil {
+		return err
+	}
+	if current != expectedPlan {
+		err = ErrSubscriptionStale
+		return err
+	}
+
+	switch {
+	case charge > 0:
+		if _, err = debitWallet(ctx, tx, sub.AccountID, BaseCurrency, charge); err != nil {
+			return err
+		}
+		err = recordTransaction(ctx, tx, sub.AccountID, BaseCurrency, TransactionSubscription, charge, "", sub.PlanCode)
+	case charge < 0:
+		if _, err = creditWallet(ctx, tx, sub.AccountID, BaseCurrency, -charge); err != nil {
+			return err
+		}
+		err = recordTransaction(ctx, tx, sub.AccountID, BaseCurrency, TransactionSubscriptionRefund, -charge, "", expectedPlan)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO subscriptions (account_id, plan_code, period_start, period_end, status, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (account_id)
+		DO UPDATE SET plan_code = $2, period_start = $3, period_end = $4, status = $5, updated_at = NOW()`,
+		sub.AccountID, sub.PlanCode, sub.PeriodStart, sub.PeriodEnd, sub.Status,
+	)
+	return err
+}
+
+// ListSubscriptionsDue retrieves the subscriptions whose billing period has ended.
+func (r *postgresRepository) ListSubscriptionsDue(ctx context.Context, now time.Time) ([]Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+subscriptionColumns+` FROM subscriptions WHERE period_end <= $1`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+// RenewSubscription charges the monthly fee and starts the next period.
+func (r *postgresRepository) RenewSubscription(ctx context.Context, accountID string, periodStart, periodEnd time.Time, fee float64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var planCode string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE subscriptions SET period_start = $2, period_end = $3, status = 'active', updated_at = NOW()
+		WHERE account_id = $1 AND period_end < $3
+		RETURNING plan_code`, accountID, periodStart, periodEnd).Scan(&planCode)
+	if err == sql.ErrNoRows {
+		return nil // Already renewed.
+	}
+	if err != nil || fee <= 0 {
+		return err
+	}
+
+	if _, err = debitWallet(ctx, tx, accountID, BaseCurrency, fee); err != nil {
+		return err
+	}
+	err = recordTransaction(ctx, tx, accountID, BaseCurrency, TransactionSubscription, fee, "", planCode)
+	return err
+}
+
+// MarkSubscriptionPastDue flags a subscription whose renewal could not be charged.
+func (r *postgresRepository) MarkSubscriptionPastDue(ctx context.Context, accountID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE subscriptions SET status = 'past_due', updated_at = NOW() WHERE account_id = $1`, accountID)
+	return err
+}
+
+// SetWalletFrozen freezes or unfreezes all of an account's wallets and audits the change.
+func (r *postgresRepository) SetWalletFrozen(ctx context.Context, accountID string, frozen bool, entry AuditEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// The base wallet is created if missing so a freeze applies before first use.
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO wallets (account_id, currency, balance, updated_at)
+		VALUES ($1, 'INR', 0, NOW())
+		ON CONFLICT (account_id, currency) DO NOTHING`, accountID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE wallets SET frozen = $2, updated_at = NOW() WHERE account_id = $1`, accountID, frozen)
+	if err != nil {
+		return err
+	}
+	err = recordAudit(ctx, tx, entry)
+	return err
+}
+
+const adjustmentColumns = `id, account_id, currency, direction, amount, reason, status, requested_by,
+	COALESCE(reviewed_by, ''), COALESCE(review_note, ''), created_at, reviewed_at`
+
+func scanAdjustment(row rowScanner) (*Adjustment, error) {
+	var a Adjustment
+	var reviewedAt sql.NullTime
+	err := row.Scan(&a.ID, &a.AccountID, &a.Currency, &a.Direction, &a.Amount, &a.Reason, &a.Status, &a.RequestedBy,
+		&a.ReviewedBy, &a.ReviewNote, &a.CreatedAt, &reviewedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrAdjustmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		a.ReviewedAt = &reviewedAt.Time
+	}
+	return &a, nil
+}
+
+// CreateAdjustment stores an adjustment, applying it to the wallet unless it is
+// pending approval, and audits the request.
+func (r *postgresRepository) CreateAdjustment(ctx context.Context, adj Adjustment, entry AuditEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO wallet_adjustments (id, account_id, currency, direction, amount, reason, status, requested_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		adj.ID, adj.AccountID, adj.Currency, adj.Direction, adj.Amount, adj.Reason, adj.Status, adj.RequestedBy, adj.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if adj.Status == AdjustmentApplied {
+		if err = applyAdjustment(ctx, tx, &adj); err != nil {
+			return err
+		}
+	}
+	err = recordAudit(ctx, tx, entry)
+	return err
+}
+
+// GetAdjustment retrieves an adjustment.
+func (r *postgresRepository) GetAdjustment(ctx context.Context, adjustmentID string) (*Adjustment, error) {
+	return scanAdjustment(r.db.QueryRowContext(ctx, `
+		SELECT `+adjustmentColumns+` FROM wallet_adjustments WHERE id = $1`, adjustmentID))
+}
+
+// ReviewAdjustment records the review of a pending adjustment, applies it if
+// approved, and audits the review.
+func (r *postgresRepository) ReviewAdjustment(ctx context.Context, adjustmentID string, status string, reviewer string, note string, entry AuditEntry) (*Adjustment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	adj, err := scanAdjustment(tx.QueryRowContext(ctx, `
+		UPDATE wallet_adjustments
+		SET status = $2, reviewed_by = $3, review_note = NULLIF($4, ''), reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING `+adjustmentColumns, adjustmentID, status, reviewer, note))
+	if err == ErrAdjustmentNotFound {
+		err = ErrAdjustmentNotPending
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if adj.Status == AdjustmentApplied {
+		if err = applyAdjustment(ctx, tx, adj); err != nil {
+			return nil, err
+		}
+	}
+	if err = recordAudit(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+	return adj, nil
+}
+
+// ListAdjustments retrieves adjustments with the given status, or all
+// adjustments if status is empty, newest first.
+func (r *postgresRepository) ListAdjustments(ctx context.Context, status string) ([]Adjustment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+adjustmentColumns+` FROM wallet_adjustments
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjustments []Adjustment
+	for rows.Next() {
+		adj, err := scanAdjustment(rows)
+		if err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, *adj)
+	}
+	return adjustments, rows.Err()
+}
+
+// ListAuditEntries retrieves the most recent audit entries, for one account or
+// all accounts if accountID is empty.
+func (r *postgresRepository) ListAuditEntries(ctx context.Context, accountID string, limit int) ([]AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, actor, action, COALESCE(account_id, ''), COALESCE(target_id, ''), details, created_at
+		FROM admin_audit_log
+		WHERE ($1 = '' OR account_id = $1)
+		ORDER BY id DESC LIMIT $2`, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.AccountID, &e.TargetID, &e.Details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// applyAdjustment posts an adjustment to the wallet within tx. Debits bypass the
+// freeze and credit policy, as they are made by ops staff.
+func applyAdjustment(ctx context.Context, tx *sql.Tx, adj *Adjustment) error {
+	if adj.Direction == AdjustmentCredit {
+		if _, err := creditWallet(ctx, tx, adj.AccountID, adj.Currency, adj.Amount); err != nil {
+			return err
+		}
+		return recordTransaction(ctx, tx, adj.AccountID, adj.Currency, TransactionAdjustmentCredit, adj.Amount, "", adj.ID)
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO wallets (account_id, currency, balance, updated_at)
+		VALUES ($1, $2, -$3::NUMERIC, NOW())
+		ON CONFLICT (account_id, currency)
+		DO UPDATE SET balance = wallets.balance - $3, updated_at = NOW()`,
+		adj.AccountID, adj.Currency, adj.Amount,
+	)
+	if err != nil {
+		return err
+	}
+	return recordTransaction(ctx, tx, adj.AccountID, adj.Currency, TransactionAdjustmentDebit, adj.Amount, "", adj.ID)
+}
+
+// recordAudit appends an entry to the admin audit log within tx.
+func recordAudit(ctx context.Context, tx *sql.Tx, entry AuditEntry) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO admin_audit_log (actor, action, account_id, target_id, details)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)`,
+		entry.Actor, entry.Action, entry.AccountID, entry.TargetID, entry.Details,
+	)
+	return err
+}
+
+// creditLine retrieves the account's credit line within tx, or nil if it has none.
+func creditLine(ctx context.Context, tx *sql.Tx, accountID string) (*CreditLine, error) {
+	line, err := scanCreditLine(tx.QueryRowContext(ctx, `
+		SELECT `+creditLineColumns+` FROM credit_lines WHERE account_id = $1`, accountID))
+	if err == ErrNoCreditLine {
+		return nil, nil
+	}
+	return line, err
+}
+
+// debitWallet takes amount out of the account's wallet in currency within tx,
+// provided the credit policy allows it, and returns the new balance. Credit
+// lines only extend the base currency wallet.
+func debitWallet(ctx context.Context, tx *sql.Tx, accountID string, currency string, amount float64) (float64, error) {
+	var balance float64
+	var frozen bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT balance, frozen FROM wallets WHERE account_id = $1 AND currency = $2 FOR UPDATE`,
+		accountID, currency).Scan(&balance, &frozen)
+	if err == sql.ErrNoRows {
+		// New wallets inherit a freeze on the account's other wallets.
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO wallets (account_id, currency, balance, frozen, updated_at)
+			VALUES ($1, $2, 0, EXISTS (SELECT 1 FROM wallets WHERE account_id = $1 AND frozen), NOW())
+			RETURNING frozen`, accountID, currency).Scan(&frozen)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if frozen {
+		return 0, ErrWalletFrozen
+	}
+
+	var line *CreditLine
+	if currency == BaseCurrency {
+		if line, err = creditLine(ctx, tx, accountID); err != nil {
+			return 0, err
+		}
+	}
+	if err := checkDebit(balance, amount, line); err != nil {
+		return 0, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE wallets SET balance = balance - $3, updated_at = NOW()
+		WHERE account_id = $1 AND currency = $2
+		RETURNING balance`,
+		accountID, currency, amount,
+	).Scan(&balance)
+	return balance, err
+}
+
+// payCreditBills applies amount credited to the account's wallet to its unpaid
+// bills, oldest first, and lifts the freeze once no bill is overdue.
+func payCreditBills(ctx context.Context, tx *sql.Tx, accountID string, amount float64) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, amount - paid_amount FROM credit_bills
+		WHERE account_id = $1 AND status IN ('open', 'overdue')
+		ORDER BY due_date
+		FOR UPDATE`, accountID)
+	if err != nil {
+		return err
+	}
+	type unpaid struct {
+		id          string
+		outstanding float64
+	}
+	var bills []unpaid
+	for rows.Next() {
+		var b unpaid
+		if err := rows.Scan(&b.id, &b.outstanding); err != nil {
+			rows.Close()
+			return err
+		}
+		bills = append(bills, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(bills) == 0 {
+		return nil
+	}
+
+	for _, b := range bills {
+		if amount <= 0 {
+			break
+		}
+		paid := math.Min(amount, b.outstanding)
+		amount -= paid
+		_, err := tx.ExecContext(ctx, `
+			UPDATE credit_bills
+			SET paid_amount = paid_amount + $2,
+				status = CASE WHEN paid_amount + $2 >= amount - 0.005 THEN 'paid' ELSE status END
+			WHERE id = $1`, b.id, paid)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE credit_lines SET frozen = FALSE, updated_at = NOW()
+		WHERE account_id = $1 AND frozen
+			AND NOT EXISTS (SELECT 1 FROM credit_bills WHERE account_id = $1 AND status = 'overdue')`,
+		accountID)
+	return err
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func scanRemittanceBatch(row rowScanner) (*RemittanceBatch, error) {
+	var b RemittanceBatch
+	err := row.Scan(&b.ID, &b.AccountID, &b.Status, &b.TotalAmount, &b.Fee, &b.UTR, &b.FailureReason, &b.CreatedAt, &b.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrBatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func remittanceBatchItems(ctx context.Context, q queryer, batchID string) ([]RemittanceDetail, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT order_id, amount FROM remittance_batch_items WHERE batch_id = $1`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []RemittanceDetail
+	for rows.Next() {
+		var item RemittanceDetail
+		if err := rows.Scan(&item.OrderID, &item.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// recordTransaction logs a wallet transaction within tx. It must run after the
+// wallet balance has been updated so the running balance is captured.
+func recordTransaction(ctx context.Context, tx *sql.Tx, accountID string, currency string, txnType string, amount float64, orderID string, reference string) error {
+	return insertTransaction(ctx, tx, accountID, currency, txnType, amount, orderID, reference, nil)
+}
+
+// insertTransaction logs a wallet transaction within tx, along with the
+// conversion that funded it, if any.
+func insertTransaction(ctx context.Context, tx *sql.Tx, accountID string, currency string, txnType string, amount float64, orderID string, reference string, fx *FXConversion) error {
+	var sourceAmount, rate sql.NullFloat64
+	var sourceCurrency sql.NullString
+	if fx != nil {
+		sourceAmount = sql.NullFloat64{Float64: fx.SourceAmount, Valid: true}
+		sourceCurrency = sql.NullString{String: fx.SourceCurrency, Valid: true}
+		rate = sql.NullFloat64{Float64: fx.Rate, Valid: true}
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO transactions (account_id, currency, transaction_type, amount, order_id, reference, balance_after,
+			source_amount, source_currency, fx_rate)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''),
+			(SELECT balance FROM wallets WHERE account_id = $1 AND currency = $2), $7, $8, $9)`,
+		accountID, currency, txnType, amount, orderID, reference, sourceAmount, sourceCurrency, rate,
+	)
+	return err
+}
+
+// creditWallet adds amount to the account's wallet in currency within tx and
+// returns the new balance. Credits to the base currency wallet also pay down the
+// account's unpaid credit bills.
+func creditWallet(ctx context.Context, tx *sql.Tx, accountID string, currency string, amount float64) (float64, error) {
+	var newBalance float64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO wallets (account_id, currency, balance, frozen, updated_at)
+		VALUES ($1, $2, $3, EXISTS (SELECT 1 FROM wallets WHERE account_id = $1 AND frozen), NOW())
+		ON CONFLICT (account_id, currency)
+		DO UPDATE SET balance = wallets.balance + $3, updated_at = NOW()
+		RETURNING balance`,
+		accountID, currency, amount,
+	).Scan(&newBalance)
+	if err != nil {
+		return 0, err
+	}
+	if currency != BaseCurrency {
+		return newBalance, nil
+	}
+	return newBalance, payCreditBills(ctx, tx, accountID, amount)
+}
+
+// subjectTables selects an account's rows from each table holding data about
+// it, for data exports. Invoices and billing profiles are exported by the
+// invoice package.
+var subjectTables = []struct {
+	name  string
+	query string
+}{
+	{"wallets", `SELECT * FROM wallets WHERE account_id = $1 ORDER BY currency`},
+	{"transactions", `SELECT * FROM transactions WHERE account_id = $1 ORDER BY transaction_id`},
+	{"recharge_intents", `SELECT * FROM recharge_intents WHERE account_id = $1 ORDER BY created_at`},
+	{"remittance_eligibility", `SELECT * FROM remittance_eligibility WHERE account_id = $1 ORDER BY last_event_at`},
+	{"remittance_batches", `SELECT * FROM remittance_batches WHERE account_id = $1 ORDER BY created_at`},
+	{"remittance_batch_items", `
+		SELECT i.* FROM remittance_batch_items i
+		JOIN remittance_batches b ON b.id = i.batch_id
+		WHERE b.account_id = $1 ORDER BY i.batch_id, i.order_id`},
+	{"remittance_settings", `SELECT * FROM remittance_settings WHERE account_id = $1`},
+	{"payouts", `SELECT * FROM payouts WHERE account_id = $1 ORDER BY created_at`},
+	{"credit_lines", `SELECT * FROM credit_lines WHERE account_id = $1`},
+	{"credit_bills", `SELECT * FROM credit_bills WHERE account_id = $1 ORDER BY created_at`},
+	{"balance_alerts", `SELECT * FROM balance_alerts WHERE account_id = $1`},
+	{"subscriptions", `SELECT * FROM subscriptions WHERE account_id = $1`},
+	{"wallet_adjustments", `SELECT * FROM wallet_adjustments WHERE account_id = $1 ORDER BY created_at`},
+	{"admin_audit_log", `SELECT * FROM admin_audit_log WHERE account_id = $1 ORDER BY id`},
+}
+
+// ExportAccountTables returns the account's rows in every table holding data
+// about it, by table name. Each row maps column names to values.
+func (r *postgresRepository) ExportAccountTables(ctx context.Context, accountID string) (map[string][]map[string]any, error) {
+	tables := make(map[string][]map[string]any, len(subjectTables))
+	for _, t := range subjectTables {
+		rows, err := r.db.QueryContext(ctx, t.query, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", t.name, err)
+		}
+		tables[t.name], err = scanRowMaps(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", t.name, err)
+		}
+	}
+	return tables, nil
+}
+
+// scanRowMaps reads every row into a map of column names to values, and
+// closes rows.
+func scanRowMaps(rows *sql.Rows) ([]map[string]any, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			// Numeric and text columns arrive as bytes.
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// HasOutstandingFunds reports whether the account still has money in or owed
+// to its wallets: a non-zero balance, unpaid credit bills, unsettled payouts,
+// or delivered COD orders awaiting remittance.
+func (r *postgresRepository) HasOutstandingFunds(ctx context.Context, accountID string) (bool, error) {
+	var outstanding bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM wallets WHERE account_id = $1 AND balance <> 0)
+			OR EXISTS (SELECT 1 FROM credit_bills WHERE account_id = $1 AND status IN ('open', 'overdue'))
+			OR EXISTS (SELECT 1 FROM payouts WHERE account_id = $1 AND status IN ('queued', 'processing'))
+			OR EXISTS (SELECT 1 FROM remittance_eligibility WHERE account_id = $1
+				AND is_cod AND shipment_status = 'delivered' AND NOT remittance_processed)`,
+		accountID,
+	).Scan(&outstanding)
+	return outstanding, err
+}
+
+// EraseAccountData deletes the account's bank details, alerts and subscription,
+// masks the bank accounts on its payouts and freezes its wallets and credit
+// line, recording entry in the audit log. Ledger records are kept.
+func (r *postgresRepository) EraseAccountData(ctx context.Context, accountID string, entry AuditEntry) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	statements := []string{
+		`DELETE FROM remittance_settings WHERE account_id = $1`,
+		`DELETE FROM balance_alerts WHERE account_id = $1`,
+		`DELETE FROM subscriptions WHERE account_id = $1`,
+		`UPDATE payouts SET bank_account_number = 'XXXX' || RIGHT(bank_account_number, 4), beneficiary_name = 'Erased account'
+		WHERE account_id = $1`,
+		`UPDATE wallets SET frozen = TRUE, updated_at = NOW() WHERE account_id = $1`,
+		`UPDATE credit_lines SET frozen = TRUE, updated_at = NOW() WHERE account_id = $1`,
+	}
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt, accountID); err != nil {
+			return fmt.Errorf("failed to erase account data: %w", err)
+		}
+	}
+	return recordAudit(ctx, tx, entry)
 }
 
 func (r *postgresRepository) Close() {
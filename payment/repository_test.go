@@ -0,0 +1,192 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Shridhar2104/logilo/internal/sqltest"
+)
+
+func TestProcessRemittance(t *testing.T) {
+	cutoff := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	errCommit := errors.New("commit failed")
+	errLookup := errors.New("connection reset")
+
+	// remit scripts the statements remitting an eligible order's COD.
+	remit := func(m *sqltest.Mock, orderID string, amount float64) {
+		m.ExpectQuery("SELECT "+receivedCOD+" FROM remittance_eligibility").WithArgs(orderID, "acct", cutoff).WillReturnRows([]string{"cod"}, []any{amount})
+		m.ExpectQuery("INSERT INTO wallets").WithArgs("acct", BaseCurrency, amount).WillReturnRows([]string{"balance"}, []any{amount})
+		m.ExpectQuery("FROM credit_bills").WillReturnRows([]string{"id", "outstanding"})
+		m.ExpectExec("UPDATE remittance_eligibility SET remittance_processed = TRUE").WithArgs(orderID).WillReturnResult(1)
+		m.ExpectExec("INSERT INTO transactions").WillReturnResult(1)
+	}
+	// skip scripts the lookup of an order that is not eligible.
+	skip := func(m *sqltest.Mock, orderID string) {
+		m.ExpectQuery("FROM remittance_eligibility").WithArgs(orderID, "acct", cutoff).WillReturnRows([]string{"cod"})
+	}
+
+	tests := []struct {
+		name    string
+		orders  []string
+		script  func(m *sqltest.Mock)
+		want    []RemittanceDetail
+		wantErr error
+	}{
+		{
+			name:   "last order ineligible",
+			orders: []string{"o1", "o2"},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				remit(m, "o1", 500)
+				skip(m, "o2")
+				m.ExpectCommit()
+			},
+			want: []RemittanceDetail{{OrderID: "o1", Amount: 500, Processed: true}, {OrderID: "o2"}},
+		},
+		{
+			name:   "first order ineligible",
+			orders: []string{"o1", "o2"},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				skip(m, "o1")
+				remit(m, "o2", 250.5)
+				m.ExpectCommit()
+			},
+			want: []RemittanceDetail{{OrderID: "o1"}, {OrderID: "o2", Amount: 250.5, Processed: true}},
+		},
+		{
+			name:   "nothing eligible",
+			orders: []string{"o1"},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				skip(m, "o1")
+				m.ExpectCommit()
+			},
+			want: []RemittanceDetail{{OrderID: "o1"}},
+		},
+		{
+			name:   "commit fails",
+			orders: []string{"o1"},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				remit(m, "o1", 500)
+				m.ExpectCommit().WillReturnError(errCommit)
+			},
+			wantErr: errCommit,
+		},
+		{
+			name:   "lookup fails",
+			orders: []string{"o1", "o2"},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				remit(m, "o1", 500)
+				m.ExpectQuery("FROM remittance_eligibility").WillReturnError(errLookup)
+				m.ExpectRollback()
+			},
+			wantErr: errLookup,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m := sqltest.Open(t)
+			tt.script(m)
+
+			details, err := NewPostgresRepository(db).ProcessRemittance(context.Background(), "acct", tt.orders, cutoff)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessRemittance() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(details, tt.want) {
+				t.Errorf("ProcessRemittance() = %+v, want %+v", details, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportCourierStatement(t *testing.T) {
+	imported := time.Date(2024, time.May, 2, 9, 0, 0, 0, time.UTC)
+	errCommit := errors.New("commit failed")
+
+	tests := []struct {
+		name       string
+		lines      []StatementLine
+		script     func(m *sqltest.Mock)
+		wantStatus []string
+		wantErr    error
+	}{
+		{
+			name:  "collected, short and missing",
+			lines: []StatementLine{{LineNo: 1, AWB: "A1", Amount: 500}, {LineNo: 2, AWB: "A2", Amount: 90}, {LineNo: 3, AWB: "A3", Amount: 40}},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				m.ExpectExec("INSERT INTO courier_statements").WillReturnResult(1)
+				m.ExpectQuery("FROM remittance_eligibility").WithArgs("A1").WillReturnRows([]string{"order_id", "cod_amount", "collected"}, []any{"o1", 500.0, false})
+				m.ExpectExec("SET cod_collected_by_courier = TRUE, courier_remitted_amount = $2").WithArgs("o1", 500.0).WillReturnResult(1)
+				m.ExpectExec("INSERT INTO courier_statement_lines").WillReturnResult(1)
+				m.ExpectQuery("FROM remittance_eligibility").WithArgs("A2").WillReturnRows([]string{"order_id", "cod_amount", "collected"}, []any{"o2", 100.0, false})
+				m.ExpectExec("SET cod_collected_by_courier = TRUE, courier_remitted_amount = $2").WithArgs("o2", 90.0).WillReturnResult(1)
+				m.ExpectExec("INSERT INTO courier_statement_lines").WillReturnResult(1)
+				m.ExpectQuery("FROM remittance_eligibility").WithArgs("A3").WillReturnRows([]string{"order_id", "cod_amount", "collected"})
+				m.ExpectExec("INSERT INTO courier_statement_lines").WillReturnResult(1)
+				m.ExpectCommit()
+			},
+			wantStatus: []string{LineStatusMatched, LineStatusShortPayment, LineStatusMissingAWB},
+		},
+		{
+			name:  "already reconciled",
+			lines: []StatementLine{{LineNo: 1, AWB: "A1", Amount: 500}},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				m.ExpectExec("INSERT INTO courier_statements").WillReturnResult(1)
+				m.ExpectQuery("FROM remittance_eligibility").WithArgs("A1").WillReturnRows([]string{"order_id", "cod_amount", "collected"}, []any{"o1", 500.0, true})
+				m.ExpectExec("INSERT INTO courier_statement_lines").WillReturnResult(1)
+				m.ExpectCommit()
+			},
+			wantStatus: []string{LineStatusDuplicate},
+		},
+		{
+			name:  "statement imported before",
+			lines: []StatementLine{{LineNo: 1, AWB: "A1", Amount: 500}},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				m.ExpectExec("INSERT INTO courier_statements").WillReturnResult(0)
+				m.ExpectRollback()
+			},
+			wantErr: ErrStatementExists,
+		},
+		{
+			name:  "commit fails",
+			lines: []StatementLine{{LineNo: 1, AWB: "A3", Amount: 40}},
+			script: func(m *sqltest.Mock) {
+				m.ExpectBegin()
+				m.ExpectExec("INSERT INTO courier_statements").WillReturnResult(1)
+				m.ExpectQuery("FROM remittance_eligibility").WithArgs("A3").WillReturnRows([]string{"order_id", "cod_amount", "collected"})
+				m.ExpectExec("INSERT INTO courier_statement_lines").WillReturnResult(1)
+				m.ExpectCommit().WillReturnError(errCommit)
+			},
+			wantErr: errCommit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m := sqltest.Open(t)
+			tt.script(m)
+
+			statement := CourierStatement{ID: "st1", Courier: "delhivery", Reference: "R1", Lines: tt.lines, ImportedAt: imported}
+			got, err := NewPostgresRepository(db).ImportCourierStatement(context.Background(), statement)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImportCourierStatement() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i, line := range got.Lines {
+				if line.Status != tt.wantStatus[i] {
+					t.Errorf("line %d status = %s, want %s", line.LineNo, line.Status, tt.wantStatus[i])
+				}
+			}
+		})
+	}
+}
@@ -2,9 +2,15 @@ package payment
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
+	"time"
 
+	"github.com/Shridhar2104/logilo/account"
+	"github.com/Shridhar2104/logilo/datasubject"
+	"github.com/Shridhar2104/logilo/payment/invoice"
 	"github.com/Shridhar2104/logilo/payment/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -12,20 +18,25 @@ import (
 
 type grpcServer struct {
 	pb.UnimplementedPaymentServiceServer
-	service Service
+	service  Service
+	admin    AdminService
+	invoices invoice.Service
 }
 
-// NewGRPCServer creates and starts a new gRPC server.
-func NewGRPCServer(service Service, port int) error {
+// NewGRPCServer creates and starts a new gRPC server with the given options.
+func NewGRPCServer(service Service, admin AdminService, invoices invoice.Service, port int, opts ...grpc.ServerOption) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
 
-	server := grpc.NewServer()
+	server := grpc.NewServer(opts...)
 	pb.RegisterPaymentServiceServer(server, &grpcServer{
-		service: service,
+		service:  service,
+		admin:    admin,
+		invoices: invoices,
 	})
+	datasubject.Register(server, datasubject.Combine(service, invoices))
 	reflection.Register(server)
 	fmt.Printf("gRPC server running on port %d\n", port)
 	return server.Serve(lis)
@@ -33,25 +44,31 @@ func NewGRPCServer(service Service, port int) error {
 
 // RechargeWallet handles wallet recharge requests.
 func (s *grpcServer) RechargeWallet(ctx context.Context, req *pb.RechargeRequest) (*pb.RechargeResponse, error) {
-	newBalance, err := s.service.RechargeWallet(ctx, req.UserId, req.Amount)
+	newBalance, err := s.service.RechargeWallet(ctx, req.UserId, req.Amount, req.Currency, req.WalletCurrency)
 	if err != nil {
 		return nil, err
 	}
 
+	currency := req.WalletCurrency
+	if currency == "" {
+		currency = req.Currency
+	}
 	return &pb.RechargeResponse{
 		NewBalance: newBalance,
+		Currency:   normalizeCurrency(currency),
 	}, nil
 }
 
 // DeductBalance handles wallet balance deduction for an order.
 func (s *grpcServer) DeductBalance(ctx context.Context, req *pb.DeductionRequest) (*pb.DeductionResponse, error) {
-	newBalance, err := s.service.DeductBalance(ctx, req.UserId, req.Amount, req.OrderId)
+	newBalance, err := s.service.DeductBalance(ctx, req.UserId, req.Amount, req.Currency, req.OrderId)
 	if err != nil {
 		return nil, err
 	}
 
 	return &pb.DeductionResponse{
 		NewBalance: newBalance,
+		Currency:   normalizeCurrency(req.Currency),
 	}, nil
 }
 
@@ -77,27 +94,718 @@ func (s *grpcServer) ProcessRemittance(ctx context.Context, req *pb.RemittanceRe
 	}, nil
 }
 
-// GetWalletDetails retrieves wallet balance and transaction history.
+// GetWalletDetails retrieves wallet balance and a page of transaction history.
 func (s *grpcServer) GetWalletDetails(ctx context.Context, req *pb.WalletDetailsRequest) (*pb.WalletDetailsResponse, error) {
-	balance, transactions, err := s.service.GetWalletDetails(ctx, req.UserId)
+	filter := TransactionFilter{
+		Currency: req.Currency,
+		Type:    req.TransactionType,
+		OrderID: req.OrderId,
+		Cursor:  req.Cursor,
+		Limit:   int(req.Limit),
+	}
+	var err error
+	if req.From != "" {
+		if filter.From, err = time.Parse(time.RFC3339, req.From); err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if req.To != "" {
+		if filter.To, err = time.Parse(time.RFC3339, req.To); err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+
+	balances, transactions, nextCursor, err := s.service.GetWalletDetails(ctx, req.UserId, filter)
 	if err != nil {
 		return nil, err
 	}
 
+	var balance float64
+	var balanceItems []*pb.WalletBalance
+	for _, b := range balances {
+		if b.Currency == normalizeCurrency(req.Currency) {
+			balance = b.Balance
+		}
+		balanceItems = append(balanceItems, &pb.WalletBalance{
+			Currency: b.Currency,
+			Balance:  b.Balance,
+		})
+	}
+
 	// Map the transactions to the gRPC response format.
 	var transactionItems []*pb.Transaction
 	for _, t := range transactions {
-		transactionItems = append(transactionItems, &pb.Transaction{
+		item := &pb.Transaction{
 			TransactionId:   t.TransactionID,
 			TransactionType: t.TransactionType,
 			Amount:          t.Amount,
 			OrderId:         t.OrderID.String,
-		})
+			Timestamp:       t.Timestamp.Format(time.RFC3339),
+			BalanceAfter:    t.BalanceAfter,
+			Reference:       t.Reference.String,
+			Currency:        t.Currency,
+		}
+		if t.FX != nil {
+			item.SourceAmount = t.FX.SourceAmount
+			item.SourceCurrency = t.FX.SourceCurrency
+			item.FxRate = t.FX.Rate
+		}
+		transactionItems = append(transactionItems, item)
 	}
 
 	return &pb.WalletDetailsResponse{
 		Balance:      balance,
 		TransactionHistory: transactionItems,
+		NextCursor:         nextCursor,
+		Balances:           balanceItems,
+	}, nil
+}
+
+// CreateRechargeIntent starts a gateway-backed wallet recharge.
+func (s *grpcServer) CreateRechargeIntent(ctx context.Context, req *pb.CreateRechargeIntentRequest) (*pb.CreateRechargeIntentResponse, error) {
+	intent, err := s.service.CreateRechargeIntent(ctx, req.UserId, req.Amount, req.Currency, req.WalletCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateRechargeIntentResponse{
+		Intent: toProtoRechargeIntent(intent),
+	}, nil
+}
+
+// HandleGatewayWebhook applies a gateway webhook forwarded over gRPC.
+func (s *grpcServer) HandleGatewayWebhook(ctx context.Context, req *pb.GatewayWebhookRequest) (*pb.GatewayWebhookResponse, error) {
+	intent, err := s.service.HandleGatewayWebhook(ctx, req.Payload, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GatewayWebhookResponse{
+		Intent: toProtoRechargeIntent(intent),
+	}, nil
+}
+
+func toProtoRechargeIntent(intent *RechargeIntent) *pb.RechargeIntent {
+	return &pb.RechargeIntent{
+		IntentId:       intent.ID,
+		UserId:         intent.AccountID,
+		Amount:         intent.Amount,
+		Status:         intent.Status,
+		CheckoutRef:    intent.CheckoutRef,
+		CreatedAt:      intent.CreatedAt.Format(time.RFC3339),
+		Currency:       intent.Currency,
+		WalletCurrency: intent.WalletCurrency,
+		CreditAmount:   intent.CreditAmount,
+		FxRate:         intent.FXRate,
+	}
+}
+
+// CreateRemittanceBatches batches payable COD orders per merchant.
+func (s *grpcServer) CreateRemittanceBatches(ctx context.Context, req *pb.CreateRemittanceBatchesRequest) (*pb.CreateRemittanceBatchesResponse, error) {
+	asOf := time.Now()
+	if req.AsOf != "" {
+		t, err := time.Parse(time.RFC3339, req.AsOf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid as_of: %w", err)
+		}
+		asOf = t
+	}
+
+	batches, err := s.service.CreateRemittanceBatches(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateRemittanceBatchesResponse{
+		Batches: toProtoRemittanceBatches(batches),
+	}, nil
+}
+
+// ListRemittanceBatches lists a merchant's remittance batches.
+func (s *grpcServer) ListRemittanceBatches(ctx context.Context, req *pb.ListRemittanceBatchesRequest) (*pb.ListRemittanceBatchesResponse, error) {
+	batches, err := s.service.ListRemittanceBatches(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListRemittanceBatchesResponse{
+		Batches: toProtoRemittanceBatches(batches),
+	}, nil
+}
+
+// UpdateRemittanceBatch moves a remittance batch to a new status.
+func (s *grpcServer) UpdateRemittanceBatch(ctx context.Context, req *pb.UpdateRemittanceBatchRequest) (*pb.UpdateRemittanceBatchResponse, error) {
+	batch, err := s.service.UpdateRemittanceBatchStatus(ctx, req.BatchId, req.Status, req.Utr, req.FailureReason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateRemittanceBatchResponse{
+		Batch: toProtoRemittanceBatch(batch),
+	}, nil
+}
+
+func toProtoRemittanceBatch(b *RemittanceBatch) *pb.RemittanceBatch {
+	var items []*pb.RemittanceDetail
+	for _, item := range b.Items {
+		items = append(items, &pb.RemittanceDetail{
+			OrderId:   item.OrderID,
+			Amount:    item.Amount,
+			Processed: b.Status == BatchStatusPaid,
+		})
+	}
+
+	return &pb.RemittanceBatch{
+		BatchId:       b.ID,
+		UserId:        b.AccountID,
+		Status:        b.Status,
+		TotalAmount:   b.TotalAmount,
+		Items:         items,
+		Utr:           b.UTR,
+		FailureReason: b.FailureReason,
+		CreatedAt:     b.CreatedAt.Format(time.RFC3339),
+		Fee:           b.Fee,
+	}
+}
+
+func toProtoRemittanceBatches(batches []RemittanceBatch) []*pb.RemittanceBatch {
+	var res []*pb.RemittanceBatch
+	for i := range batches {
+		res = append(res, toProtoRemittanceBatch(&batches[i]))
+	}
+	return res
+}
+
+// RequestEarlyRemittance settles a merchant's COD ahead of the cycle.
+func (s *grpcServer) RequestEarlyRemittance(ctx context.Context, req *pb.EarlyRemittanceRequest) (*pb.EarlyRemittanceResponse, error) {
+	batch, err := s.service.RequestEarlyRemittance(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.EarlyRemittanceResponse{}
+	if batch != nil {
+		res.Batch = toProtoRemittanceBatch(batch)
+	}
+	return res, nil
+}
+
+// GetRemittanceSettings retrieves a merchant's remittance destination.
+func (s *grpcServer) GetRemittanceSettings(ctx context.Context, req *pb.GetRemittanceSettingsRequest) (*pb.RemittanceSettingsResponse, error) {
+	settings, err := s.service.GetRemittanceSettings(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoRemittanceSettings(settings), nil
+}
+
+// UpdateRemittanceSettings sets a merchant's remittance destination.
+func (s *grpcServer) UpdateRemittanceSettings(ctx context.Context, req *pb.UpdateRemittanceSettingsRequest) (*pb.RemittanceSettingsResponse, error) {
+	var bank *BankAccount
+	if req.Bank != nil {
+		bank = &BankAccount{
+			AccountNumber:   req.Bank.AccountNumber,
+			IFSC:            req.Bank.Ifsc,
+			BeneficiaryName: req.Bank.BeneficiaryName,
+		}
+	}
+
+	settings, err := s.service.UpdateRemittanceSettings(ctx, req.UserId, req.Destination, bank)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoRemittanceSettings(settings), nil
+}
+
+// ListPayouts lists a merchant's bank payouts.
+func (s *grpcServer) ListPayouts(ctx context.Context, req *pb.ListPayoutsRequest) (*pb.ListPayoutsResponse, error) {
+	payouts, err := s.service.ListPayouts(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*pb.Payout
+	for _, p := range payouts {
+		items = append(items, &pb.Payout{
+			PayoutId:  p.ID,
+			BatchId:   p.BatchID,
+			Amount:    p.Amount,
+			Status:    p.Status,
+			Utr:       p.UTR,
+			Attempts:  int32(p.Attempts),
+			LastError: p.LastError,
+			CreatedAt: p.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &pb.ListPayoutsResponse{
+		Payouts: items,
+	}, nil
+}
 
+func toProtoRemittanceSettings(settings *RemittanceSettings) *pb.RemittanceSettingsResponse {
+	return &pb.RemittanceSettingsResponse{
+		Destination: settings.Destination,
+		Bank: &pb.BankAccount{
+			AccountNumber:   settings.Bank.AccountNumber,
+			Ifsc:            settings.Bank.IFSC,
+			BeneficiaryName: settings.Bank.BeneficiaryName,
+		},
+		BankVerified: settings.BankVerified,
+	}
+}
+
+// ImportCourierStatement reconciles a courier's COD statement.
+func (s *grpcServer) ImportCourierStatement(ctx context.Context, req *pb.ImportCourierStatementRequest) (*pb.ImportCourierStatementResponse, error) {
+	statement, err := s.service.ImportCourierStatement(ctx, req.Courier, req.Reference, req.CsvData)
+	if err != nil {
+		return nil, err
+	}
+
+	flagged := statement.Flagged()
+	var lines []*pb.StatementLine
+	for _, l := range flagged {
+		lines = append(lines, &pb.StatementLine{
+			LineNo:         int32(l.LineNo),
+			Awb:            l.AWB,
+			Amount:         l.Amount,
+			OrderId:        l.OrderID,
+			ExpectedAmount: l.ExpectedAmount,
+			Status:         l.Status,
+		})
+	}
+
+	return &pb.ImportCourierStatementResponse{
+		StatementId:  statement.ID,
+		TotalAmount:  statement.TotalAmount,
+		LineCount:    int32(len(statement.Lines)),
+		MatchedCount: int32(len(statement.Lines) - len(flagged)),
+		FlaggedLines: lines,
 	}, nil
 }
+
+// ExportStatement streams a monthly account statement as CSV chunks.
+func (s *grpcServer) ExportStatement(req *pb.ExportStatementRequest, stream pb.PaymentService_ExportStatementServer) error {
+	month, err := ParseStatementMonth(req.Month)
+	if err != nil {
+		return fmt.Errorf("invalid month: %w", err)
+	}
+
+	return s.service.ExportStatement(stream.Context(), req.UserId, req.Currency, month, &statementChunkWriter{stream})
+}
+
+// StreamWalletEvents streams wallet balance changes until the client disconnects.
+func (s *grpcServer) StreamWalletEvents(req *pb.StreamWalletEventsRequest, stream pb.PaymentService_StreamWalletEventsServer) error {
+	if req.AfterSeq < 0 {
+		return errors.New("after_seq must not be negative")
+	}
+	afterSeq := req.AfterSeq
+	if req.Tail {
+		afterSeq = -1
+	}
+
+	err := s.service.StreamWalletEvents(stream.Context(), req.UserId, afterSeq, func(e WalletEvent) error {
+		return stream.Send(&pb.WalletEvent{
+			Seq:          e.Seq,
+			UserId:       e.AccountID,
+			Currency:     e.Currency,
+			Type:         e.Type,
+			Amount:       e.Amount,
+			BalanceAfter: e.BalanceAfter,
+			OrderId:      e.OrderID,
+			Reference:    e.Reference,
+			CreatedAt:    e.CreatedAt.Format(time.RFC3339),
+		})
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// statementChunkWriter sends each write to the stream as a statement chunk.
+type statementChunkWriter struct {
+	stream pb.PaymentService_ExportStatementServer
+}
+
+func (w *statementChunkWriter) Write(p []byte) (int, error) {
+	chunk := make([]byte, len(p))
+	copy(chunk, p)
+	if err := w.stream.Send(&pb.StatementChunk{Data: chunk}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// GenerateInvoices issues GST invoices for a month's freight charges.
+func (s *grpcServer) GenerateInvoices(ctx context.Context, req *pb.GenerateInvoicesRequest) (*pb.GenerateInvoicesResponse, error) {
+	month, err := ParseStatementMonth(req.Month)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month: %w", err)
+	}
+
+	invoices, err := s.invoices.GenerateInvoices(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*pb.Invoice
+	for i := range invoices {
+		items = append(items, invoice.ToProto(&invoices[i]))
+	}
+	return &pb.GenerateInvoicesResponse{
+		Invoices: items,
+	}, nil
+}
+
+// ListInvoices lists a merchant's GST invoices.
+func (s *grpcServer) ListInvoices(ctx context.Context, req *pb.ListInvoicesRequest) (*pb.ListInvoicesResponse, error) {
+	invoices, err := s.invoices.ListInvoices(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*pb.Invoice
+	for i := range invoices {
+		items = append(items, invoice.ToProto(&invoices[i]))
+	}
+	return &pb.ListInvoicesResponse{
+		Invoices: items,
+	}, nil
+}
+
+// GetInvoicePDF renders one of a merchant's invoices as PDF.
+func (s *grpcServer) GetInvoicePDF(ctx context.Context, req *pb.GetInvoicePDFRequest) (*pb.GetInvoicePDFResponse, error) {
+	inv, pdf, err := s.invoices.GetInvoicePDF(ctx, req.UserId, req.InvoiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetInvoicePDFResponse{
+		Invoice: invoice.ToProto(inv),
+		Pdf:     pdf,
+	}, nil
+}
+
+// GetBillingProfile retrieves the details printed on a merchant's invoices.
+func (s *grpcServer) GetBillingProfile(ctx context.Context, req *pb.GetBillingProfileRequest) (*pb.BillingProfileResponse, error) {
+	profile, err := s.invoices.GetBillingProfile(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BillingProfileResponse{
+		Profile: invoice.ProfileToProto(profile),
+	}, nil
+}
+
+// PutBillingProfile sets the details printed on a merchant's invoices.
+func (s *grpcServer) PutBillingProfile(ctx context.Context, req *pb.PutBillingProfileRequest) (*pb.BillingProfileResponse, error) {
+	if req.Profile == nil {
+		return nil, errors.New("profile is required")
+	}
+
+	profile, err := s.invoices.PutBillingProfile(ctx, *invoice.ProfileFromProto(req.Profile))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BillingProfileResponse{
+		Profile: invoice.ProfileToProto(profile),
+	}, nil
+}
+
+// GetCreditLine retrieves a merchant's postpaid credit line.
+func (s *grpcServer) GetCreditLine(ctx context.Context, req *pb.GetCreditLineRequest) (*pb.CreditLineResponse, error) {
+	line, err := s.service.GetCreditLine(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreditLineResponse{
+		CreditLine: toProtoCreditLine(line),
+	}, nil
+}
+
+// SetCreditLine grants or updates a merchant's postpaid credit line.
+func (s *grpcServer) SetCreditLine(ctx context.Context, req *pb.SetCreditLineRequest) (*pb.CreditLineResponse, error) {
+	line, err := s.service.SetCreditLine(ctx, CreditLine{
+		AccountID: req.UserId,
+		Limit:     req.Limit,
+		CycleDays: int(req.CycleDays),
+		TermsDays: int(req.TermsDays),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreditLineResponse{
+		CreditLine: toProtoCreditLine(line),
+	}, nil
+}
+
+// ListCreditBills lists the bills issued against a merchant's credit line.
+func (s *grpcServer) ListCreditBills(ctx context.Context, req *pb.ListCreditBillsRequest) (*pb.ListCreditBillsResponse, error) {
+	bills, err := s.service.ListCreditBills(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*pb.CreditBill
+	for _, b := range bills {
+		items = append(items, &pb.CreditBill{
+			BillId:      b.ID,
+			UserId:      b.AccountID,
+			Amount:      b.Amount,
+			PaidAmount:  b.PaidAmount,
+			PeriodStart: b.PeriodStart.Format(time.RFC3339),
+			PeriodEnd:   b.PeriodEnd.Format(time.RFC3339),
+			DueDate:     b.DueDate.Format(time.RFC3339),
+			Status:      b.Status,
+		})
+	}
+	return &pb.ListCreditBillsResponse{
+		Bills: items,
+	}, nil
+}
+
+func toProtoCreditLine(line *CreditLine) *pb.CreditLine {
+	return &pb.CreditLine{
+		UserId:     line.AccountID,
+		Limit:      line.Limit,
+		CycleDays:  int32(line.CycleDays),
+		TermsDays:  int32(line.TermsDays),
+		CycleStart: line.CycleStart.Format(time.RFC3339),
+		Frozen:     line.Frozen,
+	}
+}
+
+// GetBalanceAlert retrieves a merchant's low-balance alert.
+func (s *grpcServer) GetBalanceAlert(ctx context.Context, req *pb.GetBalanceAlertRequest) (*pb.BalanceAlertResponse, error) {
+	alert, err := s.service.GetBalanceAlert(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BalanceAlertResponse{
+		Alert: toProtoBalanceAlert(alert),
+	}, nil
+}
+
+// UpdateBalanceAlert sets a merchant's low-balance threshold and auto-recharge.
+func (s *grpcServer) UpdateBalanceAlert(ctx context.Context, req *pb.UpdateBalanceAlertRequest) (*pb.BalanceAlertResponse, error) {
+	if req.Alert == nil {
+		return nil, errors.New("alert is required")
+	}
+
+	alert, err := s.service.UpdateBalanceAlert(ctx, BalanceAlert{
+		AccountID:          req.Alert.UserId,
+		Threshold:          req.Alert.Threshold,
+		AutoRechargeAmount: req.Alert.AutoRechargeAmount,
+		MandateID:          req.Alert.MandateId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BalanceAlertResponse{
+		Alert: toProtoBalanceAlert(alert),
+	}, nil
+}
+
+func toProtoBalanceAlert(alert *BalanceAlert) *pb.BalanceAlert {
+	return &pb.BalanceAlert{
+		UserId:             alert.AccountID,
+		Threshold:          alert.Threshold,
+		AutoRechargeAmount: alert.AutoRechargeAmount,
+		MandateId:          alert.MandateID,
+		Triggered:          alert.Triggered,
+	}
+}
+
+// ListPlans lists the pricing plans merchants can subscribe to.
+func (s *grpcServer) ListPlans(ctx context.Context, req *pb.ListPlansRequest) (*pb.ListPlansResponse, error) {
+	plans, err := s.service.ListPlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*pb.Plan
+	for i := range plans {
+		items = append(items, toProtoPlan(&plans[i]))
+	}
+	return &pb.ListPlansResponse{
+		Plans: items,
+	}, nil
+}
+
+// GetAccountPlan retrieves a merchant's subscription and the plan in effect.
+func (s *grpcServer) GetAccountPlan(ctx context.Context, req *pb.GetAccountPlanRequest) (*pb.AccountPlanResponse, error) {
+	sub, plan, err := s.service.GetAccountPlan(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoAccountPlan(sub, plan), nil
+}
+
+// ChangePlan moves a merchant to another plan.
+func (s *grpcServer) ChangePlan(ctx context.Context, req *pb.ChangePlanRequest) (*pb.AccountPlanResponse, error) {
+	if _, err := s.service.ChangePlan(ctx, req.UserId, req.PlanCode); err != nil {
+		return nil, err
+	}
+
+	sub, plan, err := s.service.GetAccountPlan(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoAccountPlan(sub, plan), nil
+}
+
+func toProtoPlan(p *Plan) *pb.Plan {
+	weekdays := make([]string, len(p.Cycle.Weekdays))
+	for i, d := range p.Cycle.Weekdays {
+		weekdays[i] = strings.ToLower(d.String()[:3])
+	}
+
+	return &pb.Plan{
+		Code:                      p.Code,
+		Name:                      p.Name,
+		MonthlyFee:                p.MonthlyFee,
+		RateDiscountPercent:       p.RateDiscountPercent,
+		RemittanceDelayDays:       int32(p.Cycle.DelayDays),
+		RemittanceWeekdays:        weekdays,
+		EarlyRemittanceFeePercent: p.Cycle.EarlyFeePercent,
+		Features:                  p.Features,
+	}
+}
+
+func toProtoAccountPlan(sub *Subscription, plan *Plan) *pb.AccountPlanResponse {
+	res := &pb.AccountPlanResponse{
+		Plan: toProtoPlan(plan),
+	}
+	if sub != nil {
+		res.Subscription = &pb.Subscription{
+			PlanCode:    sub.PlanCode,
+			PeriodStart: sub.PeriodStart.Format(time.RFC3339),
+			PeriodEnd:   sub.PeriodEnd.Format(time.RFC3339),
+			Status:      sub.Status,
+		}
+	}
+	return res
+}
+
+// staffActor returns the member of staff whose token an admin RPC was called
+// with. Admin actions are audited under their name, never one the request
+// asserts.
+func staffActor(ctx context.Context) string {
+	if claims, ok := account.ClaimsFromContext(ctx); ok {
+		return claims.Staff
+	}
+	return ""
+}
+
+// FreezeWallet stops all debits from a merchant's wallet.
+func (s *grpcServer) FreezeWallet(ctx context.Context, req *pb.FreezeWalletRequest) (*pb.FreezeWalletResponse, error) {
+	if err := s.admin.FreezeWallet(ctx, staffActor(ctx), req.UserId, req.Reason); err != nil {
+		return nil, err
+	}
+	return &pb.FreezeWalletResponse{Success: true}, nil
+}
+
+// UnfreezeWallet lifts a wallet freeze.
+func (s *grpcServer) UnfreezeWallet(ctx context.Context, req *pb.FreezeWalletRequest) (*pb.FreezeWalletResponse, error) {
+	if err := s.admin.UnfreezeWallet(ctx, staffActor(ctx), req.UserId, req.Reason); err != nil {
+		return nil, err
+	}
+	return &pb.FreezeWalletResponse{Success: true}, nil
+}
+
+// RequestAdjustment posts a manual wallet adjustment.
+func (s *grpcServer) RequestAdjustment(ctx context.Context, req *pb.RequestAdjustmentRequest) (*pb.AdjustmentResponse, error) {
+	adj, err := s.admin.RequestAdjustment(ctx, staffActor(ctx), Adjustment{
+		AccountID: req.UserId,
+		Currency:  req.Currency,
+		Direction: req.Direction,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AdjustmentResponse{
+		Adjustment: toProtoAdjustment(adj),
+	}, nil
+}
+
+// ReviewAdjustment approves or rejects a pending adjustment.
+func (s *grpcServer) ReviewAdjustment(ctx context.Context, req *pb.ReviewAdjustmentRequest) (*pb.AdjustmentResponse, error) {
+	adj, err := s.admin.ReviewAdjustment(ctx, staffActor(ctx), req.AdjustmentId, req.Approve, req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AdjustmentResponse{
+		Adjustment: toProtoAdjustment(adj),
+	}, nil
+}
+
+// ListAdjustments lists wallet adjustments, optionally by status.
+func (s *grpcServer) ListAdjustments(ctx context.Context, req *pb.ListAdjustmentsRequest) (*pb.ListAdjustmentsResponse, error) {
+	adjustments, err := s.admin.ListAdjustments(ctx, req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*pb.Adjustment
+	for i := range adjustments {
+		items = append(items, toProtoAdjustment(&adjustments[i]))
+	}
+	return &pb.ListAdjustmentsResponse{
+		Adjustments: items,
+	}, nil
+}
+
+// ListAuditLog lists the most recent admin actions.
+func (s *grpcServer) ListAuditLog(ctx context.Context, req *pb.ListAuditLogRequest) (*pb.ListAuditLogResponse, error) {
+	entries, err := s.admin.ListAuditLog(ctx, req.UserId, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*pb.AuditEntry
+	for _, e := range entries {
+		items = append(items, &pb.AuditEntry{
+			Id:        e.ID,
+			Actor:     e.Actor,
+			Action:    e.Action,
+			UserId:    e.AccountID,
+			TargetId:  e.TargetID,
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return &pb.ListAuditLogResponse{
+		Entries: items,
+	}, nil
+}
+
+func toProtoAdjustment(adj *Adjustment) *pb.Adjustment {
+	res := &pb.Adjustment{
+		Id:          adj.ID,
+		UserId:      adj.AccountID,
+		Currency:    adj.Currency,
+		Direction:   adj.Direction,
+		Amount:      adj.Amount,
+		Reason:      adj.Reason,
+		Status:      adj.Status,
+		RequestedBy: adj.RequestedBy,
+		ReviewedBy:  adj.ReviewedBy,
+		ReviewNote:  adj.ReviewNote,
+		CreatedAt:   adj.CreatedAt.Format(time.RFC3339),
+	}
+	if adj.ReviewedAt != nil {
+		res.ReviewedAt = adj.ReviewedAt.Format(time.RFC3339)
+	}
+	return res
+}
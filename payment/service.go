@@ -1,34 +1,499 @@
 package payment
 
-import "context"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/Shridhar2104/logilo/datasubject"
+	"github.com/google/uuid"
+)
+
 type Service interface {
-	RechargeWallet(ctx context.Context, accountID string, amount float64) (float64, error)
-	DeductBalance(ctx context.Context, accountID string, amount float64, orderID string) (float64, error)
+	RechargeWallet(ctx context.Context, accountID string, amount float64, currency string, walletCurrency string) (float64, error)
+	DeductBalance(ctx context.Context, accountID string, amount float64, currency string, orderID string) (float64, error)
 	ProcessRemittance(ctx context.Context, accountID string, orderIDs []string) ([]RemittanceDetail, error)
-	GetWalletDetails(ctx context.Context, accountID string) (float64, []Transaction, error)
+	GetWalletDetails(ctx context.Context, accountID string, filter TransactionFilter) ([]WalletBalance, []Transaction, string, error)
+	ExportStatement(ctx context.Context, accountID string, currency string, month time.Time, w io.Writer) error
+	StreamWalletEvents(ctx context.Context, accountID string, afterSeq int64, send func(WalletEvent) error) error
+	SequenceWalletEvents(ctx context.Context) (int, error)
+	CreateRechargeIntent(ctx context.Context, accountID string, amount float64, currency string, walletCurrency string) (*RechargeIntent, error)
+	HandleGatewayWebhook(ctx context.Context, payload []byte, signature string) (*RechargeIntent, error)
+	ApplyShipmentEvent(ctx context.Context, event ShipmentEvent) error
+	CreateRemittanceBatches(ctx context.Context, asOf time.Time) ([]RemittanceBatch, error)
+	ListRemittanceBatches(ctx context.Context, accountID string) ([]RemittanceBatch, error)
+	UpdateRemittanceBatchStatus(ctx context.Context, batchID string, status string, utr string, reason string) (*RemittanceBatch, error)
+	RequestEarlyRemittance(ctx context.Context, accountID string) (*RemittanceBatch, error)
+	GetRemittanceSettings(ctx context.Context, accountID string) (*RemittanceSettings, error)
+	UpdateRemittanceSettings(ctx context.Context, accountID string, destination string, bank *BankAccount) (*RemittanceSettings, error)
+	ProcessPayouts(ctx context.Context) error
+	ListPayouts(ctx context.Context, accountID string) ([]Payout, error)
+	ImportCourierStatement(ctx context.Context, courier string, reference string, data []byte) (*CourierStatement, error)
+	GetCreditLine(ctx context.Context, accountID string) (*CreditLine, error)
+	SetCreditLine(ctx context.Context, line CreditLine) (*CreditLine, error)
+	ListCreditBills(ctx context.Context, accountID string) ([]CreditBill, error)
+	RunCreditBilling(ctx context.Context, now time.Time) error
+	GetBalanceAlert(ctx context.Context, accountID string) (*BalanceAlert, error)
+	UpdateBalanceAlert(ctx context.Context, alert BalanceAlert) (*BalanceAlert, error)
+	CheckBalanceAlert(ctx context.Context, accountID string) error
+	ListPlans(ctx context.Context) ([]Plan, error)
+	GetAccountPlan(ctx context.Context, accountID string) (*Subscription, *Plan, error)
+	ChangePlan(ctx context.Context, accountID string, planCode string) (*Subscription, error)
+	RenewSubscriptions(ctx context.Context, now time.Time) error
+	ExportSubjectData(ctx context.Context, accountID string) ([]datasubject.File, error)
+	EraseSubjectData(ctx context.Context, accountID string) (*datasubject.Erasure, error)
 }
 
 
 type paymentService struct {
-	repo Repository
+	repo     Repository
+	gateway  Gateway
+	payouts  PayoutProvider
+	fx       FXProvider
+	plans    PlanCatalog
+	notifier Notifier
+	kyc      KYCChecker
 }
 
-func NewPaymentService(repo Repository) Service {
-	return &paymentService{repo}
+func NewPaymentService(repo Repository, gateway Gateway, payouts PayoutProvider, fx FXProvider, plans PlanCatalog, notifier Notifier, kyc KYCChecker) Service {
+	return &paymentService{repo, gateway, payouts, fx, plans, notifier, kyc}
 }
 
-func (s *paymentService) RechargeWallet(ctx context.Context, accountID string, amount float64) (float64, error) {
-	return s.repo.RechargeWallet(ctx, accountID, amount)
+// RechargeWallet credits amount, paid in currency, to the wallet in
+// walletCurrency, converting it at the current rate. An empty walletCurrency
+// recharges the wallet in the currency paid.
+func (s *paymentService) RechargeWallet(ctx context.Context, accountID string, amount float64, currency string, walletCurrency string) (float64, error) {
+	if amount <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	currency = normalizeCurrency(currency)
+	if walletCurrency == "" {
+		walletCurrency = currency
+	}
+	walletCurrency = normalizeCurrency(walletCurrency)
+
+	credit, fx, err := convert(ctx, s.fx, amount, currency, walletCurrency)
+	if err != nil {
+		return 0, err
+	}
+	newBalance, err := s.repo.RechargeWallet(ctx, accountID, walletCurrency, credit, fx)
+	if err != nil {
+		return 0, err
+	}
+	if walletCurrency == BaseCurrency {
+		s.afterBalanceChange(ctx, accountID, newBalance)
+	}
+	return newBalance, nil
 }
 
-func (s *paymentService) DeductBalance(ctx context.Context, accountID string, amount float64, orderID string) (float64, error) {
-	return s.repo.DeductBalance(ctx, accountID, amount, orderID)
+// DeductBalance charges the wallet in currency for shipping an order. amount
+// is the rate card price, which the merchant's plan discounts. Merchants must
+// verify their email address before shipping.
+func (s *paymentService) DeductBalance(ctx context.Context, accountID string, amount float64, currency string, orderID string) (float64, error) {
+	if amount <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	if err := s.requireVerifiedEmail(ctx, accountID); err != nil {
+		return 0, err
+	}
+	currency = normalizeCurrency(currency)
+	if _, err := s.fx.Rate(ctx, currency, currency); err != nil {
+		return 0, err
+	}
+	plan, err := s.accountPlan(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	amount = plan.DiscountedRate(amount)
+	newBalance, err := s.repo.DeductBalance(ctx, accountID, currency, amount, orderID)
+	if err != nil {
+		return 0, err
+	}
+	if currency == BaseCurrency {
+		s.afterBalanceChange(ctx, accountID, newBalance)
+	}
+	return newBalance, nil
 }
 
+// ProcessRemittance remits the given orders once they are past the delay of the
+// merchant's plan. Like every other remittance, it needs the merchant's KYC
+// approved.
 func (s *paymentService) ProcessRemittance(ctx context.Context, accountID string, orderIDs []string) ([]RemittanceDetail, error) {
-	return s.repo.ProcessRemittance(ctx, accountID, orderIDs)
+	if err := s.requireKYC(ctx, accountID); err != nil {
+		return nil, err
+	}
+	plan, err := s.accountPlan(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	details, err := s.repo.ProcessRemittance(ctx, accountID, orderIDs, plan.Cycle.Cutoff(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	s.refreshBalanceAlert(ctx, accountID)
+	return details, nil
+}
+
+// GetWalletDetails returns the balance of each of the account's wallets and one
+// page of transactions matching filter, along with the cursor for the next page.
+func (s *paymentService) GetWalletDetails(ctx context.Context, accountID string, filter TransactionFilter) ([]WalletBalance, []Transaction, string, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	balances, transactions, err := s.repo.GetWalletDetails(ctx, accountID, filter)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return balances, transactions, pageCursor(transactions, filter.Limit), nil
+}
+
+// ExportStatement writes the statement of the wallet in currency for the
+// calendar month starting at month as CSV.
+func (s *paymentService) ExportStatement(ctx context.Context, accountID string, currency string, month time.Time, w io.Writer) error {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	to := from.AddDate(0, 1, 0)
+	currency = normalizeCurrency(currency)
+
+	opening, err := s.repo.GetBalanceBefore(ctx, accountID, currency, from)
+	if err != nil {
+		return err
+	}
+
+	return writeStatement(ctx, w, opening, func(cursor string) ([]Transaction, error) {
+		return s.repo.ListTransactions(ctx, accountID, TransactionFilter{
+			Currency:  currency,
+			From:      from,
+			To:        to,
+			Cursor:    cursor,
+			Limit:     maxPageSize,
+			Ascending: true,
+		})
+	})
+}
+
+// CreateRechargeIntent opens a recharge of amount in currency with the payment
+// gateway. The wallet in walletCurrency is credited later, once the gateway
+// confirms capture through a webhook, at the rate quoted now.
+func (s *paymentService) CreateRechargeIntent(ctx context.Context, accountID string, amount float64, currency string, walletCurrency string) (*RechargeIntent, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	currency = normalizeCurrency(currency)
+	if walletCurrency == "" {
+		walletCurrency = currency
+	}
+	walletCurrency = normalizeCurrency(walletCurrency)
+
+	credit, fx, err := convert(ctx, s.fx, amount, currency, walletCurrency)
+	if err != nil {
+		return nil, err
+	}
+	intent := RechargeIntent{
+		ID:             uuid.NewString(),
+		AccountID:      accountID,
+		Amount:         amount,
+		Currency:       currency,
+		WalletCurrency: walletCurrency,
+		CreditAmount:   credit,
+		FXRate:         1,
+		Status:         RechargeStatusCreated,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if fx != nil {
+		intent.FXRate = fx.Rate
+	}
+
+	checkoutRef, err := s.gateway.CreateIntent(ctx, intent.ID, accountID, amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	intent.CheckoutRef = checkoutRef
+
+	if err := s.repo.CreateRechargeIntent(ctx, intent); err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+// HandleGatewayWebhook verifies a gateway webhook and applies it to its recharge intent.
+func (s *paymentService) HandleGatewayWebhook(ctx context.Context, payload []byte, signature string) (*RechargeIntent, error) {
+	event, err := s.gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		return nil, err
+	}
+	if event.EventID == "" {
+		return nil, ErrMissingEventID
+	}
+	intent, err := s.repo.ApplyGatewayEvent(ctx, *event)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status == RechargeStatusCaptured && intent.WalletCurrency == BaseCurrency {
+		s.refreshBalanceAlert(ctx, intent.AccountID)
+	}
+	return intent, nil
+}
+
+// CreateRemittanceBatches batches the COD orders that are payable as of asOf,
+// for the merchants whose plan remits on that day.
+func (s *paymentService) CreateRemittanceBatches(ctx context.Context, asOf time.Time) ([]RemittanceBatch, error) {
+	var batches []RemittanceBatch
+	for _, plan := range s.plans {
+		if !plan.Cycle.IsRunDay(asOf) {
+			continue
+		}
+		created, err := s.repo.CreateRemittanceBatches(ctx, plan.Cycle.Cutoff(asOf), "", plan.Code, 0)
+		if err != nil {
+			return batches, err
+		}
+		batches = append(batches, created...)
+	}
+	return batches, nil
+}
+
+func (s *paymentService) ListRemittanceBatches(ctx context.Context, accountID string) ([]RemittanceBatch, error) {
+	return s.repo.ListRemittanceBatches(ctx, accountID)
+}
+
+// UpdateRemittanceBatchStatus approves, pays or fails a remittance batch.
+// Approving a batch settles it to the merchant's remittance destination, and
+// needs the merchant's KYC to be approved. Batches settled by bank payout are
+// left to the payout worker to mark paid or failed.
+func (s *paymentService) UpdateRemittanceBatchStatus(ctx context.Context, batchID string, status string, utr string, reason string) (*RemittanceBatch, error) {
+	if status == BatchStatusPaid && utr == "" {
+		return nil, ErrMissingUTR
+	}
+	if status == BatchStatusPaid || status == BatchStatusFailed {
+		paidOut, err := s.repo.BatchHasPayout(ctx, batchID)
+		if err != nil {
+			return nil, err
+		}
+		if paidOut {
+			return nil, ErrBatchHasPayout
+		}
+	}
+	if status == BatchStatusApproved {
+		batch, err := s.repo.GetRemittanceBatch(ctx, batchID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.requireKYC(ctx, batch.AccountID); err != nil {
+			return nil, err
+		}
+	}
+	batch, err := s.repo.UpdateRemittanceBatchStatus(ctx, batchID, status, utr, reason)
+	if err != nil || status != BatchStatusApproved {
+		return batch, err
+	}
+	return s.settleBatch(ctx, batch)
+}
+
+// RequestEarlyRemittance settles all of a merchant's delivered COD orders now,
+// ahead of the remittance cycle, withholding the early settlement fee.
+// It returns nil when the merchant has nothing to settle.
+func (s *paymentService) RequestEarlyRemittance(ctx context.Context, accountID string) (*RemittanceBatch, error) {
+	if err := s.requireKYC(ctx, accountID); err != nil {
+		return nil, err
+	}
+	plan, err := s.accountPlan(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.HasFeature(FeatureEarlyRemittance) {
+		return nil, ErrFeatureNotInPlan
+	}
+
+	batches, err := s.repo.CreateRemittanceBatches(ctx, time.Now(), accountID, "", plan.Cycle.EarlyFeePercent)
+	if err != nil || len(batches) == 0 {
+		return nil, err
+	}
+
+	batch, err := s.repo.UpdateRemittanceBatchStatus(ctx, batches[0].ID, BatchStatusApproved, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return s.settleBatch(ctx, batch)
 }
 
-func (s *paymentService) GetWalletDetails(ctx context.Context, accountID string) (float64, []Transaction, error) {
-	return s.repo.GetWalletDetails(ctx, accountID)
+// settleBatch pays an approved batch into the merchant's wallet, or queues a bank
+// payout when the merchant remits to a verified bank account.
+func (s *paymentService) settleBatch(ctx context.Context, batch *RemittanceBatch) (*RemittanceBatch, error) {
+	settings, err := s.repo.GetRemittanceSettings(ctx, batch.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Destination != DestinationBank || !settings.BankVerified {
+		settled, err := s.repo.SettleBatchToWallet(ctx, batch.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.refreshBalanceAlert(ctx, batch.AccountID)
+		return settled, nil
+	}
+
+	now := time.Now()
+	err = s.repo.CreatePayout(ctx, Payout{
+		ID:            uuid.NewString(),
+		BatchID:       batch.ID,
+		AccountID:     batch.AccountID,
+		Amount:        batch.NetAmount(),
+		Bank:          settings.Bank,
+		Status:        PayoutStatusQueued,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (s *paymentService) GetRemittanceSettings(ctx context.Context, accountID string) (*RemittanceSettings, error) {
+	return s.repo.GetRemittanceSettings(ctx, accountID)
+}
+
+// UpdateRemittanceSettings sets the merchant's remittance destination. New bank
+// details are verified with the payout provider before they are used.
+func (s *paymentService) UpdateRemittanceSettings(ctx context.Context, accountID string, destination string, bank *BankAccount) (*RemittanceSettings, error) {
+	if destination != DestinationWallet && destination != DestinationBank {
+		return nil, ErrInvalidDestination
+	}
+
+	settings, err := s.repo.GetRemittanceSettings(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	settings.Destination = destination
+
+	if bank != nil && *bank != settings.Bank {
+		if bank.AccountNumber == "" || bank.BeneficiaryName == "" || !ifscPattern.MatchString(bank.IFSC) {
+			return nil, ErrInvalidBankAccount
+		}
+		verified, err := s.payouts.VerifyAccount(ctx, *bank)
+		if err != nil {
+			return nil, err
+		}
+		settings.Bank = *bank
+		settings.BankVerified = verified
+	}
+	if destination == DestinationBank && !settings.BankVerified {
+		return nil, ErrBankAccountNotVerified
+	}
+
+	if err := s.repo.PutRemittanceSettings(ctx, *settings); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRemittanceSettings(ctx, accountID)
+}
+
+// ProcessPayouts submits queued payouts and polls in-flight ones. Failed payouts
+// are retried with exponential backoff before their batch is marked failed.
+func (s *paymentService) ProcessPayouts(ctx context.Context) error {
+	payouts, err := s.repo.ListDuePayouts(ctx, time.Now(), 50)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range payouts {
+		if err := s.advancePayout(ctx, &p); err != nil {
+			return err
+		}
+		if err := s.repo.UpdatePayout(ctx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *paymentService) advancePayout(ctx context.Context, p *Payout) error {
+	now := time.Now()
+
+	if p.Status == PayoutStatusQueued {
+		// A payout waiting on KYC is held without using up an attempt.
+		if err := s.requireKYC(ctx, p.AccountID); err != nil {
+			p.LastError = err.Error()
+			p.NextAttemptAt = now.Add(kycHoldInterval)
+			return nil
+		}
+		p.Attempts++
+		ref, err := s.payouts.CreatePayout(ctx, *p)
+		if err != nil {
+			return s.retryPayout(ctx, p, err.Error())
+		}
+		p.Status, p.ProviderRef, p.LastError = PayoutStatusProcessing, ref, ""
+		p.NextAttemptAt = now.Add(time.Minute)
+		return nil
+	}
+
+	status, utr, err := s.payouts.PayoutStatus(ctx, p.ProviderRef)
+	if err != nil {
+		p.LastError = err.Error()
+		p.NextAttemptAt = now.Add(payoutBackoff(p.Attempts))
+		return nil
+	}
+
+	switch status {
+	case PayoutStatusProcessed:
+		if _, err := s.repo.UpdateRemittanceBatchStatus(ctx, p.BatchID, BatchStatusPaid, utr, ""); err != nil {
+			return err
+		}
+		p.Status, p.UTR = PayoutStatusProcessed, utr
+	case PayoutStatusFailed:
+		return s.retryPayout(ctx, p, "payout rejected by provider")
+	default:
+		p.NextAttemptAt = now.Add(time.Minute)
+	}
+	return nil
+}
+
+// retryPayout requeues a failed payout, or fails it and its batch once attempts run out.
+func (s *paymentService) retryPayout(ctx context.Context, p *Payout, reason string) error {
+	p.LastError = reason
+	if p.Attempts < maxPayoutAttempts {
+		p.Status, p.ProviderRef = PayoutStatusQueued, ""
+		p.NextAttemptAt = time.Now().Add(payoutBackoff(p.Attempts))
+		return nil
+	}
+
+	if _, err := s.repo.UpdateRemittanceBatchStatus(ctx, p.BatchID, BatchStatusFailed, "", reason); err != nil {
+		return err
+	}
+	p.Status = PayoutStatusFailed
+	return nil
+}
+
+func (s *paymentService) ListPayouts(ctx context.Context, accountID string) ([]Payout, error) {
+	return s.repo.ListPayouts(ctx, accountID)
+}
+
+// ImportCourierStatement parses a courier's CSV COD statement and reconciles it
+// against our COD shipments.
+func (s *paymentService) ImportCourierStatement(ctx context.Context, courier string, reference string, data []byte) (*CourierStatement, error) {
+	if courier == "" || reference == "" {
+		return nil, errors.New("courier and statement reference are required")
+	}
+
+	lines, err := ParseCourierStatement(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	statement := CourierStatement{
+		ID:         uuid.NewString(),
+		Courier:    courier,
+		Reference:  reference,
+		Lines:      lines,
+		ImportedAt: time.Now(),
+	}
+	for _, l := range lines {
+		statement.TotalAmount += l.Amount
+	}
+
+	return s.repo.ImportCourierStatement(ctx, statement)
 }
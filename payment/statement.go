@@ -0,0 +1,129 @@
+package payment
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Wallet transaction types.
+const (
+	TransactionRecharge   = "recharge"
+	TransactionDeduction  = "deduction"
+	TransactionRemittance = "remittance"
+	// Plan fees and the refund of unused fees on a plan change.
+	TransactionSubscription       = "subscription"
+	TransactionSubscriptionRefund = "subscription_refund"
+	// Manual adjustments posted by ops staff.
+	TransactionAdjustmentCredit = "adjustment_credit"
+	TransactionAdjustmentDebit  = "adjustment_debit"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+var ErrInvalidCursor = errors.New("invalid transaction cursor")
+
+// debitTypes are the transaction types that take money out of the wallet.
+var debitTypes = map[string]bool{
+	TransactionDeduction:       true,
+	TransactionSubscription:    true,
+	TransactionAdjustmentDebit: true,
+}
+
+// TransactionFilter narrows and pages a wallet's transaction history.
+type TransactionFilter struct {
+	Currency string // Only this wallet's transactions; all wallets if empty.
+	Type     string
+	OrderID  string
+	From     time.Time // Inclusive.
+	To       time.Time // Exclusive.
+	Cursor   string    // Continue after this transaction.
+	Limit    int
+	// Ascending lists oldest first; by default the newest transactions come first.
+	Ascending bool
+}
+
+// pageCursor returns the cursor for the page following transactions, or an empty
+// string when transactions is the last page.
+func pageCursor(transactions []Transaction, limit int) string {
+	if len(transactions) < limit || len(transactions) == 0 {
+		return ""
+	}
+	return transactions[len(transactions)-1].TransactionID
+}
+
+func normalizeFilter(filter TransactionFilter) (TransactionFilter, error) {
+	if filter.Currency != "" {
+		filter.Currency = normalizeCurrency(filter.Currency)
+	}
+	if filter.Cursor != "" {
+		if _, err := strconv.ParseInt(filter.Cursor, 10, 64); err != nil {
+			return filter, ErrInvalidCursor
+		}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+	return filter, nil
+}
+
+// ParseStatementMonth parses a statement period such as "2024-03".
+func ParseStatementMonth(month string) (time.Time, error) {
+	return time.ParseInLocation("2006-01", month, time.UTC)
+}
+
+// writeStatement writes a monthly account statement as CSV, oldest transaction
+// first, with opening and closing balance rows. Transactions are read one page
+// at a time through next, and each page is flushed to w as it is written.
+func writeStatement(ctx context.Context, w io.Writer, opening float64, next func(cursor string) ([]Transaction, error)) error {
+	cw := csv.NewWriter(w)
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+	cw.Write([]string{"Date", "Transaction ID", "Type", "Order ID", "Reference", "Debit", "Credit", "Balance"})
+	cw.Write([]string{"", "", "Opening balance", "", "", "", "", money(opening)})
+
+	closing := opening
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := next(cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range page {
+			debit, credit := "", money(t.Amount)
+			if debitTypes[t.TransactionType] {
+				debit, credit = money(t.Amount), ""
+			}
+			cw.Write([]string{
+				t.Timestamp.Format(time.RFC3339), t.TransactionID, t.TransactionType,
+				t.OrderID.String, t.Reference.String, debit, credit, money(t.BalanceAfter),
+			})
+			closing = t.BalanceAfter
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+
+		if cursor = pageCursor(page, maxPageSize); cursor == "" {
+			break
+		}
+	}
+
+	cw.Write([]string{"", "", "Closing balance", "", "", "", "", money(closing)})
+	cw.Flush()
+	return cw.Error()
+}
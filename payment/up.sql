@@ -1,21 +1,280 @@
--- Orders table
-ALTER TABLE orders ADD COLUMN delivery_date TIMESTAMP;
-ALTER TABLE orders ADD COLUMN remittance_processed BOOLEAN DEFAULT FALSE;
+-- Remittance eligibility, projected from shipment events so the payment
+-- service does not read the orders table owned by the shopify service
+CREATE TABLE remittance_eligibility (
+    order_id VARCHAR(255) PRIMARY KEY,
+    account_id VARCHAR(255) NOT NULL,
+    awb VARCHAR(64) UNIQUE,
+    is_cod BOOLEAN NOT NULL DEFAULT FALSE,
+    cod_amount NUMERIC(10, 2) NOT NULL DEFAULT 0.00,
+    shipment_status VARCHAR(20) NOT NULL, -- e.g., "booked", "delivered", "returned", "cancelled"
+    delivered_at TIMESTAMP DEFAULT NULL,
+    cod_collected_by_courier BOOLEAN NOT NULL DEFAULT FALSE,
+    courier_remitted_amount NUMERIC(10, 2) NOT NULL DEFAULT 0.00,
+    remittance_processed BOOLEAN NOT NULL DEFAULT FALSE,
+    last_event_at TIMESTAMP NOT NULL, -- occurred_at of the latest applied event
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX remittance_eligibility_payable_idx ON remittance_eligibility (account_id, delivered_at)
+    WHERE is_cod AND NOT remittance_processed;
+
+-- Shipment events already applied, for deduplicating redeliveries
+CREATE TABLE shipment_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    order_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
 
 -- Wallets table
 CREATE TABLE wallets (
-    account_id VARCHAR(255) PRIMARY KEY,
+    account_id VARCHAR(255) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'INR', -- one wallet per ISO 4217 currency
     balance NUMERIC(10, 2) NOT NULL DEFAULT 0.00,
-    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    frozen BOOLEAN NOT NULL DEFAULT FALSE, -- set by ops during fraud investigations
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, currency)
 );
 
 -- Transactions table for wallet history
 CREATE TABLE transactions (
     transaction_id SERIAL PRIMARY KEY,
     account_id VARCHAR(255) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'INR',
     transaction_type VARCHAR(50) NOT NULL, -- e.g., "recharge", "deduction", "remittance"
     amount NUMERIC(10, 2) NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     order_id VARCHAR(255) DEFAULT NULL,
-    FOREIGN KEY (account_id) REFERENCES wallets(account_id)
+    reference VARCHAR(255) DEFAULT NULL, -- e.g., the recharge intent ID
+    balance_after NUMERIC(12, 2) NOT NULL DEFAULT 0.00, -- running wallet balance
+    source_amount NUMERIC(12, 2) DEFAULT NULL, -- amount paid, for recharges converted from another currency
+    source_currency CHAR(3) DEFAULT NULL,
+    fx_rate NUMERIC(18, 8) DEFAULT NULL,
+    xact_id XID8 NOT NULL DEFAULT pg_current_xact_id(), -- writing database transaction, for sequencing
+    seq BIGINT UNIQUE DEFAULT NULL, -- wallet event number, assigned in commit order after commit
+    FOREIGN KEY (account_id, currency) REFERENCES wallets(account_id, currency)
+);
+CREATE INDEX transactions_account_idx ON transactions (account_id, transaction_id);
+CREATE INDEX transactions_unsequenced_idx ON transactions (xact_id, transaction_id) WHERE seq IS NULL;
+
+
+-- Recharge intents awaiting gateway confirmation
+CREATE TABLE recharge_intents (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(255) NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL, -- charged by the gateway, in currency
+    currency CHAR(3) NOT NULL DEFAULT 'INR',
+    wallet_currency CHAR(3) NOT NULL DEFAULT 'INR', -- wallet credited on capture
+    credit_amount NUMERIC(10, 2) NOT NULL, -- in wallet_currency, at the rate locked at creation
+    fx_rate NUMERIC(18, 8) NOT NULL DEFAULT 1,
+    status VARCHAR(20) NOT NULL, -- e.g., "created", "pending", "captured", "failed"
+    checkout_ref VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Gateway webhook events already applied, used to ignore redeliveries
+CREATE TABLE gateway_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    intent_id VARCHAR(36) NOT NULL REFERENCES recharge_intents(id),
+    status VARCHAR(20) NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- COD remittance batches, one per merchant per cycle
+CREATE TABLE remittance_batches (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL, -- e.g., "created", "approved", "paid", "failed"
+    total_amount NUMERIC(12, 2) NOT NULL,
+    fee_amount NUMERIC(12, 2) NOT NULL DEFAULT 0.00, -- early settlement fee
+    utr VARCHAR(64) DEFAULT NULL,
+    failure_reason TEXT DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE remittance_batch_items (
+    batch_id VARCHAR(36) NOT NULL REFERENCES remittance_batches(id),
+    order_id VARCHAR(255) NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL,
+    PRIMARY KEY (batch_id, order_id)
+);
+
+-- Where each merchant's remittances are sent
+CREATE TABLE remittance_settings (
+    account_id VARCHAR(255) PRIMARY KEY,
+    destination VARCHAR(20) NOT NULL DEFAULT 'wallet', -- "wallet" or "bank"
+    bank_account_number VARCHAR(34) NOT NULL DEFAULT '',
+    ifsc VARCHAR(11) NOT NULL DEFAULT '',
+    beneficiary_name VARCHAR(255) NOT NULL DEFAULT '',
+    bank_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bank payouts settling remittance batches
+CREATE TABLE payouts (
+    id VARCHAR(36) PRIMARY KEY,
+    batch_id VARCHAR(36) NOT NULL REFERENCES remittance_batches(id),
+    account_id VARCHAR(255) NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    bank_account_number VARCHAR(34) NOT NULL,
+    ifsc VARCHAR(11) NOT NULL,
+    beneficiary_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL, -- e.g., "queued", "processing", "processed", "failed"
+    provider_ref VARCHAR(255) DEFAULT NULL,
+    utr VARCHAR(64) DEFAULT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT DEFAULT NULL,
+    next_attempt_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX payouts_due_idx ON payouts (status, next_attempt_at);
+
+-- COD remittance statements received from couriers
+CREATE TABLE courier_statements (
+    id VARCHAR(36) PRIMARY KEY,
+    courier VARCHAR(255) NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    total_amount NUMERIC(12, 2) NOT NULL,
+    imported_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (courier, reference)
+);
+
+CREATE TABLE courier_statement_lines (
+    statement_id VARCHAR(36) NOT NULL REFERENCES courier_statements(id),
+    line_no INT NOT NULL,
+    awb VARCHAR(64) NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL,
+    order_id VARCHAR(255) DEFAULT NULL,
+    expected_amount NUMERIC(10, 2) NOT NULL DEFAULT 0.00,
+    status VARCHAR(20) NOT NULL, -- e.g., "matched", "short_payment", "amount_mismatch", "missing_awb", "duplicate"
+    PRIMARY KEY (statement_id, line_no)
+);
+
+-- Merchant details printed on GST invoices
+CREATE TABLE billing_profiles (
+    account_id VARCHAR(255) PRIMARY KEY,
+    legal_name VARCHAR(255) NOT NULL,
+    gstin VARCHAR(15) NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    state_code CHAR(2) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Last invoice number issued in each financial year
+CREATE TABLE invoice_sequences (
+    financial_year VARCHAR(7) PRIMARY KEY,
+    last_number INT NOT NULL
+);
+
+-- Monthly GST tax invoices for freight charges
+CREATE TABLE invoices (
+    id VARCHAR(36) PRIMARY KEY,
+    number VARCHAR(32) NOT NULL UNIQUE,
+    financial_year VARCHAR(7) NOT NULL,
+    account_id VARCHAR(255) NOT NULL,
+    legal_name VARCHAR(255) NOT NULL,
+    gstin VARCHAR(15) NOT NULL,
+    address TEXT NOT NULL,
+    state_code CHAR(2) NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    issue_date TIMESTAMP NOT NULL,
+    sac VARCHAR(8) NOT NULL,
+    taxable_value NUMERIC(12, 2) NOT NULL,
+    cgst NUMERIC(12, 2) NOT NULL,
+    sgst NUMERIC(12, 2) NOT NULL,
+    igst NUMERIC(12, 2) NOT NULL,
+    total NUMERIC(12, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_id, period_start)
+);
+
+-- Postpaid credit lines letting trusted merchants' wallets go negative
+CREATE TABLE credit_lines (
+    account_id VARCHAR(255) PRIMARY KEY,
+    credit_limit NUMERIC(12, 2) NOT NULL,
+    cycle_days INT NOT NULL,
+    terms_days INT NOT NULL,
+    cycle_start TIMESTAMP NOT NULL,
+    frozen BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bills for the credit used in each closed billing cycle
+CREATE TABLE credit_bills (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(255) NOT NULL REFERENCES credit_lines(account_id),
+    amount NUMERIC(12, 2) NOT NULL,
+    paid_amount NUMERIC(12, 2) NOT NULL DEFAULT 0.00,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    due_date TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL, -- e.g., "open", "paid", "overdue"
+    reminders_sent INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX credit_bills_unpaid_idx ON credit_bills (account_id, due_date) WHERE status IN ('open', 'overdue');
+
+-- Low-balance alert thresholds and optional auto-recharge via a saved gateway mandate
+CREATE TABLE balance_alerts (
+    account_id VARCHAR(255) PRIMARY KEY,
+    threshold NUMERIC(12, 2) NOT NULL,
+    auto_recharge_amount NUMERIC(12, 2) NOT NULL DEFAULT 0.00,
+    mandate_id VARCHAR(255) DEFAULT NULL,
+    triggered BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Merchant plan subscriptions; merchants without one are on the free Lite plan
+CREATE TABLE subscriptions (
+    account_id VARCHAR(255) PRIMARY KEY,
+    plan_code VARCHAR(32) NOT NULL, -- e.g., "lite", "pro", "enterprise"
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL, -- e.g., "active", "past_due"
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX subscriptions_due_idx ON subscriptions (period_end);
+
+-- Manual wallet adjustments by ops staff, with maker-checker review
+CREATE TABLE wallet_adjustments (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(255) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'INR',
+    direction VARCHAR(10) NOT NULL, -- e.g., "credit", "debit"
+    amount NUMERIC(12, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL, -- e.g., "pending", "applied", "rejected"
+    requested_by VARCHAR(255) NOT NULL,
+    reviewed_by VARCHAR(255) DEFAULT NULL,
+    review_note TEXT DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_at TIMESTAMP DEFAULT NULL,
+    CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
 );
+
+-- Append-only log of admin actions
+CREATE TABLE admin_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL, -- e.g., "wallet.freeze", "adjustment.approve"
+    account_id VARCHAR(255) DEFAULT NULL,
+    target_id VARCHAR(255) DEFAULT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX admin_audit_log_account_idx ON admin_audit_log (account_id, id);
+
+CREATE FUNCTION reject_audit_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER admin_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+)
+
+// SignatureHeader carries the gateway's signature of the webhook body.
+const SignatureHeader = "X-Gateway-Signature"
+
+// NewWebhookHandler returns an HTTP handler that receives gateway webhooks and
+// applies them to recharge intents.
+func NewWebhookHandler(service Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		payload, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, err = service.HandleGatewayWebhook(r.Context(), payload, r.Header.Get(SignatureHeader))
+		switch {
+		case errors.Is(err, ErrInvalidSignature):
+			w.WriteHeader(http.StatusUnauthorized)
+		case errors.Is(err, ErrIntentNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, ErrAmountMismatch), errors.Is(err, ErrCheckoutMismatch), errors.Is(err, ErrMissingEventID):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case err != nil:
+			// Let the gateway retry on unexpected failures.
+			log.Printf("Failed to handle gateway webhook: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+}
@@ -4,15 +4,24 @@ import (
 	"log"
 	"time"
 
+	"github.com/Shridhar2104/logilo/account"
+	"github.com/Shridhar2104/logilo/datasubject/pb"
 	"github.com/Shridhar2104/logilo/shopify"
 
 	"github.com/tinrab/retry"
 	"github.com/kelseyhightower/envconfig"
+	"google.golang.org/grpc"
 )
 
 type Config struct {
 	DatabaseURL string `envconfig:"DATABASE_SHOPIFY_URL"`
-	
+	StaffSecret string `envconfig:"STAFF_TOKEN_SECRET" required:"true"` // Verifies the account service's staff tokens
+}
+
+// policies restricts the data-subject RPCs to the account service.
+var policies = map[string]account.Policy{
+	pb.DataSubjectService_ExportSubjectData_FullMethodName: {Permission: account.PermissionInternal},
+	pb.DataSubjectService_EraseSubjectData_FullMethodName:  {Permission: account.PermissionInternal},
 }
 
 func main() {
@@ -36,5 +45,11 @@ func main() {
 	log.Println("server starting on port 8080 ...")
 
 	s := shopify.NewShopifyService(r)
-	log.Fatal(shopify.NewGRPCServer(s, 8080))
+	// Only other services call the shopify service, so it holds no user
+	// token secret.
+	tokens, err := account.NewTokenManager("", cfg.StaffSecret, 0, 0)
+	if err != nil {
+		log.Fatalf("Failed to set up tokens: %v", err)
+	}
+	log.Fatal(shopify.NewGRPCServer(s, 8080, grpc.UnaryInterceptor(account.UnaryServerInterceptor(tokens, policies))))
 }
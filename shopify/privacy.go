@@ -0,0 +1,47 @@
+package shopify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Shridhar2104/logilo/datasubject"
+)
+
+// ExportSubjectData returns the account's connected shops and their orders.
+// Shop access tokens are credentials rather than personal data and are left
+// out.
+func (s *shopifyService) ExportSubjectData(ctx context.Context, accountId string) ([]datasubject.File, error) {
+	shops, err := s.repo.ListShops(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.repo.ListOrdersForAccount(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	shopsFile, err := datasubject.JSONFile("shops.json", shops)
+	if err != nil {
+		return nil, err
+	}
+	ordersFile, err := datasubject.JSONFile("orders.json", orders)
+	if err != nil {
+		return nil, err
+	}
+	return []datasubject.File{shopsFile, ordersFile}, nil
+}
+
+// EraseSubjectData deletes the account's orders and disconnects its shops.
+// Nothing is retained: the orders are copies of the merchant's Shopify data.
+func (s *shopifyService) EraseSubjectData(ctx context.Context, accountId string) (*datasubject.Erasure, error) {
+	orders, shops, err := s.repo.DeleteAccountData(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+	return &datasubject.Erasure{
+		Erased: []string{
+			fmt.Sprintf("%d synced Shopify orders", orders),
+			fmt.Sprintf("%d connected shops and their access tokens", shops),
+		},
+	}, nil
+}
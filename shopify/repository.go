@@ -14,6 +14,9 @@ type Repository interface {
 	SyncOrders(ctx context.Context, shopName string, sinceId string, limit int, token string) ([]Order, error)
 	UpdateOrder(ctx context.Context, order Order, accountId string, shopName string) error	
 	StoreToken(ctx context.Context, shopName string, accountId string, token string) error
+	ListShops(ctx context.Context, accountId string) ([]string, error)
+	ListOrdersForAccount(ctx context.Context, accountId string) ([]Order, error)
+	DeleteAccountData(ctx context.Context, accountId string) (int, int, error)
 
 }
 
@@ -155,6 +158,85 @@ func (r *postgresRepository) StoreToken(ctx context.Context, shopName string, ac
 }
 
 
+// ListShops returns the names of the shops the account has connected.
+func (r *postgresRepository) ListShops(ctx context.Context, accountId string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT shop_name FROM tokens WHERE account_id = $1 ORDER BY shop_name`, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shops []string
+	for rows.Next() {
+		var shop string
+		if err := rows.Scan(&shop); err != nil {
+			return nil, err
+		}
+		shops = append(shops, shop)
+	}
+	return shops, rows.Err()
+}
+
+// ListOrdersForAccount returns the orders of all the account's shops.
+func (r *postgresRepository) ListOrdersForAccount(ctx context.Context, accountId string) ([]Order, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, created_at, updated_at, shop_name, account_id, order_id, total_price
+		FROM orders WHERE account_id = $1 ORDER BY created_at`,
+		accountId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []Order
+	for rows.Next() {
+		var order Order
+		if err := rows.Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt, &order.ShopName, &order.AccountId, &order.OrderId, &order.TotalPrice); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+// DeleteAccountData deletes the account's orders and shop tokens, returning
+// how many of each were deleted.
+func (r *postgresRepository) DeleteAccountData(ctx context.Context, accountId string) (orders int, shops int, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM orders WHERE account_id = $1`, accountId)
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	orders = int(n)
+
+	res, err = tx.ExecContext(ctx, `DELETE FROM tokens WHERE account_id = $1`, accountId)
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err = res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	return orders, int(n), nil
+}
+
 func (r *postgresRepository) Close() {
 	r.db.Close()
 }
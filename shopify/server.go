@@ -7,6 +7,7 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+	"github.com/Shridhar2104/logilo/datasubject"
 	"github.com/Shridhar2104/logilo/shopify/pb"
 )
 type grpcServer struct {
@@ -14,17 +15,18 @@ type grpcServer struct {
 	service Service
 }
 
-func NewGRPCServer(service Service, port int) error {
+func NewGRPCServer(service Service, port int, opts ...grpc.ServerOption) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
 	}
 
-	server := grpc.NewServer()
+	server := grpc.NewServer(opts...)
 	pb.RegisterShopifyServiceServer(server, &grpcServer{
 		UnimplementedShopifyServiceServer: pb.UnimplementedShopifyServiceServer{}, // Add this
 		service: service,
 	})
+	datasubject.Register(server, service)
 	reflection.Register(server)
 	return server.Serve(lis)
 }
@@ -2,7 +2,8 @@ package shopify
 
 import (
 	"context"
-	
+
+	"github.com/Shridhar2104/logilo/datasubject"
 )
 
 type Service interface {
@@ -11,6 +12,8 @@ type Service interface {
 	UpdateOrder(ctx context.Context, order Order, accountId string, shopName string) error
 	StoreToken(ctx context.Context, shopName string, accountId string, token string) error
 	PutOrder(ctx context.Context, order Order) error
+	ExportSubjectData(ctx context.Context, accountId string) ([]datasubject.File, error)
+	EraseSubjectData(ctx context.Context, accountId string) (*datasubject.Erasure, error)
 }
 
 